@@ -305,17 +305,32 @@ func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
 	if err := unmarshal(cfg, mod); err != nil {
 		return nil, err
 	}
+	policy := module.CardinalityPolicy(cfg.CardinalityPolicy())
+	if !policy.IsValid() {
+		return nil, fmt.Errorf("unknown cardinality_policy '%s', expected '%s' or '%s'",
+			policy, module.CardinalityDrop, module.CardinalityOther)
+	}
+	var vnode struct {
+		Vnode *module.VirtualNode `yaml:"vnode"`
+	}
+	if err := unmarshal(cfg, &vnode); err != nil {
+		return nil, err
+	}
 
 	job := module.NewJob(module.JobConfig{
-		PluginName:      m.PluginName,
-		Name:            cfg.Name(),
-		ModuleName:      cfg.Module(),
-		FullName:        cfg.FullName(),
-		UpdateEvery:     cfg.UpdateEvery(),
-		AutoDetectEvery: cfg.AutoDetectionRetry(),
-		Priority:        cfg.Priority(),
-		Module:          mod,
-		Out:             m.Out,
+		PluginName:        m.PluginName,
+		Name:              cfg.Name(),
+		ModuleName:        cfg.Module(),
+		FullName:          cfg.FullName(),
+		UpdateEvery:       cfg.UpdateEvery(),
+		AutoDetectEvery:   cfg.AutoDetectionRetry(),
+		Priority:          cfg.Priority(),
+		MaxCharts:         cfg.MaxCharts(),
+		MaxDims:           cfg.MaxDims(),
+		Module:            mod,
+		Out:               m.Out,
+		CardinalityPolicy: policy,
+		Vnode:             vnode.Vnode,
 	})
 	return job, nil
 }
@@ -80,6 +80,40 @@ func TestManager_Run(t *testing.T) {
 	assert.True(t, buf.String() != "")
 }
 
+func TestManager_buildJob_CardinalityPolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy  string
+		wantErr bool
+	}{
+		"not set": {policy: ""},
+		"drop":    {policy: "drop"},
+		"other":   {policy: "other"},
+		"unknown": {policy: "others", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mgr := NewManager()
+			mgr.Modules = prepareMockRegistry()
+			cfg := confgroup.Config{
+				"name":               "name",
+				"module":             "success",
+				"cardinality_policy": test.policy,
+			}
+
+			job, err := mgr.buildJob(cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, job)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, job)
+			}
+		})
+	}
+}
+
 func prepareMockRegistry() module.Registry {
 	reg := module.Registry{}
 	reg.Register("success", module.Creator{
@@ -29,6 +29,9 @@ func (c Config) FullName() string          { return fullName(c.Name(), c.Module(
 func (c Config) UpdateEvery() int          { v, _ := c.get("update_every").(int); return v }
 func (c Config) AutoDetectionRetry() int   { v, _ := c.get("autodetection_retry").(int); return v }
 func (c Config) Priority() int             { v, _ := c.get("priority").(int); return v }
+func (c Config) MaxCharts() int            { v, _ := c.get("max_charts").(int); return v }
+func (c Config) MaxDims() int              { v, _ := c.get("max_dims").(int); return v }
+func (c Config) CardinalityPolicy() string { v, _ := c.get("cardinality_policy").(string); return v }
 func (c Config) Hash() uint64              { return calcHash(c) }
 func (c Config) Source() string            { v, _ := c.get("__source__").(string); return v }
 func (c Config) Provider() string          { v, _ := c.get("__provider__").(string); return v }
@@ -52,6 +55,15 @@ func (c Config) Apply(def Default) {
 		v := firstPositive(def.Priority, module.Priority)
 		c.set("priority", v)
 	}
+	if c.MaxCharts() <= 0 && def.MaxCharts > 0 {
+		c.set("max_charts", def.MaxCharts)
+	}
+	if c.MaxDims() <= 0 && def.MaxDims > 0 {
+		c.set("max_dims", def.MaxDims)
+	}
+	if c.CardinalityPolicy() == "" && def.CardinalityPolicy != "" {
+		c.set("cardinality_policy", def.CardinalityPolicy)
+	}
 	if c.UpdateEvery() < def.MinUpdateEvery && def.MinUpdateEvery > 0 {
 		c.set("update_every", def.MinUpdateEvery)
 	}
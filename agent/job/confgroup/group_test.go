@@ -119,6 +119,60 @@ func TestConfig_Priority(t *testing.T) {
 	}
 }
 
+func TestConfig_MaxCharts(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected interface{}
+	}{
+		"int":     {cfg: Config{"max_charts": 1}, expected: 1},
+		"not int": {cfg: Config{"max_charts": "1"}, expected: 0},
+		"not set": {cfg: Config{}, expected: 0},
+		"nil cfg": {expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.MaxCharts())
+		})
+	}
+}
+
+func TestConfig_MaxDims(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected interface{}
+	}{
+		"int":     {cfg: Config{"max_dims": 1}, expected: 1},
+		"not int": {cfg: Config{"max_dims": "1"}, expected: 0},
+		"not set": {cfg: Config{}, expected: 0},
+		"nil cfg": {expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.MaxDims())
+		})
+	}
+}
+
+func TestConfig_CardinalityPolicy(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected interface{}
+	}{
+		"string":     {cfg: Config{"cardinality_policy": "other"}, expected: "other"},
+		"not string": {cfg: Config{"cardinality_policy": 0}, expected: ""},
+		"not set":    {cfg: Config{}, expected: ""},
+		"nil cfg":    {expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.CardinalityPolicy())
+		})
+	}
+}
+
 func TestConfig_Hash(t *testing.T) {
 	tests := map[string]struct {
 		one, two Config
@@ -298,6 +352,29 @@ func TestConfig_Apply(t *testing.T) {
 				"priority":            module.Priority,
 			},
 		},
+		"+job +def cardinality limits": {
+			def: Default{
+				MaxCharts:         applyDef,
+				MaxDims:           applyDef,
+				CardinalityPolicy: "other",
+			},
+			origCfg: Config{
+				"name":               "name",
+				"module":             "module",
+				"max_charts":         jobDef,
+				"cardinality_policy": "drop",
+			},
+			expectedCfg: Config{
+				"name":                "name",
+				"module":              "module",
+				"update_every":        module.UpdateEvery,
+				"autodetection_retry": module.AutoDetectionRetry,
+				"priority":            module.Priority,
+				"max_charts":          jobDef,
+				"max_dims":            applyDef,
+				"cardinality_policy":  "drop",
+			},
+		},
 		"clean name": {
 			def: Default{},
 			origCfg: Config{
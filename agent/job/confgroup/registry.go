@@ -5,10 +5,13 @@ package confgroup
 type Registry map[string]Default
 
 type Default struct {
-	MinUpdateEvery     int `yaml:"-"`
-	UpdateEvery        int `yaml:"update_every"`
-	AutoDetectionRetry int `yaml:"autodetection_retry"`
-	Priority           int `yaml:"priority"`
+	MinUpdateEvery     int    `yaml:"-"`
+	UpdateEvery        int    `yaml:"update_every"`
+	AutoDetectionRetry int    `yaml:"autodetection_retry"`
+	Priority           int    `yaml:"priority"`
+	MaxCharts          int    `yaml:"max_charts"`
+	MaxDims            int    `yaml:"max_dims"`
+	CardinalityPolicy  string `yaml:"cardinality_policy"`
 }
 
 func (r Registry) Register(name string, def Default) {
@@ -116,6 +116,9 @@ func mergeDef(a, b confgroup.Default) confgroup.Default {
 		UpdateEvery:        firstPositive(a.UpdateEvery, b.UpdateEvery),
 		AutoDetectionRetry: firstPositive(a.AutoDetectionRetry, b.AutoDetectionRetry),
 		Priority:           firstPositive(a.Priority, b.Priority),
+		MaxCharts:          firstPositive(a.MaxCharts, b.MaxCharts),
+		MaxDims:            firstPositive(a.MaxDims, b.MaxDims),
+		CardinalityPolicy:  firstNotEmpty(a.CardinalityPolicy, b.CardinalityPolicy),
 	}
 }
 
@@ -126,6 +129,13 @@ func firstPositive(value int, others ...int) int {
 	return firstPositive(others[0], others[1:]...)
 }
 
+func firstNotEmpty(value string, others ...string) string {
+	if value != "" || len(others) == 0 {
+		return value
+	}
+	return firstNotEmpty(others[0], others[1:]...)
+}
+
 func fileName(path string) string {
 	_, file := filepath.Split(path)
 	ext := filepath.Ext(path)
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"fmt"
+)
+
+// CardinalityPolicy is what the job does with charts and dimensions over the cardinality limits.
+type CardinalityPolicy string
+
+const (
+	// CardinalityDrop policy drops new charts and dimensions over the limits.
+	CardinalityDrop CardinalityPolicy = "drop"
+	// CardinalityOther policy drops new charts over the limit, and sums the values of new dimensions
+	// over the limit into the chart "other" dimension.
+	CardinalityOther CardinalityPolicy = "other"
+)
+
+const (
+	otherDimID   = "cardinality_other"
+	otherDimName = "other"
+)
+
+// IsValid reports whether the policy is known, an empty policy is the default one.
+func (p CardinalityPolicy) IsValid() bool {
+	switch p {
+	case "", CardinalityDrop, CardinalityOther:
+		return true
+	}
+	return false
+}
+
+func (p CardinalityPolicy) String() string {
+	switch p {
+	case CardinalityDrop, CardinalityOther:
+		return string(p)
+	}
+	return string(CardinalityDrop)
+}
+
+// cardinalityGuard limits the number of charts and dimensions a job creates.
+// Only charts and dimensions sent to netdata are counted, removed ones free their place.
+type cardinalityGuard struct {
+	maxCharts int
+	maxDims   int
+	policy    CardinalityPolicy
+
+	charts        int
+	dims          int
+	droppedCharts int
+	droppedDims   int
+
+	chart  *Chart
+	warned map[string]bool
+}
+
+func newCardinalityGuard(maxCharts, maxDims int, policy CardinalityPolicy) *cardinalityGuard {
+	return &cardinalityGuard{
+		maxCharts: maxCharts,
+		maxDims:   maxDims,
+		policy:    CardinalityPolicy(policy.String()),
+		warned:    make(map[string]bool),
+	}
+}
+
+func newCardinalityChart(pluginName, fullName string) *Chart {
+	return &Chart{
+		typ:      "netdata",
+		ID:       fmt.Sprintf("cardinality_limit_of_%s", fullName),
+		Title:    "Charts and dimensions dropped due to the cardinality limits",
+		Units:    "entities",
+		Fam:      pluginName,
+		Ctx:      fmt.Sprintf("netdata.%s_plugin_cardinality_limit", pluginCtxName(pluginName)),
+		Priority: 145001,
+		Dims: Dims{
+			{ID: "dropped_charts", Name: "charts"},
+			{ID: "dropped_dims", Name: "dimensions"},
+		},
+	}
+}
+
+func (g *cardinalityGuard) enabled() bool {
+	return g.maxCharts > 0 || g.maxDims > 0
+}
+
+// limited reports whether the guard dropped anything since the job start.
+func (g *cardinalityGuard) limited() bool {
+	return len(g.warned) > 0
+}
+
+// check decides on the chart and its new dimensions before the chart is created.
+// It marks the chart ignored if it is over the charts limit, the chart is admitted once a removed chart frees a place.
+func (g *cardinalityGuard) check(j *Job, chart *Chart) {
+	if !g.enabled() || chart.remove || chart.ignore && !chart.limited {
+		return
+	}
+
+	if chart.limited {
+		if g.maxCharts > 0 && g.charts >= g.maxCharts {
+			return
+		}
+		chart.ignore = false
+		chart.limited = false
+		chart.created = false
+		g.droppedCharts--
+	}
+
+	if !chart.counted {
+		if g.maxCharts > 0 && g.charts >= g.maxCharts {
+			chart.ignore = true
+			chart.limited = true
+			g.droppedCharts++
+			g.warn(j, "charts", "chart '%s' is dropped, the job reached the charts limit (%d)", chart.ID, g.maxCharts)
+			return
+		}
+		chart.counted = true
+		g.charts++
+	}
+
+	for _, dim := range chart.Dims {
+		if dim.remove || dim.counted || dim.limited {
+			continue
+		}
+		if g.maxDims > 0 && g.dims >= g.maxDims {
+			dim.limited = true
+			g.droppedDims++
+			g.warn(j, "dims", "dimension '%s' of chart '%s' is %s, the job reached the dimensions limit (%d)",
+				dim.ID, chart.ID, g.dropVerb(), g.maxDims)
+			continue
+		}
+		dim.counted = true
+		g.dims++
+	}
+}
+
+// releaseChart frees the places of the removed chart and its dimensions.
+func (g *cardinalityGuard) releaseChart(chart *Chart) {
+	if chart.limited {
+		chart.limited = false
+		g.droppedCharts--
+	}
+	if chart.counted {
+		chart.counted = false
+		g.charts--
+	}
+	for _, dim := range chart.Dims {
+		g.releaseDim(dim)
+	}
+}
+
+// releaseDim frees the place of the removed dimension.
+func (g *cardinalityGuard) releaseDim(dim *Dim) {
+	if dim.limited {
+		dim.limited = false
+		g.droppedDims--
+	}
+	if dim.counted {
+		dim.counted = false
+		g.dims--
+	}
+}
+
+func (g *cardinalityGuard) metrics() map[string]int64 {
+	return map[string]int64{
+		"dropped_charts": int64(g.droppedCharts),
+		"dropped_dims":   int64(g.droppedDims),
+	}
+}
+
+func (g *cardinalityGuard) dropVerb() string {
+	if g.policy == CardinalityOther {
+		return "aggregated into '" + otherDimName + "'"
+	}
+	return "dropped"
+}
+
+func (g *cardinalityGuard) warn(j *Job, kind, format string, args ...interface{}) {
+	if g.warned[kind] {
+		return
+	}
+	g.warned[kind] = true
+	if j.Logger != nil {
+		j.Warningf(format+" (logged once)", args...)
+	}
+}
+
+// otherDim returns the "other" dimension of the chart, it is based on the first limited dimension.
+func (g *cardinalityGuard) otherDim(chart *Chart) *Dim {
+	if g.policy != CardinalityOther {
+		return nil
+	}
+	for _, dim := range chart.Dims {
+		if dim.limited && !dim.remove {
+			return &Dim{ID: otherDimID, Name: otherDimName, Algo: dim.Algo, Mul: dim.Mul, Div: dim.Div}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCardinalityTestJob(maxCharts, maxDims int, policy CardinalityPolicy, mod Module) (*Job, *bytes.Buffer) {
+	var out bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName:        pluginName,
+		Name:              jobName,
+		ModuleName:        modName,
+		FullName:          modName + "_" + jobName,
+		Module:            mod,
+		Out:               &out,
+		UpdateEvery:       1,
+		MaxCharts:         maxCharts,
+		MaxDims:           maxDims,
+		CardinalityPolicy: policy,
+	})
+	job.charts = mod.Charts()
+	return job, &out
+}
+
+func newCardinalityTestChart(id string, dims ...string) *Chart {
+	chart := &Chart{ID: id, Title: "title", Units: "units"}
+	for _, dim := range dims {
+		chart.Dims = append(chart.Dims, &Dim{ID: dim, Algo: Incremental})
+	}
+	return chart
+}
+
+func TestCardinalityPolicy_String(t *testing.T) {
+	assert.Equal(t, "drop", CardinalityDrop.String())
+	assert.Equal(t, "other", CardinalityOther.String())
+	assert.Equal(t, "drop", CardinalityPolicy("").String())
+	assert.Equal(t, "drop", CardinalityPolicy("unknown").String())
+}
+
+func TestJob_Cardinality_NoLimits(t *testing.T) {
+	charts := &Charts{
+		newCardinalityTestChart("chart1", "dim1", "dim2"),
+		newCardinalityTestChart("chart2", "dim3"),
+	}
+	mod := &MockModule{
+		ChartsFunc:  func() *Charts { return charts },
+		CollectFunc: func() map[string]int64 { return map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3} },
+	}
+	job, out := newCardinalityTestJob(0, 0, "", mod)
+
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "CHART 'module_job.chart2'")
+	assert.NotContains(t, out.String(), "cardinality_limit")
+	assert.Zero(t, job.cardinality.charts)
+	assert.Zero(t, job.cardinality.dims)
+}
+
+func TestJob_Cardinality_MaxCharts(t *testing.T) {
+	charts := &Charts{
+		newCardinalityTestChart("chart1", "dim1"),
+		newCardinalityTestChart("chart2", "dim2"),
+		newCardinalityTestChart("chart3", "dim3"),
+	}
+	mod := &MockModule{
+		ChartsFunc:  func() *Charts { return charts },
+		CollectFunc: func() map[string]int64 { return map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3} },
+	}
+	job, out := newCardinalityTestJob(2, 0, CardinalityDrop, mod)
+
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "CHART 'module_job.chart1'")
+	assert.Contains(t, out.String(), "CHART 'module_job.chart2'")
+	assert.NotContains(t, out.String(), "chart3")
+	assert.Contains(t, out.String(), "CHART 'netdata.cardinality_limit_of_module_job'")
+	assert.Contains(t, out.String(), "SET 'charts' = 1")
+	assert.Contains(t, out.String(), "SET 'dimensions' = 0")
+
+}
+
+func TestJob_Cardinality_MaxCharts_AdmitsDroppedChart(t *testing.T) {
+	charts := &Charts{
+		newCardinalityTestChart("chart1", "dim1"),
+		newCardinalityTestChart("chart2", "dim2"),
+		newCardinalityTestChart("chart3", "dim3"),
+	}
+	mod := &MockModule{
+		ChartsFunc: func() *Charts { return charts },
+		CollectFunc: func() map[string]int64 {
+			return map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3, "dim4": 4}
+		},
+	}
+	job, out := newCardinalityTestJob(2, 0, CardinalityDrop, mod)
+
+	job.runOnce()
+
+	require.NotContains(t, out.String(), "chart3")
+	require.Equal(t, 1, job.cardinality.droppedCharts)
+
+	// the removed chart frees its place, the dropped chart takes it before the new one
+	charts.Get("chart1").MarkRemove()
+	require.NoError(t, charts.Add(newCardinalityTestChart("chart4", "dim4")))
+	out.Reset()
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "CHART 'module_job.chart3'")
+	assert.Contains(t, out.String(), "DIMENSION 'dim3'")
+	assert.Contains(t, out.String(), "SET 'dim3' = 3")
+	assert.NotContains(t, out.String(), "chart4")
+	assert.Equal(t, 2, job.cardinality.charts)
+	assert.Equal(t, 1, job.cardinality.droppedCharts)
+	assert.Equal(t, 2, job.cardinality.dims)
+
+	charts.Get("chart3").MarkRemove()
+	out.Reset()
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "CHART 'module_job.chart4'")
+	assert.Contains(t, out.String(), "SET 'charts' = 0")
+	assert.Equal(t, 2, job.cardinality.charts)
+	assert.Equal(t, 0, job.cardinality.droppedCharts)
+}
+
+func TestJob_Cardinality_MaxDims_Drop(t *testing.T) {
+	chart := newCardinalityTestChart("chart1", "dim1", "dim2", "dim3")
+	charts := &Charts{chart}
+	mod := &MockModule{
+		ChartsFunc:  func() *Charts { return charts },
+		CollectFunc: func() map[string]int64 { return map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3, "dim4": 4} },
+	}
+	job, out := newCardinalityTestJob(0, 2, CardinalityDrop, mod)
+
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "DIMENSION 'dim1'")
+	assert.Contains(t, out.String(), "DIMENSION 'dim2'")
+	assert.NotContains(t, out.String(), "dim3")
+	assert.NotContains(t, out.String(), otherDimID)
+	assert.Contains(t, out.String(), "SET 'dimensions' = 1")
+
+	// a dim added in runtime is dropped too, a removed dim frees its place only for new dims
+	require.NoError(t, chart.AddDim(&Dim{ID: "dim4"}))
+	chart.MarkNotCreated()
+	out.Reset()
+	job.runOnce()
+
+	assert.NotContains(t, out.String(), "dim4")
+	assert.Contains(t, out.String(), "SET 'dimensions' = 2")
+
+	require.NoError(t, chart.MarkDimRemove("dim1", true))
+	out.Reset()
+	job.runOnce()
+
+	assert.Equal(t, 1, job.cardinality.dims)
+	assert.Equal(t, 2, job.cardinality.droppedDims)
+}
+
+func TestJob_Cardinality_MaxDims_Other(t *testing.T) {
+	charts := &Charts{
+		newCardinalityTestChart("chart1", "dim1", "dim2", "dim3"),
+		newCardinalityTestChart("chart2", "dim4"),
+	}
+	mod := &MockModule{
+		ChartsFunc:  func() *Charts { return charts },
+		CollectFunc: func() map[string]int64 { return map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3} },
+	}
+	job, out := newCardinalityTestJob(0, 1, CardinalityOther, mod)
+
+	job.runOnce()
+
+	assert.Contains(t, out.String(), "DIMENSION 'dim1'")
+	assert.Contains(t, out.String(), fmt.Sprintf("DIMENSION '%s' '%s' 'incremental'", otherDimID, otherDimName))
+	assert.Contains(t, out.String(), fmt.Sprintf("SET '%s' = 5", otherDimID))
+	assert.NotContains(t, out.String(), "DIMENSION 'dim4'")
+	// chart2 has no values for its "other" dimension
+	assert.Contains(t, out.String(), fmt.Sprintf("SET '%s' = \n", otherDimID))
+	assert.Contains(t, out.String(), "SET 'dimensions' = 3")
+}
+
+func TestChart_Copy_ResetsCardinality(t *testing.T) {
+	chart := newCardinalityTestChart("chart1", "dim1")
+	chart.counted, chart.limited = true, true
+	chart.Dims[0].counted, chart.Dims[0].limited = true, true
+
+	cp := chart.Copy()
+
+	assert.False(t, cp.counted || cp.limited)
+	assert.False(t, cp.Dims[0].counted || cp.Dims[0].limited)
+}
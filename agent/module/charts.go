@@ -94,6 +94,11 @@ type (
 		Vars   Vars
 
 		Retries int
+		// ObsoleteAfter enables automatic removal of stale dynamic charts and dimensions, zero disables it.
+		// The job removes the chart if none of its dimensions got a value for ObsoleteAfter consecutive
+		// data collections, and a dimension if it got no value for ObsoleteAfter consecutive data collections.
+		// Failed data collections are not counted.
+		ObsoleteAfter int
 
 		remove bool
 		// created flag is used to indicate whether the chart needs to be created by the orchestrator.
@@ -103,6 +108,10 @@ type (
 
 		// ignore flag is used to indicate that the chart shouldn't be sent to the netdata plugins.d
 		ignore bool
+		// counted and limited flags are used by the job cardinality guard:
+		// the chart is counted against the limit, or it is over the limit.
+		counted bool
+		limited bool
 	}
 
 	Label struct {
@@ -130,6 +139,11 @@ type (
 		DimOpts
 
 		remove bool
+		// retries is the number of consecutive data collections without the dimension value.
+		retries int
+		// counted and limited flags are used by the job cardinality guard.
+		counted bool
+		limited bool
 	}
 
 	// Var represents a chart variable.
@@ -333,6 +347,7 @@ func (c Chart) HasDim(dimID string) bool {
 // Copy returns a deep copy of the chart.
 func (c Chart) Copy() *Chart {
 	chart := c
+	chart.counted, chart.limited = false, false
 	chart.Dims = Dims{}
 	chart.Vars = Vars{}
 
@@ -365,6 +380,7 @@ func (c Chart) indexVar(varID string) int {
 }
 
 func (d Dim) copy() *Dim {
+	d.counted, d.limited = false, false
 	return &d
 }
 
@@ -446,6 +462,17 @@ func checkVar(v *Var) error {
 	return nil
 }
 
+// CleanID returns the ID with the characters that are not allowed in chart, dimension and variable IDs
+// (whitespace and quotes) replaced with '_'.
+func CleanID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\'' || r == '"' {
+			return '_'
+		}
+		return r
+	}, id)
+}
+
 func checkID(id string) int {
 	for _, r := range id {
 		if unicode.IsSpace(r) {
@@ -35,19 +35,22 @@ var writeLock = &sync.Mutex{}
 
 var reSpace = regexp.MustCompile(`\s+`)
 
-func newRuntimeChart(pluginName string) *Chart {
+func pluginCtxName(pluginName string) string {
 	// this is needed to keep the same name as we had before https://github.com/netdata/go.d.plugin/issues/650
 	ctxName := pluginName
 	if ctxName == "go.d" {
 		ctxName = "go"
 	}
-	ctxName = reSpace.ReplaceAllString(ctxName, "_")
+	return reSpace.ReplaceAllString(ctxName, "_")
+}
+
+func newRuntimeChart(pluginName string) *Chart {
 	return &Chart{
 		typ:      "netdata",
 		Title:    "Execution time",
 		Units:    "ms",
 		Fam:      pluginName,
-		Ctx:      fmt.Sprintf("netdata.%s_plugin_execution_time", ctxName),
+		Ctx:      fmt.Sprintf("netdata.%s_plugin_execution_time", pluginCtxName(pluginName)),
 		Priority: 145000,
 		Dims: Dims{
 			{ID: "time"},
@@ -65,6 +68,14 @@ type JobConfig struct {
 	UpdateEvery     int
 	AutoDetectEvery int
 	Priority        int
+	// MaxCharts is the maximum number of job charts, zero means no limit.
+	MaxCharts int
+	// MaxDims is the maximum number of job dimensions (all charts), zero means no limit.
+	MaxDims int
+	// CardinalityPolicy is the policy for the dimensions over MaxDims, the default is CardinalityDrop.
+	CardinalityPolicy CardinalityPolicy
+	// Vnode is the virtual node the job charts go to, it takes precedence over the module VirtualNodeProvider.
+	Vnode *VirtualNode
 }
 
 const (
@@ -75,6 +86,11 @@ const (
 
 func NewJob(cfg JobConfig) *Job {
 	var buf bytes.Buffer
+	var vnode *VirtualNode
+	if cfg.Vnode != nil {
+		cp := *cfg.Vnode
+		vnode = &cp
+	}
 	return &Job{
 		pluginName:      cfg.PluginName,
 		name:            cfg.Name,
@@ -87,6 +103,8 @@ func NewJob(cfg JobConfig) *Job {
 		out:             cfg.Out,
 		AutoDetectTries: infTries,
 		runChart:        newRuntimeChart(cfg.PluginName),
+		cardinality:     newCardinalityGuard(cfg.MaxCharts, cfg.MaxDims, cfg.CardinalityPolicy),
+		vnode:           vnode,
 		stop:            make(chan struct{}),
 		tick:            make(chan int),
 		buf:             &buf,
@@ -113,12 +131,17 @@ type Job struct {
 	initialized bool
 	panicked    bool
 
-	runChart *Chart
-	charts   *Charts
-	tick     chan int
-	out      io.Writer
-	buf      *bytes.Buffer
-	api      *netdataapi.API
+	runChart    *Chart
+	charts      *Charts
+	cardinality *cardinalityGuard
+
+	vnode        *VirtualNode
+	vnodeCreated bool
+
+	tick chan int
+	out  io.Writer
+	buf  *bytes.Buffer
+	api  *netdataapi.API
 
 	retries int
 	prevRun time.Time
@@ -248,13 +271,21 @@ func (j *Job) Cleanup() {
 		j.runChart.MarkRemove()
 		j.createChart(j.runChart)
 	}
+	if chart := j.cardinality.chart; chart != nil && chart.created {
+		chart.MarkRemove()
+		j.createChart(chart)
+	}
 	if j.charts != nil {
+		vnode := j.vnodeCreated && j.switchToVirtualNode()
 		for _, chart := range *j.charts {
 			if chart.created {
 				chart.MarkRemove()
 				j.createChart(chart)
 			}
 		}
+		if vnode {
+			j.switchToLocalNode()
+		}
 	}
 	if j.buf.Len() > 0 {
 		writeLock.Lock()
@@ -293,6 +324,10 @@ func (j *Job) postCheck() bool {
 		j.Errorf("charts check: %v", err)
 		return false
 	}
+	if err := j.setupVirtualNode(); err != nil {
+		j.Error(err)
+		return false
+	}
 	return true
 }
 
@@ -341,8 +376,13 @@ func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinc
 
 	elapsed := int64(durationTo(time.Since(startTime), time.Millisecond))
 
+	vnode := j.switchToVirtualNode()
+
 	var i, updated int
 	for _, chart := range *j.charts {
+		if chart.limited {
+			j.cardinality.check(j, chart)
+		}
 		if !chart.created {
 			typeID := fmt.Sprintf("%s.%s", j.FullName(), chart.ID)
 			if len(typeID) >= NetdataChartIDMaxLength {
@@ -350,9 +390,11 @@ func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinc
 					len(typeID), NetdataChartIDMaxLength, typeID)
 				chart.ignore = true
 			}
+			j.cardinality.check(j, chart)
 			j.createChart(chart)
 		}
 		if chart.remove {
+			j.cardinality.releaseChart(chart)
 			continue
 		}
 		(*j.charts)[i] = chart
@@ -366,13 +408,31 @@ func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinc
 	}
 	*j.charts = (*j.charts)[:i]
 
+	if vnode {
+		j.switchToLocalNode()
+	}
+
 	if updated == 0 {
 		return false
 	}
 	j.updateChart(j.runChart, map[string]int64{"time": elapsed}, sinceLastRun)
+	j.updateCardinalityChart(sinceLastRun)
 	return true
 }
 
+func (j *Job) updateCardinalityChart(sinceLastRun int) {
+	if !j.cardinality.limited() {
+		return
+	}
+	if j.cardinality.chart == nil {
+		j.cardinality.chart = newCardinalityChart(j.pluginName, j.FullName())
+	}
+	if chart := j.cardinality.chart; !chart.created {
+		j.createChart(chart)
+	}
+	j.updateChart(j.cardinality.chart, j.cardinality.metrics(), sinceLastRun)
+}
+
 func (j *Job) createChart(chart *Chart) {
 	defer func() { chart.created = true }()
 	if chart.ignore {
@@ -412,6 +472,9 @@ func (j *Job) createChart(chart *Chart) {
 	}
 
 	for _, dim := range chart.Dims {
+		if dim.limited {
+			continue
+		}
 		_ = j.api.DIMENSION(
 			firstNotEmpty(dim.Name, dim.ID),
 			dim.Name,
@@ -421,6 +484,16 @@ func (j *Job) createChart(chart *Chart) {
 			dim.DimOpts.String(),
 		)
 	}
+	if dim := j.cardinality.otherDim(chart); dim != nil {
+		_ = j.api.DIMENSION(
+			dim.ID,
+			dim.Name,
+			dim.Algo.String(),
+			handleZero(dim.Mul),
+			handleZero(dim.Div),
+			dim.DimOpts.String(),
+		)
+	}
 	for _, v := range chart.Vars {
 		_ = j.api.VARIABLE(
 			v.ID,
@@ -436,6 +509,8 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 		for _, dim := range chart.Dims {
 			if !dim.remove {
 				dims = append(dims, dim)
+			} else {
+				j.cardinality.releaseDim(dim)
 			}
 		}
 		chart.Dims = dims
@@ -452,13 +527,30 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 		sinceLastRun,
 	)
 	var i, updated int
+	var other int64
+	var hasOther bool
 	for _, dim := range chart.Dims {
 		if dim.remove {
+			j.cardinality.releaseDim(dim)
 			continue
 		}
 		chart.Dims[i] = dim
 		i++
-		if v, ok := collected[dim.ID]; !ok {
+		v, ok := collected[dim.ID]
+		if ok {
+			dim.retries = 0
+		} else {
+			dim.retries++
+		}
+		if dim.limited {
+			if ok && j.cardinality.policy == CardinalityOther {
+				other += v
+				hasOther = true
+				updated++
+			}
+			continue
+		}
+		if !ok {
 			_ = j.api.SETEMPTY(firstNotEmpty(dim.Name, dim.ID))
 		} else {
 			_ = j.api.SET(firstNotEmpty(dim.Name, dim.ID), v)
@@ -467,6 +559,12 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 	}
 	chart.Dims = chart.Dims[:i]
 
+	if hasOther {
+		_ = j.api.SET(otherDimID, other)
+	} else if j.cardinality.otherDim(chart) != nil {
+		_ = j.api.SETEMPTY(otherDimID)
+	}
+
 	for _, vr := range chart.Vars {
 		if v, ok := collected[vr.ID]; ok {
 			_ = j.api.VARIABLE(vr.ID, v)
@@ -480,9 +578,32 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 	} else {
 		chart.Retries++
 	}
+	if chart.ObsoleteAfter > 0 {
+		removeStale(chart)
+	}
 	return chart.updated
 }
 
+// removeStale marks the chart or its dimensions to be removed if they got no values
+// for chart ObsoleteAfter consecutive data collections.
+func removeStale(chart *Chart) {
+	if chart.Retries >= chart.ObsoleteAfter {
+		chart.MarkRemove()
+		chart.MarkNotCreated()
+		return
+	}
+	var removed bool
+	for _, dim := range chart.Dims {
+		if !dim.remove && dim.retries >= chart.ObsoleteAfter {
+			_ = chart.MarkDimRemove(dim.ID, true)
+			removed = true
+		}
+	}
+	if removed {
+		chart.MarkNotCreated()
+	}
+}
+
 func (j Job) penalty() int {
 	v := j.retries / penaltyStep * penaltyStep * j.updateEvery / 2
 	if v > maxPenalty {
@@ -3,6 +3,7 @@
 package module
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"testing"
@@ -288,3 +289,44 @@ func TestJob_Tick(t *testing.T) {
 		job.Tick(i)
 	}
 }
+
+func TestJob_ObsoleteStaleCharts(t *testing.T) {
+	var out bytes.Buffer
+	metrics := map[string]int64{"dim1": 1, "dim2": 2, "dim3": 3}
+	charts := &Charts{
+		{ID: "chart1", Title: "title", Units: "units", ObsoleteAfter: 2, Dims: Dims{{ID: "dim1"}, {ID: "dim2"}}},
+		{ID: "chart2", Title: "title", Units: "units", ObsoleteAfter: 2, Dims: Dims{{ID: "dim3"}}},
+		{ID: "chart3", Title: "title", Units: "units", Dims: Dims{{ID: "dim4"}}},
+	}
+	job := newTestJob()
+	job.out = &out
+	job.module = &MockModule{CollectFunc: func() map[string]int64 { return metrics }}
+	job.charts = charts
+
+	job.runOnce()
+	delete(metrics, "dim2")
+	delete(metrics, "dim3")
+	job.runOnce()
+	assert.Len(t, *charts, 3)
+	assert.Len(t, charts.Get("chart1").Dims, 2)
+
+	// dim2 and chart2 got no values for 2 data collections, chart3 has no ObsoleteAfter
+	job.runOnce()
+	assert.True(t, charts.Get("chart1").GetDim("dim2").Obsolete)
+	assert.True(t, charts.Get("chart2").Obsolete)
+
+	out.Reset()
+	job.runOnce()
+	assert.Contains(t, out.String(), "DIMENSION 'dim2' '' 'absolute' '1' '1' 'hidden obsolete'")
+	assert.Contains(t, out.String(), "CHART 'module_job.chart2' '' 'title' 'units' '' '' 'line' '1' '0' 'obsolete'")
+	assert.Len(t, *charts, 2)
+	assert.Len(t, charts.Get("chart1").Dims, 1)
+	assert.True(t, charts.Has("chart3"))
+
+	// failed data collections are not counted
+	metrics = nil
+	for i := 0; i < 3; i++ {
+		job.runOnce()
+	}
+	assert.False(t, charts.Get("chart1").Obsolete)
+}
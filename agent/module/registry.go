@@ -16,6 +16,9 @@ type Defaults struct {
 	AutoDetectionRetry int
 	Priority           int
 	Disabled           bool
+	// MaxCharts and MaxDims are the job cardinality limits, zero means no limit.
+	MaxCharts int
+	MaxDims   int
 }
 
 type (
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ChartTemplate is a set of charts of an instance (database, container, user, etc.) with placeholders.
+// The chart and dimension IDs, names, titles, families, contexts, variable IDs and label values
+// are text/template templates executed with the instance labels, e.g. "db_{{.db}}_size".
+// The "id" function cleans a label value for IDs (see CleanID), e.g. "file_{{id .path}}_size".
+//
+// ChartTemplate tracks the added instances, so their charts can be removed by key.
+// It is not safe for concurrent use, create one per module instance.
+type ChartTemplate struct {
+	charts    Charts
+	tmpls     map[string]*template.Template
+	instances map[string]Charts
+}
+
+// NewChartTemplate returns a new chart template. The charts are copied.
+// It returns an error if any of the templates can't be parsed.
+func NewChartTemplate(charts Charts) (*ChartTemplate, error) {
+	t := &ChartTemplate{
+		charts:    *charts.Copy(),
+		tmpls:     make(map[string]*template.Template),
+		instances: make(map[string]Charts),
+	}
+
+	for _, chart := range t.charts {
+		for _, s := range chartTemplateFields(chart) {
+			if err := t.parse(*s); err != nil {
+				return nil, fmt.Errorf("chart '%s' template: %v", chart.ID, err)
+			}
+		}
+	}
+	return t, nil
+}
+
+// MustNewChartTemplate is like NewChartTemplate but panics if the templates can't be parsed.
+// It is intended for use in module constructors.
+func MustNewChartTemplate(charts Charts) *ChartTemplate {
+	t, err := NewChartTemplate(charts)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Instantiate returns the charts of the instance. All the placeholders must be in the labels.
+// The labels are added to the charts labels (sorted by key) after the template ones.
+func (t *ChartTemplate) Instantiate(labels map[string]string) (*Charts, error) {
+	charts := t.charts.Copy()
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, chart := range *charts {
+		chart.Labels = append([]Label(nil), chart.Labels...)
+		for _, s := range chartTemplateFields(chart) {
+			v, err := t.execute(*s, labels)
+			if err != nil {
+				return nil, fmt.Errorf("chart '%s' template: %v", chart.ID, err)
+			}
+			*s = v
+		}
+		for _, k := range keys {
+			chart.Labels = append(chart.Labels, Label{Key: k, Value: labels[k]})
+		}
+	}
+	return charts, nil
+}
+
+// Add instantiates the charts of the instance and adds them to the charts.
+// It returns an error if the instance with the key is already added.
+// The charts are added all or none, they are unchanged on error.
+func (t *ChartTemplate) Add(charts *Charts, key string, labels map[string]string) error {
+	if t.Has(key) {
+		return fmt.Errorf("chart template: instance '%s' is already added", key)
+	}
+	instance, err := t.Instantiate(labels)
+	if err != nil {
+		return err
+	}
+	for i, chart := range *instance {
+		if err := checkChart(chart); err != nil {
+			return fmt.Errorf("error on adding chart : %s", err)
+		}
+		if hasNotRemovedChart(*charts, chart.ID) || (*instance)[:i].Has(chart.ID) {
+			return fmt.Errorf("error on adding chart : '%s' is already in charts", chart.ID)
+		}
+	}
+	*charts = append(*charts, *instance...)
+	t.instances[key] = *instance
+	return nil
+}
+
+// Remove marks the charts of the instance to be removed (see Chart.MarkRemove).
+// It returns false if there is no instance with the key.
+func (t *ChartTemplate) Remove(key string) bool {
+	instance, ok := t.instances[key]
+	if !ok {
+		return false
+	}
+	for _, chart := range instance {
+		chart.MarkRemove()
+		chart.MarkNotCreated()
+	}
+	delete(t.instances, key)
+	return true
+}
+
+// Has returns true if the instance with the key is added.
+func (t *ChartTemplate) Has(key string) bool {
+	_, ok := t.instances[key]
+	return ok
+}
+
+// Keys returns the keys of the added instances, sorted.
+func (t *ChartTemplate) Keys() []string {
+	keys := make([]string, 0, len(t.instances))
+	for k := range t.instances {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *ChartTemplate) parse(s string) error {
+	if !strings.Contains(s, "{{") || t.tmpls[s] != nil {
+		return nil
+	}
+	tmpl, err := template.New("").Funcs(chartTemplateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return err
+	}
+	t.tmpls[s] = tmpl
+	return nil
+}
+
+func (t *ChartTemplate) execute(s string, labels map[string]string) (string, error) {
+	tmpl, ok := t.tmpls[s]
+	if !ok {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, labels); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func hasNotRemovedChart(charts Charts, id string) bool {
+	for _, chart := range charts {
+		if chart.ID == id && !chart.remove {
+			return true
+		}
+	}
+	return false
+}
+
+var chartTemplateFuncs = template.FuncMap{"id": CleanID}
+
+// chartTemplateFields returns pointers to the chart fields that can have placeholders.
+func chartTemplateFields(chart *Chart) []*string {
+	fields := []*string{&chart.ID, &chart.OverID, &chart.Title, &chart.Fam, &chart.Ctx}
+	for _, dim := range chart.Dims {
+		fields = append(fields, &dim.ID, &dim.Name)
+	}
+	for _, v := range chart.Vars {
+		fields = append(fields, &v.ID)
+	}
+	for i := range chart.Labels {
+		fields = append(fields, &chart.Labels[i].Value)
+	}
+	return fields
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testChartTemplate = Charts{
+	{
+		ID:    "db_{{.db}}_size",
+		Title: "Database size",
+		Units: "bytes",
+		Fam:   "db {{.db}}",
+		Ctx:   "test.db_size",
+		Dims: Dims{
+			{ID: "db_{{.db}}_size", Name: "size"},
+		},
+		Vars: Vars{
+			{ID: "db_{{.db}}_max_size"},
+		},
+		Labels: []Label{
+			{Key: "instance", Value: "{{.db}}@{{.host}}"},
+		},
+	},
+	{
+		ID:    "db_{{.db}}_connections",
+		Title: "Database connections",
+		Units: "connections",
+		Ctx:   "test.db_connections",
+		Dims: Dims{
+			{ID: "db_{{.db}}_connections", Name: "{{.db}}"},
+		},
+	},
+}
+
+func TestNewChartTemplate(t *testing.T) {
+	_, err := NewChartTemplate(testChartTemplate)
+	assert.NoError(t, err)
+
+	_, err = NewChartTemplate(Charts{{ID: "db_{{.db", Title: "title", Units: "units"}})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustNewChartTemplate(Charts{{ID: "db_{{.db}", Title: "title", Units: "units"}}) })
+}
+
+func TestChartTemplate_Instantiate(t *testing.T) {
+	tmpl := MustNewChartTemplate(testChartTemplate)
+
+	charts, err := tmpl.Instantiate(map[string]string{"db": "postgres", "host": "localhost"})
+	require.NoError(t, err)
+	require.Len(t, *charts, 2)
+
+	chart := (*charts)[0]
+	assert.Equal(t, "db_postgres_size", chart.ID)
+	assert.Equal(t, "db postgres", chart.Fam)
+	assert.Equal(t, "test.db_size", chart.Ctx)
+	assert.Equal(t, "db_postgres_size", chart.Dims[0].ID)
+	assert.Equal(t, "size", chart.Dims[0].Name)
+	assert.Equal(t, "db_postgres_max_size", chart.Vars[0].ID)
+	assert.Equal(t, []Label{
+		{Key: "instance", Value: "postgres@localhost"},
+		{Key: "db", Value: "postgres"},
+		{Key: "host", Value: "localhost"},
+	}, chart.Labels)
+	assert.Equal(t, "postgres", (*charts)[1].Dims[0].Name)
+
+	// the template is not modified
+	assert.Equal(t, "db_{{.db}}_size", testChartTemplate[0].ID)
+	assert.Equal(t, "{{.db}}@{{.host}}", testChartTemplate[0].Labels[0].Value)
+	assert.Equal(t, "db_{{.db}}_size", tmpl.charts[0].Dims[0].ID)
+
+	_, err = tmpl.Instantiate(map[string]string{"host": "localhost"})
+	assert.Error(t, err)
+}
+
+func TestChartTemplate_AddRemove(t *testing.T) {
+	tmpl := MustNewChartTemplate(testChartTemplate)
+	charts := &Charts{}
+
+	require.NoError(t, tmpl.Add(charts, "db1", map[string]string{"db": "db1", "host": "localhost"}))
+	require.NoError(t, tmpl.Add(charts, "db2", map[string]string{"db": "db2", "host": "localhost"}))
+	assert.Error(t, tmpl.Add(charts, "db2", map[string]string{"db": "db2", "host": "localhost"}))
+	assert.Error(t, tmpl.Add(charts, "db3", map[string]string{"db": "db3"}))
+
+	assert.Len(t, *charts, 4)
+	assert.True(t, tmpl.Has("db1"))
+	assert.Equal(t, []string{"db1", "db2"}, tmpl.Keys())
+
+	assert.True(t, tmpl.Remove("db1"))
+	assert.False(t, tmpl.Remove("db1"))
+	assert.False(t, tmpl.Has("db1"))
+	assert.Equal(t, []string{"db2"}, tmpl.Keys())
+
+	for _, chart := range *charts {
+		removed := chart.ID == "db_db1_size" || chart.ID == "db_db1_connections"
+		assert.Equalf(t, removed, chart.remove, "chart '%s'", chart.ID)
+		assert.Equalf(t, removed, chart.Obsolete, "chart '%s'", chart.ID)
+	}
+
+	// the removed instance can be added again
+	assert.NoError(t, tmpl.Add(charts, "db1", map[string]string{"db": "db1", "host": "localhost"}))
+}
+
+func TestChartTemplate_Add_AllOrNone(t *testing.T) {
+	tmpl := MustNewChartTemplate(testChartTemplate)
+	charts := &Charts{
+		{ID: "db_db1_connections", Title: "title", Units: "units"},
+	}
+
+	// the first instance chart is fine, the second one is a duplicate
+	assert.Error(t, tmpl.Add(charts, "db1", map[string]string{"db": "db1", "host": "localhost"}))
+	assert.Len(t, *charts, 1)
+	assert.False(t, tmpl.Has("db1"))
+
+	charts.Get("db_db1_connections").MarkRemove()
+	assert.NoError(t, tmpl.Add(charts, "db1", map[string]string{"db": "db1", "host": "localhost"}))
+	assert.Len(t, *charts, 3)
+
+	// the instance charts are checked too
+	tmpl = MustNewChartTemplate(Charts{
+		{ID: "db_{{.db}}_size", Title: "title", Units: "units"},
+		{ID: "db_{{.db}}_size", Title: "title", Units: "units"},
+	})
+	charts = &Charts{}
+	assert.Error(t, tmpl.Add(charts, "db1", map[string]string{"db": "db1"}))
+	assert.Empty(t, *charts)
+}
+
+func TestChartTemplate_Instantiate_IDFunc(t *testing.T) {
+	tmpl := MustNewChartTemplate(Charts{
+		{
+			ID:    "file_{{id .file}}_size",
+			Title: "File size",
+			Units: "bytes",
+			Fam:   "{{.file}}",
+			Dims:  Dims{{ID: "file_{{id .file}}_size", Name: "size"}},
+		},
+	})
+
+	charts, err := tmpl.Instantiate(map[string]string{"file": "/etc/my certs/'a'.pem"})
+	require.NoError(t, err)
+
+	chart := (*charts)[0]
+	assert.Equal(t, "file_/etc/my_certs/_a_.pem_size", chart.ID)
+	assert.Equal(t, "file_/etc/my_certs/_a_.pem_size", chart.Dims[0].ID)
+	assert.Equal(t, "/etc/my certs/'a'.pem", chart.Fam)
+	assert.Equal(t, []Label{{Key: "file", Value: "/etc/my certs/'a'.pem"}}, chart.Labels)
+	assert.NoError(t, checkChart(chart))
+}
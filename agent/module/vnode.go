@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// VirtualNode is a Netdata host that represents the job target (network device, virtual machine, remote server).
+	// All the job charts go to the virtual node instead of the local host.
+	VirtualNode struct {
+		// GUID is the node unique id, if not set it is derived from the hostname.
+		GUID     string            `yaml:"guid"`
+		Hostname string            `yaml:"hostname"`
+		Labels   map[string]string `yaml:"labels"`
+	}
+
+	// VirtualNodeProvider is implemented by modules that monitor a remote target and can report its identity.
+	// VirtualNode is called once after a successful Check, nil means the job charts go to the local host.
+	// The virtual node from the job configuration takes precedence.
+	VirtualNodeProvider interface {
+		VirtualNode() *VirtualNode
+	}
+)
+
+// vnodeNamespace is the namespace of the GUIDs derived from hostnames.
+var vnodeNamespace = uuid.MustParse("6fc56a4e-6e3f-4ab8-8d6b-5f4c8e1d2a90")
+
+// normalize validates the virtual node and sets the GUID if it is not set.
+func (v *VirtualNode) normalize() error {
+	if v.Hostname == "" {
+		return errors.New("virtual node: empty hostname")
+	}
+	if v.GUID == "" {
+		v.GUID = v.guid()
+		return nil
+	}
+	id, err := uuid.Parse(v.GUID)
+	if err != nil {
+		return fmt.Errorf("virtual node: invalid guid '%s': %v", v.GUID, err)
+	}
+	v.GUID = id.String()
+	return nil
+}
+
+// guid returns the GUID derived from the hostname.
+func (v VirtualNode) guid() string {
+	return uuid.NewSHA1(vnodeNamespace, []byte(v.Hostname)).String()
+}
+
+func (v *VirtualNode) sortedLabels() []string {
+	keys := make([]string, 0, len(v.Labels))
+	for k := range v.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setupVirtualNode sets the job virtual node from the config or the module.
+func (j *Job) setupVirtualNode() error {
+	if j.vnode == nil {
+		p, ok := j.module.(VirtualNodeProvider)
+		if !ok {
+			return nil
+		}
+		vnode := p.VirtualNode()
+		if vnode == nil {
+			return nil
+		}
+		cp := *vnode
+		j.vnode = &cp
+	}
+	if err := j.vnode.normalize(); err != nil {
+		return err
+	}
+	j.Infof("charts go to the virtual node '%s' (guid %s)", j.vnode.Hostname, j.vnode.GUID)
+	return nil
+}
+
+// switchToVirtualNode makes the following commands apply to the job virtual node, it defines the node first.
+// It returns false if the job has no virtual node.
+func (j *Job) switchToVirtualNode() bool {
+	if j.vnode == nil {
+		return false
+	}
+	if !j.vnodeCreated {
+		j.vnodeCreated = true
+		_ = j.api.HOSTDEFINE(j.vnode.GUID, j.vnode.Hostname)
+		for _, k := range j.vnode.sortedLabels() {
+			_ = j.api.HOSTLABEL(k, j.vnode.Labels[k])
+		}
+		_ = j.api.HOSTDEFINEEND()
+	}
+	_ = j.api.HOST(j.vnode.GUID)
+	return true
+}
+
+// switchToLocalNode makes the following commands apply to the local host.
+func (j *Job) switchToLocalNode() {
+	_ = j.api.HOST("")
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockVnodeModule struct {
+	MockModule
+	vnode *VirtualNode
+}
+
+func (m *mockVnodeModule) VirtualNode() *VirtualNode { return m.vnode }
+
+func TestVirtualNode_normalize(t *testing.T) {
+	tests := map[string]struct {
+		vnode    VirtualNode
+		wantGUID string
+		wantErr  bool
+	}{
+		"guid set": {
+			vnode:    VirtualNode{GUID: "A1B2C3D4-0000-4000-8000-000000000001", Hostname: "host"},
+			wantGUID: "a1b2c3d4-0000-4000-8000-000000000001",
+		},
+		"guid derived from hostname": {
+			vnode:    VirtualNode{Hostname: "host"},
+			wantGUID: VirtualNode{Hostname: "host"}.guid(),
+		},
+		"invalid guid": {
+			vnode:   VirtualNode{GUID: "not-a-guid", Hostname: "host"},
+			wantErr: true,
+		},
+		"no hostname": {
+			vnode:   VirtualNode{GUID: "a1b2c3d4-0000-4000-8000-000000000001"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.vnode.normalize()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantGUID, test.vnode.GUID)
+			}
+		})
+	}
+
+	one, two := VirtualNode{Hostname: "host1"}, VirtualNode{Hostname: "host2"}
+	assert.NotEqual(t, one.guid(), two.guid())
+}
+
+func TestJob_VirtualNode(t *testing.T) {
+	tests := map[string]struct {
+		cfgVnode    *VirtualNode
+		moduleVnode *VirtualNode
+		wantHost    string
+	}{
+		"no vnode": {},
+		"from config": {
+			cfgVnode:    &VirtualNode{Hostname: "config", Labels: map[string]string{"b": "2", "a": "1"}},
+			moduleVnode: &VirtualNode{Hostname: "module"},
+			wantHost:    "config",
+		},
+		"from module": {
+			moduleVnode: &VirtualNode{Hostname: "module", Labels: map[string]string{"b": "2", "a": "1"}},
+			wantHost:    "module",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			mod := &mockVnodeModule{
+				MockModule: MockModule{
+					ChartsFunc: func() *Charts {
+						return &Charts{{ID: "chart", Title: "title", Units: "units", Dims: Dims{{ID: "dim"}}}}
+					},
+					CollectFunc: func() map[string]int64 { return map[string]int64{"dim": 1} },
+				},
+				vnode: test.moduleVnode,
+			}
+			job := NewJob(JobConfig{
+				PluginName: pluginName,
+				Name:       jobName,
+				ModuleName: modName,
+				FullName:   modName + "_" + jobName,
+				Module:     mod,
+				Out:        &out,
+				Vnode:      test.cfgVnode,
+			})
+
+			require.True(t, job.AutoDetection())
+			job.runOnce()
+			job.runOnce()
+			job.Cleanup()
+
+			if test.wantHost == "" {
+				assert.NotContains(t, out.String(), "HOST")
+				return
+			}
+
+			guid := VirtualNode{Hostname: test.wantHost}.guid()
+			s := out.String()
+			assert.Equal(t, 1, strings.Count(s, "HOST_DEFINE '"))
+			assert.Contains(t, s, "HOST_DEFINE '"+guid+"' '"+test.wantHost+"'\nHOST_LABEL 'a' '1'\nHOST_LABEL 'b' '2'\nHOST_DEFINE_END\n")
+			// create and update, update, obsolete
+			assert.Equal(t, 3, strings.Count(s, "HOST '"+guid+"'"))
+			assert.Equal(t, 3, strings.Count(s, "HOST ''"))
+
+			// the job charts are sent to the virtual node, the execution time chart to the local host
+			chart := strings.Index(s, "CHART 'module_job.chart'")
+			assert.Less(t, strings.Index(s, "HOST '"+guid+"'"), chart)
+			assert.Less(t, chart, strings.Index(s, "HOST ''"))
+			assert.Less(t, strings.Index(s, "HOST ''"), strings.Index(s, "BEGIN 'netdata.execution_time_of_module_job'"))
+		})
+	}
+}
+
+func TestJob_VirtualNode_InvalidConfig(t *testing.T) {
+	job := NewJob(JobConfig{
+		PluginName: pluginName,
+		Name:       jobName,
+		ModuleName: modName,
+		FullName:   modName + "_" + jobName,
+		Module:     &MockModule{ChartsFunc: func() *Charts { return &Charts{} }},
+		Out:        &bytes.Buffer{},
+		Vnode:      &VirtualNode{GUID: "invalid", Hostname: "host"},
+	})
+
+	assert.False(t, job.AutoDetection())
+}
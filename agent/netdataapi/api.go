@@ -3,13 +3,18 @@
 package netdataapi
 
 import (
-	"fmt"
 	"io"
+	"math"
+	"strconv"
+	"sync"
 )
 
 type (
 	// API implements Netdata external plugins API.
 	// https://learn.netdata.cloud/docs/agent/collectors/plugins.d#the-output-of-the-plugin
+	//
+	// Every method writes one or more complete lines with a single Write call.
+	// The lines are formatted in reusable buffers, API is safe for concurrent use if the writer is.
 	API struct {
 		io.Writer
 	}
@@ -17,6 +22,43 @@ type (
 
 func New(w io.Writer) *API { return &API{w} }
 
+var bufPool = sync.Pool{New: func() interface{} { b := make([]byte, 0, 256); return &b }}
+
+// write formats the line(s) in a pooled buffer and writes it.
+func (a *API) write(format func(b []byte) []byte) error {
+	bp := bufPool.Get().(*[]byte)
+	b := format((*bp)[:0])
+	_, err := a.Write(b)
+	if cap(b) <= 64*1024 {
+		*bp = b
+		bufPool.Put(bp)
+	}
+	return err
+}
+
+// quote appends the value in single quotes.
+func quote(b []byte, v string) []byte {
+	b = append(b, '\'')
+	b = append(b, v...)
+	return append(b, '\'')
+}
+
+// quoteInt appends the value in single quotes.
+func quoteInt(b []byte, v int64) []byte {
+	b = append(b, '\'')
+	b = strconv.AppendInt(b, v, 10)
+	return append(b, '\'')
+}
+
+// appendTypeID appends the quoted chart 'type.id'.
+func appendTypeID(b []byte, typeID, ID string) []byte {
+	b = append(b, '\'')
+	b = append(b, typeID...)
+	b = append(b, '.')
+	b = append(b, ID...)
+	return append(b, '\'')
+}
+
 // CHART  creates or update a chart.
 func (a *API) CHART(
 	typeID string,
@@ -32,9 +74,19 @@ func (a *API) CHART(
 	options string,
 	plugin string,
 	module string) error {
-	_, err := fmt.Fprintf(a, "CHART '%s.%s' '%s' '%s' '%s' '%s' '%s' '%s' '%d' '%d' '%s' '%s' '%s'\n",
-		typeID, ID, name, title, units, family, context, chartType, priority, updateEvery, options, plugin, module)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "CHART "...)
+		b = appendTypeID(b, typeID, ID)
+		for _, v := range []string{name, title, units, family, context, chartType} {
+			b = quote(append(b, ' '), v)
+		}
+		b = quoteInt(append(b, ' '), int64(priority))
+		b = quoteInt(append(b, ' '), int64(updateEvery))
+		for _, v := range []string{options, plugin, module} {
+			b = quote(append(b, ' '), v)
+		}
+		return append(b, '\n')
+	})
 }
 
 // DIMENSION adds or update a dimension to the chart just created.
@@ -45,71 +97,211 @@ func (a *API) DIMENSION(
 	multiplier int,
 	divisor int,
 	options string) error {
-	_, err := fmt.Fprintf(a, "DIMENSION '%s' '%s' '%s' '%d' '%d' '%s'\n",
-		ID, name, algorithm, multiplier, divisor, options)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "DIMENSION "...)
+		b = quote(b, ID)
+		b = quote(append(b, ' '), name)
+		b = quote(append(b, ' '), algorithm)
+		b = quoteInt(append(b, ' '), int64(multiplier))
+		b = quoteInt(append(b, ' '), int64(divisor))
+		b = quote(append(b, ' '), options)
+		return append(b, '\n')
+	})
 }
 
 // CLABEL adds or update a label to the chart.
 func (a *API) CLABEL(key, value string, source int) error {
-	_, err := fmt.Fprintf(a, "CLABEL '%s' '%s' '%d'\n", key, value, source)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "CLABEL "...)
+		b = quote(b, key)
+		b = quote(append(b, ' '), value)
+		b = quoteInt(append(b, ' '), int64(source))
+		return append(b, '\n')
+	})
 }
 
 // CLABELCOMMIT adds labels to the chart. Should be called after one or more CLABEL.
 func (a *API) CLABELCOMMIT() error {
-	_, err := fmt.Fprint(a, "CLABEL_COMMIT\n")
-	return err
+	return a.writeString("CLABEL_COMMIT\n")
 }
 
 // BEGIN initializes data collection for a chart.
 func (a *API) BEGIN(typeID string, ID string, msSince int) (err error) {
-	if msSince > 0 {
-		_, err = fmt.Fprintf(a, "BEGIN '%s.%s' %d\n", typeID, ID, msSince)
-	} else {
-		_, err = fmt.Fprintf(a, "BEGIN '%s.%s'\n", typeID, ID)
-	}
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "BEGIN "...)
+		b = appendTypeID(b, typeID, ID)
+		if msSince > 0 {
+			b = strconv.AppendInt(append(b, ' '), int64(msSince), 10)
+		}
+		return append(b, '\n')
+	})
 }
 
 // SET sets the value of a dimension for the initialized chart.
 func (a *API) SET(ID string, value int64) error {
-	_, err := fmt.Fprintf(a, "SET '%s' = %d\n", ID, value)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "SET "...)
+		b = quote(b, ID)
+		b = append(b, " = "...)
+		b = strconv.AppendInt(b, value, 10)
+		return append(b, '\n')
+	})
 }
 
 // SETEMPTY sets the empty value of a dimension for the initialized chart.
 func (a *API) SETEMPTY(ID string) error {
-	_, err := fmt.Fprintf(a, "SET '%s' = \n", ID)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "SET "...)
+		b = quote(b, ID)
+		return append(b, " = \n"...)
+	})
 }
 
 // VARIABLE sets the value of a CHART scope variable for the initialized chart.
 func (a *API) VARIABLE(ID string, value int64) error {
-	_, err := fmt.Fprintf(a, "VARIABLE CHART '%s' = %d\n", ID, value)
-	return err
+	return a.write(func(b []byte) []byte {
+		b = append(b, "VARIABLE CHART "...)
+		b = quote(b, ID)
+		b = append(b, " = "...)
+		b = strconv.AppendInt(b, value, 10)
+		return append(b, '\n')
+	})
 }
 
 // END completes data collection for the initialized chart.
 func (a *API) END() error {
-	_, err := fmt.Fprintf(a, "END\n\n")
-	return err
+	return a.writeString("END\n\n")
+}
+
+// BEGIN2 initializes data collection for a chart with explicit timestamps (protocol v2).
+// updateEvery is the chart data collection interval in seconds, endTime is the collection time
+// and wallClockTime is the current time, both are unix timestamps in seconds.
+func (a *API) BEGIN2(typeID string, ID string, updateEvery int, endTime, wallClockTime int64) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "BEGIN2 "...)
+		b = appendTypeID(b, typeID, ID)
+		b = strconv.AppendInt(append(b, ' '), int64(updateEvery), 10)
+		b = strconv.AppendInt(append(b, ' '), endTime, 10)
+		b = strconv.AppendInt(append(b, ' '), wallClockTime, 10)
+		return append(b, '\n')
+	})
+}
+
+// SET2 sets the collected and the stored values of a dimension for the chart initialized by BEGIN2.
+// A NaN stored value is an empty value. flags are the storage flags, usually empty.
+func (a *API) SET2(ID string, collected int64, stored float64, flags string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "SET2 "...)
+		b = quote(b, ID)
+		b = strconv.AppendInt(append(b, ' '), collected, 10)
+		b = append(b, ' ')
+		if math.IsNaN(stored) || math.IsInf(stored, 0) {
+			b = append(b, "NAN"...)
+		} else {
+			b = strconv.AppendFloat(b, stored, 'f', -1, 64)
+		}
+		b = quote(append(b, ' '), flags)
+		return append(b, '\n')
+	})
+}
+
+// END2 completes data collection for the chart initialized by BEGIN2.
+func (a *API) END2() error {
+	return a.writeString("END2\n")
+}
+
+// HOSTDEFINE starts a virtual host definition. Should be followed by zero or more HOSTLABEL and HOSTDEFINEEND.
+func (a *API) HOSTDEFINE(guid, hostname string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "HOST_DEFINE "...)
+		b = quote(b, guid)
+		b = quote(append(b, ' '), hostname)
+		return append(b, '\n')
+	})
+}
+
+// HOSTLABEL adds a label to the virtual host being defined.
+func (a *API) HOSTLABEL(key, value string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "HOST_LABEL "...)
+		b = quote(b, key)
+		b = quote(append(b, ' '), value)
+		return append(b, '\n')
+	})
+}
+
+// HOSTDEFINEEND completes the virtual host definition.
+func (a *API) HOSTDEFINEEND() error {
+	return a.writeString("HOST_DEFINE_END\n\n")
+}
+
+// HOST switches the host the following commands apply to. An empty guid switches back to the local host.
+func (a *API) HOST(guid string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "HOST "...)
+		b = quote(b, guid)
+		return append(b, "\n\n"...)
+	})
+}
+
+// FUNCTIONGLOBAL registers a host function that Netdata can call.
+// timeout is the function execution timeout in seconds.
+func (a *API) FUNCTIONGLOBAL(name string, timeout int, help string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "FUNCTION GLOBAL "...)
+		b = quote(b, name)
+		b = strconv.AppendInt(append(b, ' '), int64(timeout), 10)
+		b = quote(append(b, ' '), help)
+		return append(b, '\n')
+	})
+}
+
+// FUNCTION registers a function of the chart just created that Netdata can call.
+// timeout is the function execution timeout in seconds.
+func (a *API) FUNCTION(name string, timeout int, help string) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "FUNCTION "...)
+		b = quote(b, name)
+		b = strconv.AppendInt(append(b, ' '), int64(timeout), 10)
+		b = quote(append(b, ' '), help)
+		return append(b, '\n')
+	})
+}
+
+// FUNCTIONRESULT replies to the function call with the transaction uid.
+// status is an HTTP status code, expires is the result expiration unix timestamp in seconds.
+func (a *API) FUNCTIONRESULT(uid string, status int, contentType string, expires int64, payload []byte) error {
+	return a.write(func(b []byte) []byte {
+		b = append(b, "FUNCTION_RESULT_BEGIN "...)
+		b = append(b, uid...)
+		b = strconv.AppendInt(append(b, ' '), int64(status), 10)
+		b = quote(append(b, ' '), contentType)
+		b = strconv.AppendInt(append(b, ' '), expires, 10)
+		b = append(b, '\n')
+		b = append(b, payload...)
+		if len(payload) > 0 && payload[len(payload)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		return append(b, "FUNCTION_RESULT_END\n\n"...)
+	})
 }
 
 // FLUSH ignores the last collected values.
 func (a *API) FLUSH() error {
-	_, err := fmt.Fprintf(a, "FLUSH\n")
-	return err
+	return a.writeString("FLUSH\n")
 }
 
 // DISABLE disables this plugin. This will prevent Netdata from restarting the plugin.
 func (a *API) DISABLE() error {
-	_, err := fmt.Fprintf(a, "DISABLE\n")
-	return err
+	return a.writeString("DISABLE\n")
 }
 
 // EMPTYLINE writes an empty line.
 func (a *API) EMPTYLINE() error {
-	_, err := fmt.Fprintf(a, "\n")
+	return a.writeString("\n")
+}
+
+func (a *API) writeString(s string) error {
+	_, err := io.WriteString(a, s)
 	return err
 }
@@ -4,6 +4,8 @@ package netdataapi
 
 import (
 	"bytes"
+	"io"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -151,3 +153,101 @@ func TestAPI_FLUSH(t *testing.T) {
 		b.String(),
 	)
 }
+
+func TestAPI_BEGIN2(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.BEGIN2("typeID", "id", 1, 1683000000, 1683000001)
+
+	assert.Equal(t, "BEGIN2 'typeID.id' 1 1683000000 1683000001\n", b.String())
+}
+
+func TestAPI_SET2(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.SET2("id", 100, 0.5, "")
+	_ = netdataAPI.SET2("id", 100, math.NaN(), "E")
+
+	assert.Equal(t, "SET2 'id' 100 0.5 ''\nSET2 'id' 100 NAN 'E'\n", b.String())
+}
+
+func TestAPI_END2(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.END2()
+
+	assert.Equal(t, "END2\n", b.String())
+}
+
+func TestAPI_HOSTDEFINE(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.HOSTDEFINE("guid", "hostname")
+	_ = netdataAPI.HOSTLABEL("key", "value")
+	_ = netdataAPI.HOSTDEFINEEND()
+
+	assert.Equal(
+		t,
+		"HOST_DEFINE 'guid' 'hostname'\nHOST_LABEL 'key' 'value'\nHOST_DEFINE_END\n\n",
+		b.String(),
+	)
+}
+
+func TestAPI_HOST(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.HOST("guid")
+	_ = netdataAPI.HOST("")
+
+	assert.Equal(t, "HOST 'guid'\n\nHOST ''\n\n", b.String())
+}
+
+func TestAPI_FUNCTION(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.FUNCTIONGLOBAL("name", 10, "help")
+	_ = netdataAPI.FUNCTION("name", 10, "help")
+
+	assert.Equal(t, "FUNCTION GLOBAL 'name' 10 'help'\nFUNCTION 'name' 10 'help'\n", b.String())
+}
+
+func TestAPI_FUNCTIONRESULT(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.FUNCTIONRESULT("uid", 200, "application/json", 1683000000, []byte(`{"status":200}`))
+
+	assert.Equal(
+		t,
+		"FUNCTION_RESULT_BEGIN uid 200 'application/json' 1683000000\n{\"status\":200}\nFUNCTION_RESULT_END\n\n",
+		b.String(),
+	)
+
+	b.Reset()
+	_ = netdataAPI.FUNCTIONRESULT("uid", 404, "text/plain", 0, []byte("not found\n"))
+
+	assert.Equal(
+		t,
+		"FUNCTION_RESULT_BEGIN uid 404 'text/plain' 0\nnot found\nFUNCTION_RESULT_END\n\n",
+		b.String(),
+	)
+}
+
+func BenchmarkAPI(b *testing.B) {
+	netdataAPI := New(io.Discard)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = netdataAPI.CHART("type", "id", "", "title", "units", "family", "context", "line", 1, 1, "", "plugin", "module")
+		_ = netdataAPI.DIMENSION("id", "name", "absolute", 1, 1, "")
+		_ = netdataAPI.BEGIN("type", "id", 1000)
+		_ = netdataAPI.SET("id", int64(i))
+		_ = netdataAPI.END()
+	}
+}
@@ -99,6 +99,8 @@ func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 			UpdateEvery:        creator.UpdateEvery,
 			AutoDetectionRetry: creator.AutoDetectionRetry,
 			Priority:           creator.Priority,
+			MaxCharts:          creator.MaxCharts,
+			MaxDims:            creator.MaxDims,
 		})
 	}
 
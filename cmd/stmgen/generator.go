@@ -0,0 +1,377 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	stmImportPath = "github.com/netdata/go.d.plugin/pkg/stm"
+	fieldTagName  = "stm"
+	structKey     = "STMKey"
+)
+
+var (
+	intKinds   = map[string]bool{"int": true, "int8": true, "int16": true, "int32": true, "int64": true}
+	floatKinds = map[string]bool{"float32": true, "float64": true}
+)
+
+type generator struct {
+	pkg string
+	// types are all the package type declarations.
+	types map[string]ast.Expr
+	// values are the package types with the stm.Value WriteTo method.
+	values map[string]bool
+	// gen are the types to generate methods for.
+	gen map[string]bool
+
+	usesStm bool
+}
+
+// generate returns the formatted source of WriteTo methods for the struct types declared in the package in dir.
+// The output file is excluded from parsing, so it can be regenerated.
+func generate(dir string, typeNames []string, output string) ([]byte, error) {
+	g, err := newGenerator(dir, output)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range typeNames {
+		g.gen[strings.TrimSpace(name)] = true
+	}
+
+	var body bytes.Buffer
+	for _, name := range typeNames {
+		if err := g.genType(&body, strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// SPDX-License-Identifier: GPL-3.0-or-later\n\n")
+	buf.WriteString("// Code generated by stmgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg)
+	if g.usesStm {
+		fmt.Fprintf(&buf, "import %q\n", stmImportPath)
+	}
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v", err)
+	}
+	return src, nil
+}
+
+func newGenerator(dir, output string) (*generator, error) {
+	fset := token.NewFileSet()
+	filter := func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected 1 package in '%s', found %d", dir, len(pkgs))
+	}
+
+	g := &generator{
+		types:  make(map[string]ast.Expr),
+		values: make(map[string]bool),
+		gen:    make(map[string]bool),
+	}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		for _, file := range pkg.Files {
+			g.collect(file)
+		}
+	}
+	return g, nil
+}
+
+func (g *generator) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.TypeParams == nil {
+					g.types[ts.Name.Name] = ts.Type
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || decl.Name.Name != "WriteTo" || decl.Type.Params.NumFields() != 4 {
+				continue
+			}
+			if name := typeName(decl.Recv.List[0].Type); name != "" {
+				g.values[name] = true
+			}
+		}
+	}
+}
+
+func (g *generator) genType(w *bytes.Buffer, name string) error {
+	st, ok := g.types[name].(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("type '%s': not found or not a struct", name)
+	}
+
+	fw := &fieldWriter{g: g}
+	if err := fw.structFields(st, "s", "", 1, true, map[string]bool{name: true}); err != nil {
+		return fmt.Errorf("type '%s': %v", name, err)
+	}
+
+	fmt.Fprintf(w, "\n// WriteTo writes the 'stm' tagged fields of %s into rv, it is the generated equivalent of stm.ToMap.\n", name)
+	fmt.Fprintf(w, "func (s *%s) WriteTo(rv map[string]int64) {\n\ts.stmWriteTo(rv, \"\")\n}\n", name)
+	fmt.Fprintf(w, "\nfunc (s *%s) stmWriteTo(rv map[string]int64, key string) {\n", name)
+	if hasStructKey(st) {
+		g.usesStm = true
+		fmt.Fprintf(w, "\tkey = stm.JoinKey(key, s.%s)\n", structKey)
+	}
+	if fw.usesPrefix {
+		w.WriteString("\tp := key\n\tif p != \"\" {\n\t\tp += \"_\"\n\t}\n")
+	}
+	w.Write(fw.buf.Bytes())
+	w.WriteString("}\n")
+	return nil
+}
+
+type fieldWriter struct {
+	g          *generator
+	buf        bytes.Buffer
+	usesPrefix bool
+}
+
+func (w *fieldWriter) line(indent int, format string, args ...interface{}) {
+	w.buf.WriteString(strings.Repeat("\t", indent))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteByte('\n')
+}
+
+// key returns the key expression, it is the same as stm joinPrefix(key, path).
+func (w *fieldWriter) key(path string) string {
+	if path == "" {
+		return "key"
+	}
+	w.usesPrefix = true
+	return "p + " + strconv.Quote(path)
+}
+
+func (w *fieldWriter) structFields(st *ast.StructType, expr, path string, indent int, exported bool, seen map[string]bool) error {
+	expr = selectable(expr)
+	for _, f := range st.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tagValue, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return err
+		}
+		tag, ok := reflect.StructTag(tagValue).Lookup(fieldTagName)
+		if !ok {
+			continue
+		}
+		prefix, mul, div, err := parseTag(tag)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, typeName(f.Type))
+		}
+		for _, name := range names {
+			if name == structKey {
+				continue
+			}
+			if name == "" || name == "_" {
+				return errors.New("tagged field without accessible name")
+			}
+			err := w.field(f.Type, expr+"."+name, joinPath(path, prefix), mul, div, indent, exported && ast.IsExported(name), seen)
+			if err != nil {
+				return fmt.Errorf("field '%s': %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *fieldWriter) field(t ast.Expr, expr, path string, mul, div, indent int, exported bool, seen map[string]bool) error {
+	switch t := t.(type) {
+	case *ast.ParenExpr:
+		return w.field(t.X, expr, path, mul, div, indent, exported, seen)
+	case *ast.Ident:
+		return w.ident(t.Name, expr, path, mul, div, indent, exported, seen)
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok && w.g.values[ident.Name] {
+			// the pointer may implement stm.Value, it is called even if nil
+			return w.fallback(expr, path, mul, div, indent, exported)
+		}
+		w.line(indent, "if %s != nil {", expr)
+		if err := w.field(t.X, "*"+expr, path, mul, div, indent+1, exported, seen); err != nil {
+			return err
+		}
+		w.line(indent, "}")
+		return nil
+	case *ast.StructType:
+		if hasStructKey(t) {
+			return w.fallback(expr, path, mul, div, indent, exported)
+		}
+		return w.structFields(t, expr, path, indent, exported, seen)
+	default:
+		return w.fallback(expr, path, mul, div, indent, exported)
+	}
+}
+
+func (w *fieldWriter) ident(name, expr, path string, mul, div, indent int, exported bool, seen map[string]bool) error {
+	switch {
+	case intKinds[name]:
+		w.line(indent, "rv[%s] = int64(%s)%s", w.key(path), expr, mulDiv(mul, div))
+		return nil
+	case floatKinds[name]:
+		w.line(indent, "rv[%s] = int64(float64(%s)%s)", w.key(path), expr, mulDiv(mul, div))
+		return nil
+	case name == "bool":
+		k := w.key(path)
+		w.line(indent, "if %s {", expr)
+		w.line(indent+1, "rv[%s] = 1", k)
+		w.line(indent, "} else {")
+		w.line(indent+1, "rv[%s] = 0", k)
+		w.line(indent, "}")
+		return nil
+	}
+
+	underlying, ok := w.g.types[name]
+	if _, isStruct := underlying.(*ast.StructType); isStruct && w.g.gen[name] {
+		w.line(indent, "%s.stmWriteTo(rv, %s)", selectable(expr), w.key(path))
+		return nil
+	}
+	if !ok || w.g.values[name] || seen[name] {
+		return w.fallback(expr, path, mul, div, indent, exported)
+	}
+
+	if ident, ok := underlying.(*ast.Ident); ok && !intKinds[ident.Name] && !floatKinds[ident.Name] && ident.Name != "bool" {
+		// a type defined by another named type doesn't have its methods
+		return w.fallback(expr, path, mul, div, indent, exported)
+	}
+
+	nested := make(map[string]bool, len(seen)+1)
+	for k := range seen {
+		nested[k] = true
+	}
+	nested[name] = true
+	return w.field(underlying, expr, path, mul, div, indent, exported, nested)
+}
+
+// fallback writes the value using the reflection based conversion.
+func (w *fieldWriter) fallback(expr, path string, mul, div, indent int, exported bool) error {
+	if !exported {
+		// reflection can't call stm.Value methods of unexported fields, the generated code would behave differently
+		return errors.New("unexported field type needs the reflection based conversion, export the field or change its type")
+	}
+	w.g.usesStm = true
+	w.line(indent, "stm.WriteValue(rv, %s, %s, %d, %d)", expr, w.key(path), mul, div)
+	return nil
+}
+
+// mulDiv returns the multiplication and division expression, x*1 and x/1 are omitted.
+func mulDiv(mul, div int) string {
+	var s string
+	if mul != 1 {
+		s += fmt.Sprintf(" * %d", mul)
+	}
+	if div != 1 {
+		s += fmt.Sprintf(" / %d", div)
+	}
+	return s
+}
+
+// selectable returns the expression that can be used to select a field or call a method,
+// one pointer indirection is done automatically.
+func selectable(expr string) string {
+	expr = strings.TrimPrefix(expr, "*")
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+func hasStructKey(st *ast.StructType) bool {
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if n.Name != structKey {
+				continue
+			}
+			if ident, ok := f.Type.(*ast.Ident); ok && ident.Name == "string" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns the type name of an embedded field or a method receiver.
+func typeName(t ast.Expr) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.ParenExpr:
+		return typeName(t.X)
+	}
+	return ""
+}
+
+// joinPath is the same as stm joinPrefix.
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return prefix + "_" + key
+}
+
+// parseTag is the same as stm parseTag, but returns an error instead of panicking.
+func parseTag(tag string) (prefix string, mul int, div int, err error) {
+	tokens := strings.Split(tag, ",")
+	mul, div = 1, 1
+	switch len(tokens) {
+	case 3:
+		if div, err = strconv.Atoi(tokens[2]); err != nil {
+			return "", 0, 0, err
+		}
+		fallthrough
+	case 2:
+		if mul, err = strconv.Atoi(tokens[1]); err != nil {
+			return "", 0, 0, err
+		}
+		fallthrough
+	case 1:
+		prefix = tokens[0]
+	default:
+		return "", 0, 0, fmt.Errorf("invalid tag format: %s", tag)
+	}
+	return prefix, mul, div, nil
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_SampleIsUpToDate(t *testing.T) {
+	src, err := generate("internal/sample", []string{"Stats", "Node"}, "stm_gen.go")
+	require.NoError(t, err)
+
+	existing, err := os.ReadFile("internal/sample/stm_gen.go")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(existing), string(src), "run 'go generate ./cmd/stmgen/...'")
+}
+
+func TestGenerate(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		types   []string
+		wantErr bool
+	}{
+		"only basic types": {
+			src:   "type T struct {\n A int64 `stm:\"a\"`\n B bool `stm:\"b\"`\n}",
+			types: []string{"T"},
+		},
+		"type not found": {
+			src:     "type T struct{}",
+			types:   []string{"U"},
+			wantErr: true,
+		},
+		"not a struct": {
+			src:     "type T int64",
+			types:   []string{"T"},
+			wantErr: true,
+		},
+		"invalid tag": {
+			src:     "type T struct {\n A int64 `stm:\"a,b\"`\n}",
+			types:   []string{"T"},
+			wantErr: true,
+		},
+		"unexported field needs reflection": {
+			src:     "type T struct {\n m map[string]int64 `stm:\"m\"`\n}",
+			types:   []string{"T"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package p\n\n"+test.src+"\n"), 0644)
+			require.NoError(t, err)
+
+			src, err := generate(dir, test.types, "stm_gen.go")
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotContains(t, string(src), "pkg/stm")
+				assert.Contains(t, string(src), "func (s *T) WriteTo(rv map[string]int64)")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package sample
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/pkg/metrics"
+	"github.com/netdata/go.d.plugin/pkg/stm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_WriteTo(t *testing.T) {
+	tests := map[string]func() *Stats{
+		"zero values": func() *Stats {
+			// the reflection based conversion panics on nil maps and interfaces
+			return &Stats{Counters: map[string]int64{}, Any: 0, Summary: metrics.NewSummary()}
+		},
+		"populated": prepareStats,
+	}
+
+	for name, prepare := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := prepare()
+			expected := reflectToMap(s)
+			require.NotEmpty(t, expected)
+
+			mx := make(map[string]int64)
+			s.WriteTo(mx)
+
+			assert.Equal(t, expected, mx)
+			assert.Equal(t, expected, stm.ToMap(s), "pointer")
+			assert.Equal(t, expected, stm.ToMap(*s), "value")
+		})
+	}
+}
+
+func TestNode_WriteTo(t *testing.T) {
+	n := &Node{STMKey: "node1", Up: true, Load: 3, Parent: &Node{STMKey: "root", Load: 1}}
+	expected := reflectToMap(n)
+
+	assert.Equal(t, expected, stm.ToMap(n))
+	assert.Equal(t, map[string]int64{
+		"node1_up":               1,
+		"node1_load":             3,
+		"node1_parent_root_up":   0,
+		"node1_parent_root_load": 1,
+	}, expected)
+}
+
+func TestToMap_Variadic(t *testing.T) {
+	s := prepareStats()
+	n := &Node{STMKey: "node", Load: 5}
+
+	expected := reflectToMap(s)
+	for k, v := range reflectToMap(n) {
+		expected[k] = v
+	}
+
+	assert.Equal(t, expected, stm.ToMap(s, n))
+}
+
+func BenchmarkToMap_Reflection(b *testing.B) {
+	s := prepareStats()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		reflectToMap(s)
+	}
+}
+
+func BenchmarkToMap_Generated(b *testing.B) {
+	s := prepareStats()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stm.ToMap(s)
+	}
+}
+
+// reflectToMap uses the reflection based conversion: the generated method is used only for the top level values.
+func reflectToMap(v interface{}) map[string]int64 {
+	return stm.ToMap(struct {
+		V interface{} `stm:""`
+	}{v})
+}
+
+func prepareStats() *Stats {
+	i, f := int64(42), 3.14159
+	s := &Stats{
+		Int:      1,
+		Int8:     -2,
+		Int16:    3,
+		Int32:    4,
+		Int64:    1001,
+		Float32:  0.125,
+		Float64:  12.345,
+		Bool:     true,
+		private:  7,
+		Skipped:  8,
+		IntPtr:   &i,
+		FloatPtr: &f,
+		Nodes: []Node{
+			{STMKey: "a", Up: true, Load: 10},
+			{STMKey: "b", Load: 20},
+		},
+		First:    Node{STMKey: "c", Load: 30},
+		Second:   &Node{STMKey: "d", Up: true, Parent: &Node{STMKey: "e", Load: 40}},
+		Plain:    plain{Value: 50},
+		Counters: map[string]int64{"x": 60, "y": 70},
+		Array:    [1]int64{80},
+		Any:      map[string]float64{"z": 100.5},
+		Gauge:    110,
+		Wrapped:  wrapped{Value: 1.5},
+		Summary:  metrics.NewSummary(),
+		Embedded: Embedded{Value: 120},
+	}
+	s.Latency = &struct {
+		Value *int64 `stm:""`
+	}{Value: &i}
+	s.Inline.A = 130
+	s.Inline.B.C = 14.5
+	s.Counter.Add(150)
+	s.Summary.Observe(1.5)
+	s.Summary.Observe(2.5)
+	return s
+}
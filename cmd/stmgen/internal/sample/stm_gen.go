@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Code generated by stmgen. DO NOT EDIT.
+
+package sample
+
+import "github.com/netdata/go.d.plugin/pkg/stm"
+
+// WriteTo writes the 'stm' tagged fields of Stats into rv, it is the generated equivalent of stm.ToMap.
+func (s *Stats) WriteTo(rv map[string]int64) {
+	s.stmWriteTo(rv, "")
+}
+
+func (s *Stats) stmWriteTo(rv map[string]int64, key string) {
+	p := key
+	if p != "" {
+		p += "_"
+	}
+	rv[p+"int"] = int64(s.Int)
+	rv[p+"int8"] = int64(s.Int8)
+	rv[p+"int16"] = int64(s.Int16)
+	rv[p+"int32"] = int64(s.Int32)
+	rv[p+"int64"] = int64(s.Int64) * 10 / 3
+	rv[p+"float32"] = int64(float64(s.Float32) * 1000)
+	rv[p+"float64"] = int64(float64(s.Float64) * 100 / 7)
+	if s.Bool {
+		rv[p+"bool"] = 1
+	} else {
+		rv[p+"bool"] = 0
+	}
+	rv[p+"private"] = int64(s.private)
+	if s.IntPtr != nil {
+		rv[p+"int_ptr"] = int64(*s.IntPtr)
+	}
+	if s.FloatPtr != nil {
+		rv[p+"float_ptr"] = int64(float64(*s.FloatPtr) * 1000)
+	}
+	if s.NilPtr != nil {
+		rv[p+"nil_ptr"] = int64(*s.NilPtr)
+	}
+	if s.Latency != nil {
+		if s.Latency.Value != nil {
+			rv[p+"latency"] = int64(*s.Latency.Value)
+		}
+	}
+	rv[p+"inline_a"] = int64(s.Inline.A)
+	rv[p+"inline_b_c"] = int64(float64(s.Inline.B.C) * 10)
+	stm.WriteValue(rv, s.Nodes, p+"nodes", 1, 1)
+	s.First.stmWriteTo(rv, p+"first")
+	if s.Second != nil {
+		s.Second.stmWriteTo(rv, p+"second")
+	}
+	rv[p+"plain_value"] = int64(s.Plain.Value)
+	stm.WriteValue(rv, s.Counters, p+"counters", 1, 1)
+	stm.WriteValue(rv, s.Array, p+"array", 1, 1)
+	stm.WriteValue(rv, s.Any, p+"any", 1, 1)
+	rv[p+"gauge"] = int64(s.Gauge)
+	rv[p+"wrapped"] = int64(float64(s.Wrapped.Value) * 100)
+	stm.WriteValue(rv, s.Counter, p+"counter", 1, 1)
+	stm.WriteValue(rv, s.Summary, p+"summary", 1000, 1)
+	rv[p+"embedded_value"] = int64(s.Embedded.Value)
+}
+
+// WriteTo writes the 'stm' tagged fields of Node into rv, it is the generated equivalent of stm.ToMap.
+func (s *Node) WriteTo(rv map[string]int64) {
+	s.stmWriteTo(rv, "")
+}
+
+func (s *Node) stmWriteTo(rv map[string]int64, key string) {
+	key = stm.JoinKey(key, s.STMKey)
+	p := key
+	if p != "" {
+		p += "_"
+	}
+	if s.Up {
+		rv[p+"up"] = 1
+	} else {
+		rv[p+"up"] = 0
+	}
+	rv[p+"load"] = int64(s.Load)
+	if s.Parent != nil {
+		s.Parent.stmWriteTo(rv, p+"parent")
+	}
+}
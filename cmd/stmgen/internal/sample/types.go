@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package sample has the types to test stmgen generated code against the reflection based stm conversion.
+package sample
+
+import "github.com/netdata/go.d.plugin/pkg/metrics"
+
+//go:generate go run ../.. -type=Stats,Node
+
+type (
+	Stats struct {
+		Int     int     `stm:"int"`
+		Int8    int8    `stm:"int8"`
+		Int16   int16   `stm:"int16"`
+		Int32   int32   `stm:"int32"`
+		Int64   int64   `stm:"int64,10,3"`
+		Float32 float32 `stm:"float32,1000"`
+		Float64 float64 `stm:"float64,100,7"`
+		Bool    bool    `stm:"bool"`
+		private int64   `stm:"private"`
+		Skipped int64
+
+		IntPtr   *int64   `stm:"int_ptr"`
+		FloatPtr *float64 `stm:"float_ptr,1000"`
+		NilPtr   *int64   `stm:"nil_ptr"`
+
+		Latency *struct {
+			Value *int64 `stm:""`
+		} `stm:"latency"`
+		Inline struct {
+			A int64 `stm:"a"`
+			B struct {
+				C float64 `stm:"c,10"`
+			} `stm:"b"`
+		} `stm:"inline"`
+
+		Nodes    []Node           `stm:"nodes"`
+		First    Node             `stm:"first"`
+		Second   *Node            `stm:"second"`
+		Plain    plain            `stm:"plain"`
+		Counters map[string]int64 `stm:"counters"`
+		Array    [1]int64         `stm:"array"`
+		Any      interface{}      `stm:"any"`
+		Gauge    gauge            `stm:"gauge"`
+		Wrapped  wrapped          `stm:""`
+
+		Counter metrics.Counter `stm:"counter"`
+		Summary metrics.Summary `stm:"summary,1000"`
+
+		Embedded `stm:"embedded"`
+	}
+	Node struct {
+		STMKey string
+		Name   string
+		Up     bool  `stm:"up"`
+		Load   int64 `stm:"load"`
+		Parent *Node `stm:"parent"`
+	}
+	plain struct {
+		Value int64 `stm:"value"`
+	}
+	Embedded struct {
+		Value int64 `stm:"value"`
+	}
+	gauge   int64
+	wrapped struct {
+		Value float64 `stm:"wrapped,100"`
+	}
+)
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Stmgen generates WriteTo(map[string]int64) methods for structs with 'stm' tags,
+// a faster alternative to the reflection based pkg/stm conversion.
+//
+// Usage (in a file of the package that declares the types):
+//
+//	//go:generate go run github.com/netdata/go.d.plugin/cmd/stmgen -type=serverStatus,nodeStats
+//
+// The generated methods write the same key-value pairs as stm.ToMap,
+// and stm.ToMap uses them when they are present.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		types  = flag.String("type", "", "comma-separated list of struct type names; required")
+		output = flag.String("output", "", "output file name; default <dir>/stm_gen.go")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: stmgen -type=T1,T2 [-output=file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, "stm_gen.go")
+	}
+
+	src, err := generate(dir, strings.Split(*types, ","), filepath.Base(out))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stmgen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "stmgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -7,16 +7,90 @@ import "github.com/netdata/go.d.plugin/agent/module"
 type (
 	// Charts is an alias for module.Charts
 	Charts = module.Charts
-	// Dim is an alias for module.Dim
-	Dim = module.Dim
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
 )
 
-var charts = Charts{
+// queryCharts are the charts of every server and record type, the placeholders are the query labels.
+var queryCharts = Charts{
 	{
-		ID:    "query_time",
+		ID:    "server_{{.server}}_{{.record_type}}_query_status",
+		Title: "DNS Query Status",
+		Units: "status",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.query_status",
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_query_status_success", Name: "success"},
+			{ID: "server_{{.server}}_{{.record_type}}_query_status_network_error", Name: "network_error"},
+			{ID: "server_{{.server}}_{{.record_type}}_query_status_dns_error", Name: "dns_error"},
+		},
+	},
+	{
+		ID:    "server_{{.server}}_{{.record_type}}_query_time",
 		Title: "DNS Query Time",
 		Units: "ms",
-		Fam:   "query time",
-		Ctx:   "dns_query_time.query_time",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.query_time",
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_query_time", Name: "query_time", Div: 1000},
+		},
+	},
+	{
+		ID:    "server_{{.server}}_{{.record_type}}_rcode",
+		Title: "DNS Response Codes",
+		Units: "responses/s",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.rcode",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_noerror", Name: "NOERROR", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_formerr", Name: "FORMERR", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_servfail", Name: "SERVFAIL", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_nxdomain", Name: "NXDOMAIN", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_notimp", Name: "NOTIMP", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_refused", Name: "REFUSED", Algo: module.Incremental},
+			{ID: "server_{{.server}}_{{.record_type}}_rcode_other", Name: "other", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "server_{{.server}}_{{.record_type}}_answer_changed",
+		Title: "DNS Answer Changed Since The Previous Query",
+		Units: "boolean",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.answer_changed",
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_answer_changed", Name: "changed"},
+		},
+	},
+}
+
+// dnssecCharts are the additional charts of the queries with the DNSSEC OK bit set.
+var dnssecCharts = Charts{
+	{
+		ID:    "server_{{.server}}_{{.record_type}}_dnssec_status",
+		Title: "DNSSEC Validation Status",
+		Units: "status",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.dnssec_status",
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_dnssec_status_secure", Name: "secure"},
+			{ID: "server_{{.server}}_{{.record_type}}_dnssec_status_insecure", Name: "insecure"},
+			{ID: "server_{{.server}}_{{.record_type}}_dnssec_status_bogus", Name: "bogus"},
+		},
+	},
+}
+
+// assertionCharts are the additional charts of the queries with expectations.
+var assertionCharts = Charts{
+	{
+		ID:    "server_{{.server}}_{{.record_type}}_answer_assertion",
+		Title: "DNS Answer Assertion Status",
+		Units: "status",
+		Fam:   "{{.server}}",
+		Ctx:   "dns_query.answer_assertion",
+		Dims: Dims{
+			{ID: "server_{{.server}}_{{.record_type}}_answer_assertion_passed", Name: "passed"},
+			{ID: "server_{{.server}}_{{.record_type}}_answer_assertion_failed", Name: "failed"},
+		},
 	},
 }
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package dnsquery
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+type server struct {
+	name    string
+	address string
+}
+
+// query is a query of a record type sent to a server.
+type query struct {
+	key      string
+	server   *server
+	rtype    uint16
+	typeName string
+
+	// the results of the last exchange, set by a worker
+	resp  *dns.Msg
+	rtt   time.Duration
+	err   error
+	bogus bool
+
+	rcodes  map[string]int64
+	answers map[string]string // domain => answer fingerprint
+}
+
+func newQuery(srv *server, rtype uint16) *query {
+	typeName := dns.TypeToString[rtype]
+	return &query{
+		key:      srv.name + "_" + typeName,
+		server:   srv,
+		rtype:    rtype,
+		typeName: typeName,
+		rcodes:   make(map[string]int64),
+		answers:  make(map[string]string),
+	}
+}
+
+var rcodes = map[int]string{
+	dns.RcodeSuccess:        "noerror",
+	dns.RcodeFormatError:    "formerr",
+	dns.RcodeServerFailure:  "servfail",
+	dns.RcodeNameError:      "nxdomain",
+	dns.RcodeNotImplemented: "notimp",
+	dns.RcodeRefused:        "refused",
+}
+
+func (d *DNSQuery) collect() map[string]int64 {
+	domain := randomDomain(d.Domains)
+	d.Debugf("current domain : %s", domain)
+
+	for _, q := range d.queries {
+		d.task <- task{query: q, domain: domain, dnssec: d.DNSSEC}
+	}
+
+	for range d.queries {
+		<-d.taskDone
+	}
+
+	mx := make(map[string]int64)
+
+	for _, q := range d.queries {
+		d.collectQuery(mx, q, domain)
+	}
+
+	return mx
+}
+
+func (d *DNSQuery) collectQuery(mx map[string]int64, q *query, domain string) {
+	px := "server_" + q.key + "_"
+
+	mx[px+"query_status_success"] = 0
+	mx[px+"query_status_network_error"] = 0
+	mx[px+"query_status_dns_error"] = 0
+
+	if q.err == nil && q.resp != nil {
+		name, ok := rcodes[q.resp.Rcode]
+		if !ok {
+			name = "other"
+		}
+		q.rcodes[name]++
+	}
+	for _, name := range rcodes {
+		mx[px+"rcode_"+name] = q.rcodes[name]
+	}
+	mx[px+"rcode_other"] = q.rcodes["other"]
+
+	if q.err != nil || q.resp == nil {
+		d.Debugf("error on querying %s after %s query for %s : %v", q.server.name, q.typeName, domain, q.err)
+		mx[px+"query_status_network_error"] = 1
+		return
+	}
+
+	mx[px+"query_time"] = q.rtt.Microseconds()
+
+	if d.DNSSEC {
+		mx[px+"dnssec_status_secure"] = 0
+		mx[px+"dnssec_status_insecure"] = 0
+		mx[px+"dnssec_status_bogus"] = 0
+		switch {
+		case q.resp.AuthenticatedData:
+			mx[px+"dnssec_status_secure"] = 1
+		case q.bogus:
+			mx[px+"dnssec_status_bogus"] = 1
+		case q.resp.Rcode == dns.RcodeSuccess || q.resp.Rcode == dns.RcodeNameError:
+			mx[px+"dnssec_status_insecure"] = 1
+		}
+	}
+
+	if q.resp.Rcode != dns.RcodeSuccess {
+		d.Errorf("invalid answer from %s after %s query for %s : %s",
+			q.server.name, q.typeName, domain, dns.RcodeToString[q.resp.Rcode])
+		mx[px+"query_status_dns_error"] = 1
+		return
+	}
+	mx[px+"query_status_success"] = 1
+
+	mx[px+"answer_changed"] = 0
+	fp := answerFingerprint(q.resp.Answer)
+	if prev, ok := q.answers[domain]; ok && prev != fp {
+		d.Debugf("%s answer from %s for %s changed", q.typeName, q.server.name, domain)
+		mx[px+"answer_changed"] = 1
+	}
+	q.answers[domain] = fp
+
+	if !d.expect.empty() {
+		mx[px+"answer_assertion_passed"] = 1
+		mx[px+"answer_assertion_failed"] = 0
+		if err := d.expect.check(q.rtype, q.resp); err != nil {
+			d.Debugf("%s answer from %s for %s assertion failed : %v", q.typeName, q.server.name, domain, err)
+			mx[px+"answer_assertion_passed"] = 0
+			mx[px+"answer_assertion_failed"] = 1
+		}
+	}
+}
+
+// answerFingerprint returns the answer records without TTLs, sorted.
+func answerFingerprint(answer []dns.RR) string {
+	records := make([]string, 0, len(answer))
+	for _, rr := range answer {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = 0
+		records = append(records, rr.String())
+	}
+	sort.Strings(records)
+	return strings.Join(records, "\n")
+}
+
+func randomDomain(domains []string) string {
+	rand.Seed(time.Now().UnixNano())
+	return domains[rand.Intn(len(domains))]
+}
@@ -3,12 +3,10 @@
 package dnsquery
 
 import (
-	"errors"
-	"fmt"
-	"math/rand"
-	"strings"
+	"crypto/tls"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/miekg/dns"
@@ -30,20 +28,21 @@ const (
 	defaultTimeout    = time.Second * 2
 	defaultNetwork    = "udp"
 	defaultRecordType = "A"
-	defaultPort       = 53
 )
 
 // New creates DNSQuery with default values
 func New() *DNSQuery {
 	return &DNSQuery{
-		Timeout:    web.Duration{Duration: defaultTimeout},
-		Network:    defaultNetwork,
-		RecordType: defaultRecordType,
-		Port:       defaultPort,
+		Timeout: web.Duration{Duration: defaultTimeout},
+		Network: defaultNetwork,
 
 		task:             make(chan task),
 		taskDone:         make(chan struct{}),
 		exchangerFactory: newExchanger,
+		charts:           &module.Charts{},
+		queryTmpl:        module.MustNewChartTemplate(queryCharts),
+		dnssecTmpl:       module.MustNewChartTemplate(dnssecCharts),
+		assertionTmpl:    module.MustNewChartTemplate(assertionCharts),
 		servers:          make([]*server, 0),
 		workers:          make([]*worker, 0),
 	}
@@ -53,43 +52,58 @@ type exchanger interface {
 	Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error)
 }
 
-func newExchanger(network string, timeout time.Duration) exchanger {
+type exchangerFactory func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger
+
+func newExchanger(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+	if network == networkHTTPS {
+		return newDoHClient(timeout, tlsConfig)
+	}
 	return &dns.Client{
 		Net:         network,
+		DialTimeout: timeout,
 		ReadTimeout: timeout,
+		TLSConfig:   tlsConfig,
 	}
 }
 
-type server struct {
-	id   string
-	name string
-	port int
-
-	resp *dns.Msg
-	rtt  time.Duration
-	err  error
-}
-
-// DNSQuery dnsquery module
-type DNSQuery struct {
-	module.Base
-
-	Domains    []string
-	Servers    []string
-	Network    string
-	RecordType string `yaml:"record_type"`
-	Port       int
-	Timeout    web.Duration
-
-	task     chan task
-	taskDone chan struct{}
-
-	exchangerFactory func(network string, duration time.Duration) exchanger
-
-	rtype   uint16
-	servers []*server
-	workers []*worker
-}
+type (
+	// DNSQuery dnsquery module
+	DNSQuery struct {
+		module.Base
+
+		Domains          []string
+		Servers          []string
+		Network          string
+		RecordType       string   `yaml:"record_type"`
+		RecordTypes      []string `yaml:"record_types"`
+		Port             int
+		Timeout          web.Duration
+		DNSSEC           bool         `yaml:"dnssec"`
+		Expect           ExpectConfig `yaml:"expect"`
+		tlscfg.TLSConfig `yaml:",inline"`
+
+		task     chan task
+		taskDone chan struct{}
+
+		exchangerFactory exchangerFactory
+
+		charts        *module.Charts
+		queryTmpl     *module.ChartTemplate
+		dnssecTmpl    *module.ChartTemplate
+		assertionTmpl *module.ChartTemplate
+
+		expect  *expectation
+		servers []*server
+		queries []*query
+		workers []*worker
+	}
+	// ExpectConfig is the assertions on the answers of the successful queries.
+	ExpectConfig struct {
+		Addresses []string `yaml:"addresses"`
+		CNAME     string   `yaml:"cname"`
+		MinTTL    int      `yaml:"min_ttl"`
+	}
+)
 
 // Cleanup makes cleanup
 func (d *DNSQuery) Cleanup() {
@@ -100,40 +114,30 @@ func (d *DNSQuery) Cleanup() {
 	d.workers = make([]*worker, 0)
 }
 
-func (d *DNSQuery) setup() error {
-	if len(d.Domains) == 0 {
-		return errors.New("no domains specified")
-	}
-
-	if len(d.Servers) == 0 {
-		return errors.New("no servers specified")
-	}
-
-	if !(d.Network == "" || d.Network == "udp" || d.Network == "tcp" || d.Network == "tcp-tls") {
-		return fmt.Errorf("wrong network transport : %s", d.Network)
+// Init makes initialization
+func (d *DNSQuery) Init() bool {
+	if err := d.setup(); err != nil {
+		d.Error(err)
+		return false
 	}
 
-	rtype, err := parseRecordType(d.RecordType)
-
+	exch, err := d.initExchanger()
 	if err != nil {
-		return fmt.Errorf("error on parsing record type : %s", err)
+		d.Errorf("exchanger initialization: %v", err)
+		return false
 	}
-	d.rtype = rtype
 
-	return nil
-}
-
-// Init makes initialization
-func (d *DNSQuery) Init() bool {
-	if err := d.setup(); err != nil {
-		d.Error(err)
+	if err := d.initQueries(); err != nil {
+		d.Errorf("queries initialization: %v", err)
 		return false
 	}
 
-	exch := d.exchangerFactory(d.Network, d.Timeout.Duration)
+	if err := d.initCharts(); err != nil {
+		d.Errorf("charts initialization: %v", err)
+		return false
+	}
 
-	for _, srv := range d.Servers {
-		d.servers = append(d.servers, &server{id: serverNameReplacer.Replace(srv), name: srv, port: d.Port})
+	for range d.queries {
 		// newWorker spawns worker goroutine
 		d.workers = append(d.workers, newWorker(exch, d.task, d.taskDone))
 	}
@@ -146,95 +150,17 @@ func (DNSQuery) Check() bool {
 	return true
 }
 
-// Charts creates Charts
-func (d DNSQuery) Charts() *Charts {
-	charts := charts.Copy()
-
-	for _, srv := range d.servers {
-		chart := charts.Get("query_time")
-		dim := &Dim{ID: srv.id, Name: srv.name, Div: 1000000}
-
-		if err := chart.AddDim(dim); err != nil {
-			d.Errorf("error on creating charts : %s", err)
-			return nil
-		}
-	}
-
-	return charts
+// Charts returns Charts
+func (d *DNSQuery) Charts() *module.Charts {
+	return d.charts
 }
 
 // Collect collects metrics
 func (d *DNSQuery) Collect() map[string]int64 {
-	domain := randomDomain(d.Domains)
-	d.Debugf("current domain : %s", domain)
-
-	for _, srv := range d.servers {
-		d.task <- task{server: srv, domain: domain, rtype: d.rtype}
-	}
-
-	for range d.servers {
-		<-d.taskDone
-	}
+	mx := d.collect()
 
-	metrics := make(map[string]int64)
-
-	for _, srv := range d.servers {
-		if srv.resp != nil && srv.resp.Rcode != dns.RcodeSuccess {
-			d.Errorf("invalid answer from %s after %s query for %s", srv.name, d.RecordType, domain)
-			continue
-		}
-
-		if srv.err != nil {
-			d.Debugf("error on querying %s after %s query for %s : %s", srv.name, d.RecordType, domain, srv.err)
-			continue
-		}
-
-		metrics[srv.id] = srv.rtt.Nanoseconds()
-	}
-
-	if len(metrics) == 0 {
+	if len(mx) == 0 {
 		return nil
 	}
-
-	return metrics
+	return mx
 }
-
-func parseRecordType(recordType string) (uint16, error) {
-	var rtype uint16
-
-	switch recordType {
-	case "A":
-		rtype = dns.TypeA
-	case "AAAA":
-		rtype = dns.TypeAAAA
-	case "ANY":
-		rtype = dns.TypeANY
-	case "CNAME":
-		rtype = dns.TypeCNAME
-	case "MX":
-		rtype = dns.TypeMX
-	case "NS":
-		rtype = dns.TypeNS
-	case "PTR":
-		rtype = dns.TypePTR
-	case "SOA":
-		rtype = dns.TypeSOA
-	case "SPF":
-		rtype = dns.TypeSPF
-	case "SRV":
-		rtype = dns.TypeSRV
-	case "TXT":
-		rtype = dns.TypeTXT
-	default:
-		return 0, fmt.Errorf("unknown record type : %s", recordType)
-	}
-
-	return rtype, nil
-}
-
-func randomDomain(domains []string) string {
-	rand.Seed(time.Now().UnixNano())
-	return domains[rand.Intn(len(domains))]
-}
-
-var serverNameReplacer = strings.NewReplacer(".", "_")
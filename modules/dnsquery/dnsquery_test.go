@@ -3,12 +3,22 @@
 package dnsquery
 
 import (
+	"crypto/tls"
 	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/miekg/dns"
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,10 +37,95 @@ func TestDNSQuery_Init(t *testing.T) {
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8", "8.8.4.4"}
 	require.True(t, mod.Init())
+	defer mod.Cleanup()
 	assert.Len(t, mod.servers, len(mod.Servers))
 	assert.Len(t, mod.workers, len(mod.Servers))
 }
 
+func TestDNSQuery_Init_Config(t *testing.T) {
+	tests := map[string]struct {
+		prepare  func(d *DNSQuery)
+		wantFail bool
+	}{
+		"success on multiple record types": {
+			prepare: func(d *DNSQuery) { d.RecordTypes = []string{"A", "aaaa", "MX", "CAA"} },
+		},
+		"success on DNS over TLS": {
+			prepare: func(d *DNSQuery) { d.Network = "tcp-tls" },
+		},
+		"success on DNS over HTTPS": {
+			prepare: func(d *DNSQuery) { d.Network = "https" },
+		},
+		"success on expectations": {
+			prepare: func(d *DNSQuery) {
+				d.Expect = ExpectConfig{Addresses: []string{"192.0.2.1", "2001:db8::1"}, CNAME: "example.com", MinTTL: 60}
+			},
+		},
+		"fail when network is unknown": {
+			wantFail: true,
+			prepare:  func(d *DNSQuery) { d.Network = "quic" },
+		},
+		"fail when record type is unknown": {
+			wantFail: true,
+			prepare:  func(d *DNSQuery) { d.RecordTypes = []string{"A", "B"} },
+		},
+		"fail when server is duplicated": {
+			wantFail: true,
+			prepare:  func(d *DNSQuery) { d.Servers = []string{"192.0.2.53", "192.0.2.53"} },
+		},
+		"fail when expected address is invalid": {
+			wantFail: true,
+			prepare:  func(d *DNSQuery) { d.Expect.Addresses = []string{"192.0.2"} },
+		},
+		"fail when TLS config is invalid": {
+			wantFail: true,
+			prepare:  func(d *DNSQuery) { d.TLSConfig = tlscfg.TLSConfig{TLSCA: "testdata/not_exists.pem"} },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			mod.Domains = []string{"example.com"}
+			mod.Servers = []string{"192.0.2.53"}
+			test.prepare(mod)
+			defer mod.Cleanup()
+
+			if test.wantFail {
+				assert.False(t, mod.Init())
+			} else {
+				assert.True(t, mod.Init())
+			}
+		})
+	}
+}
+
+func TestDNSQuery_Init_ServerAddress(t *testing.T) {
+	tests := map[string]struct {
+		network string
+		port    int
+		server  string
+		want    string
+	}{
+		"udp":             {network: "udp", server: "192.0.2.53", want: "192.0.2.53:53"},
+		"udp IPv6":        {network: "udp", server: "2001:db8::53", want: "[2001:db8::53]:53"},
+		"tcp custom port": {network: "tcp", port: 5353, server: "192.0.2.53", want: "192.0.2.53:5353"},
+		"tcp-tls":         {network: "tcp-tls", server: "dns.example.com", want: "dns.example.com:853"},
+		"https":           {network: "https", server: "dns.example.com", want: "https://dns.example.com:443/dns-query"},
+		"https URL":       {network: "https", server: "https://dns.example.com/query", want: "https://dns.example.com/query"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			mod.Network = test.network
+			mod.Port = test.port
+
+			assert.Equal(t, test.want, mod.serverAddress(test.server))
+		})
+	}
+}
+
 func TestDNSQuery_Check(t *testing.T) {
 	assert.True(t, New().Check())
 }
@@ -42,9 +137,15 @@ func TestDNSQuery_Charts(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
+	mod.RecordTypes = []string{"A", "AAAA"}
+	mod.DNSSEC = true
 	require.True(t, mod.Init())
+	defer mod.Cleanup()
+
 	charts := mod.Charts()
-	assert.True(t, charts.Get("query_time").HasDim("8_8_8_8"))
+	assert.Len(t, *charts, (len(queryCharts)+len(dnssecCharts))*2)
+	assert.True(t, charts.Get("server_8.8.8.8_A_query_time").HasDim("server_8.8.8.8_A_query_time"))
+	assert.NotNil(t, charts.Get("server_8.8.8.8_AAAA_dnssec_status"))
 }
 
 func TestDNSQuery_Cleanup(t *testing.T) {
@@ -78,7 +179,7 @@ func TestDNSQuery_Collect(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(string, time.Duration, *tls.Config) exchanger {
 		return okMockExchanger{}
 	}
 
@@ -87,7 +188,20 @@ func TestDNSQuery_Collect(t *testing.T) {
 
 	assert.Equal(
 		t,
-		map[string]int64{"8_8_8_8": 1000000000},
+		map[string]int64{
+			"server_8.8.8.8_A_query_status_success":       1,
+			"server_8.8.8.8_A_query_status_network_error": 0,
+			"server_8.8.8.8_A_query_status_dns_error":     0,
+			"server_8.8.8.8_A_query_time":                 1000000,
+			"server_8.8.8.8_A_rcode_noerror":              1,
+			"server_8.8.8.8_A_rcode_formerr":              0,
+			"server_8.8.8.8_A_rcode_servfail":             0,
+			"server_8.8.8.8_A_rcode_nxdomain":             0,
+			"server_8.8.8.8_A_rcode_notimp":               0,
+			"server_8.8.8.8_A_rcode_refused":              0,
+			"server_8.8.8.8_A_rcode_other":                0,
+			"server_8.8.8.8_A_answer_changed":             0,
+		},
 		mod.Collect(),
 	)
 }
@@ -98,24 +212,231 @@ func TestDNSQuery_Collect_Error(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(string, time.Duration, *tls.Config) exchanger {
 		return errMockExchanger{}
 	}
 
 	require.True(t, mod.Init())
 	require.True(t, mod.Check())
 
-	assert.Len(
-		t,
-		mod.Collect(),
-		0,
-	)
+	mx := mod.Collect()
+	assert.Equal(t, int64(1), mx["server_8.8.8.8_A_query_status_network_error"])
+	assert.NotContains(t, mx, "server_8.8.8.8_A_query_time")
+}
+
+func TestDNSQuery_Collect_Networks(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, network := range []string{"udp", "tcp", "tcp-tls", "https"} {
+		t.Run(network, func(t *testing.T) {
+			mod := New()
+			defer mod.Cleanup()
+
+			mod.Domains = []string{"example.com"}
+			mod.Servers = []string{"127.0.0.1"}
+			mod.Network = network
+			mod.Port = srv.ports[network]
+			mod.RecordTypes = []string{"A", "AAAA"}
+			mod.Expect = ExpectConfig{Addresses: []string{"192.0.2.1", "2001:db8::1"}, MinTTL: 60}
+			mod.TLSConfig = tlscfg.TLSConfig{InsecureSkipVerify: true}
+			require.True(t, mod.Init())
+
+			mx := mod.Collect()
+
+			for _, typ := range []string{"A", "AAAA"} {
+				px := "server_127.0.0.1_" + typ + "_"
+				assert.Equalf(t, int64(1), mx[px+"query_status_success"], "%s query status", typ)
+				assert.Equalf(t, int64(1), mx[px+"rcode_noerror"], "%s rcode", typ)
+				assert.Equalf(t, int64(1), mx[px+"answer_assertion_passed"], "%s assertion", typ)
+				assert.Containsf(t, mx, px+"query_time", "%s query time", typ)
+			}
+			ensureCollectedHasAllChartsDimsVarsIDs(t, mod, mx)
+		})
+	}
+}
+
+func TestDNSQuery_Collect_Answers(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := map[string]struct {
+		domain   string
+		dnssec   bool
+		expect   ExpectConfig
+		runs     int
+		expected map[string]int64
+	}{
+		"DNSSEC secure": {
+			domain: "secure.example.com",
+			dnssec: true,
+			expected: map[string]int64{
+				"query_status_success":   1,
+				"dnssec_status_secure":   1,
+				"dnssec_status_insecure": 0,
+				"dnssec_status_bogus":    0,
+			},
+		},
+		"DNSSEC insecure": {
+			domain: "example.com",
+			dnssec: true,
+			expected: map[string]int64{
+				"query_status_success":   1,
+				"dnssec_status_secure":   0,
+				"dnssec_status_insecure": 1,
+				"dnssec_status_bogus":    0,
+			},
+		},
+		"DNSSEC bogus": {
+			domain: "bogus.example.com",
+			dnssec: true,
+			expected: map[string]int64{
+				"query_status_dns_error": 1,
+				"rcode_servfail":         1,
+				"dnssec_status_secure":   0,
+				"dnssec_status_insecure": 0,
+				"dnssec_status_bogus":    1,
+			},
+		},
+		"NXDOMAIN": {
+			domain: "missing.example.com",
+			runs:   2,
+			expected: map[string]int64{
+				"query_status_success":   0,
+				"query_status_dns_error": 1,
+				"rcode_nxdomain":         2,
+			},
+		},
+		"REFUSED": {
+			domain: "refused.example.com",
+			expected: map[string]int64{
+				"query_status_dns_error": 1,
+				"rcode_refused":          1,
+			},
+		},
+		"answer changed": {
+			domain: "rotating.example.com",
+			runs:   2,
+			expected: map[string]int64{
+				"query_status_success": 1,
+				"answer_changed":       1,
+			},
+		},
+		"answer not changed": {
+			domain: "example.com",
+			runs:   2,
+			expected: map[string]int64{
+				"query_status_success": 1,
+				"answer_changed":       0,
+			},
+		},
+		"assertion passed on CNAME": {
+			domain: "www.example.com",
+			expect: ExpectConfig{CNAME: "Example.com", Addresses: []string{"192.0.2.1"}},
+			expected: map[string]int64{
+				"answer_assertion_passed": 1,
+				"answer_assertion_failed": 0,
+			},
+		},
+		"assertion failed on CNAME": {
+			domain: "www.example.com",
+			expect: ExpectConfig{CNAME: "cdn.example.net"},
+			expected: map[string]int64{
+				"answer_assertion_passed": 0,
+				"answer_assertion_failed": 1,
+			},
+		},
+		"assertion failed on address": {
+			domain: "example.com",
+			expect: ExpectConfig{Addresses: []string{"192.0.2.2"}},
+			expected: map[string]int64{
+				"answer_assertion_passed": 0,
+				"answer_assertion_failed": 1,
+			},
+		},
+		"assertion failed on min TTL": {
+			domain: "example.com",
+			expect: ExpectConfig{MinTTL: 3600},
+			expected: map[string]int64{
+				"answer_assertion_passed": 0,
+				"answer_assertion_failed": 1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			defer mod.Cleanup()
+
+			mod.Domains = []string{test.domain}
+			mod.Servers = []string{"127.0.0.1"}
+			mod.Port = srv.ports["udp"]
+			mod.DNSSEC = test.dnssec
+			mod.Expect = test.expect
+			require.True(t, mod.Init())
+
+			var mx map[string]int64
+			for i := 0; i < test.runs || i == 0; i++ {
+				mx = mod.Collect()
+			}
+
+			for k, v := range test.expected {
+				assert.Equalf(t, v, mx["server_127.0.0.1_A_"+k], "metric '%s'", k)
+			}
+		})
+	}
+}
+
+func TestExpectation_check(t *testing.T) {
+	a := func(ip string) dns.RR {
+		rr, _ := dns.NewRR("example.com. 300 IN A " + ip)
+		return rr
+	}
+
+	tests := map[string]struct {
+		expect  ExpectConfig
+		rtype   uint16
+		answer  []dns.RR
+		wantErr bool
+	}{
+		"addresses subset":   {expect: ExpectConfig{Addresses: []string{"192.0.2.1", "192.0.2.2"}}, rtype: dns.TypeA, answer: []dns.RR{a("192.0.2.2")}},
+		"unexpected address": {expect: ExpectConfig{Addresses: []string{"192.0.2.1"}}, rtype: dns.TypeA, answer: []dns.RR{a("192.0.2.1"), a("192.0.2.3")}, wantErr: true},
+		"no addresses":       {expect: ExpectConfig{Addresses: []string{"192.0.2.1"}}, rtype: dns.TypeA, wantErr: true},
+		"no IPv6 expected":   {expect: ExpectConfig{Addresses: []string{"192.0.2.1"}}, rtype: dns.TypeAAAA},
+		"min TTL":            {expect: ExpectConfig{MinTTL: 300}, rtype: dns.TypeA, answer: []dns.RR{a("192.0.2.1")}},
+		"min TTL not met":    {expect: ExpectConfig{MinTTL: 301}, rtype: dns.TypeA, answer: []dns.RR{a("192.0.2.1")}, wantErr: true},
+		"no CNAME":           {expect: ExpectConfig{CNAME: "example.net"}, rtype: dns.TypeA, answer: []dns.RR{a("192.0.2.1")}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := newExpectation(test.expect)
+			require.NoError(t, err)
+
+			err = e.check(test.rtype, &dns.Msg{Answer: test.answer})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, d *DNSQuery, mx map[string]int64) {
+	for _, chart := range *d.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+	}
 }
 
 type okMockExchanger struct{}
 
-func (m okMockExchanger) Exchange(_ *dns.Msg, _ string) (response *dns.Msg, rtt time.Duration, err error) {
-	return nil, time.Second, nil
+func (m okMockExchanger) Exchange(msg *dns.Msg, _ string) (response *dns.Msg, rtt time.Duration, err error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	return resp, time.Second, nil
 }
 
 type errMockExchanger struct{}
@@ -123,3 +444,113 @@ type errMockExchanger struct{}
 func (m errMockExchanger) Exchange(_ *dns.Msg, _ string) (response *dns.Msg, rtt time.Duration, err error) {
 	return nil, time.Second, errors.New("mock error")
 }
+
+// testServer is an authoritative and validating DNS server of the "example.com." zone:
+//   - "example.com.": A 192.0.2.1, AAAA 2001:db8::1, TTL 300.
+//   - "www.example.com.": CNAME "example.com.".
+//   - "secure.example.com.": A 192.0.2.10, the AD flag is set if the DO bit is set.
+//   - "bogus.example.com.": SERVFAIL unless the checking is disabled.
+//   - "rotating.example.com.": A 192.0.2.x, x is the number of the query.
+//   - "refused.example.com.": REFUSED.
+//   - other names: NXDOMAIN.
+type testServer struct {
+	ports map[string]int
+
+	mux      sync.Mutex
+	rotating int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{ports: make(map[string]int)}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s.serve(t, &dns.Server{PacketConn: pc, Handler: s})
+	s.ports["udp"] = pc.LocalAddr().(*net.UDPAddr).Port
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s.serve(t, &dns.Server{Listener: ln, Handler: s})
+	s.ports["tcp"] = ln.Addr().(*net.TCPAddr).Port
+
+	doh := httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	doh.Config.ErrorLog = log.New(io.Discard, "", 0)
+	doh.StartTLS()
+	t.Cleanup(doh.Close)
+	s.ports["https"] = doh.Listener.Addr().(*net.TCPAddr).Port
+
+	tlsLn, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: doh.TLS.Certificates})
+	require.NoError(t, err)
+	s.serve(t, &dns.Server{Listener: tlsLn, Net: "tcp-tls", Handler: s})
+	s.ports["tcp-tls"] = tlsLn.Addr().(*net.TCPAddr).Port
+
+	return s
+}
+
+func (s *testServer) serve(t *testing.T, srv *dns.Server) {
+	go func() { _ = srv.ActivateAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown() })
+}
+
+func (s *testServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	_ = w.WriteMsg(s.answer(req))
+}
+
+func (s *testServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != dnsMessageContentType {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	req := new(dns.Msg)
+	if err := req.Unpack(body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	bs, _ := s.answer(req).Pack()
+	w.Header().Set("Content-Type", dnsMessageContentType)
+	_, _ = w.Write(bs)
+}
+
+func (s *testServer) answer(req *dns.Msg) *dns.Msg {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	q := req.Question[0]
+	name := strings.ToLower(q.Name)
+	rr := func(s string) {
+		v, _ := dns.NewRR(s)
+		if v.Header().Rrtype == q.Qtype || v.Header().Rrtype == dns.TypeCNAME {
+			resp.Answer = append(resp.Answer, v)
+		}
+	}
+	dnssecOK := req.IsEdns0() != nil && req.IsEdns0().Do()
+
+	switch name {
+	case "example.com.":
+		rr("example.com. 300 IN A 192.0.2.1")
+		rr("example.com. 300 IN AAAA 2001:db8::1")
+	case "www.example.com.":
+		rr("www.example.com. 300 IN CNAME example.com.")
+		rr("example.com. 300 IN A 192.0.2.1")
+	case "secure.example.com.":
+		rr("secure.example.com. 300 IN A 192.0.2.10")
+		resp.AuthenticatedData = dnssecOK
+	case "bogus.example.com.":
+		if !req.CheckingDisabled {
+			resp.Rcode = dns.RcodeServerFailure
+			break
+		}
+		rr("bogus.example.com. 300 IN A 192.0.2.11")
+	case "rotating.example.com.":
+		s.mux.Lock()
+		s.rotating++
+		rr("rotating.example.com. 60 IN A 192.0.2." + strconv.Itoa(s.rotating))
+		s.mux.Unlock()
+	case "refused.example.com.":
+		resp.Rcode = dns.RcodeRefused
+	default:
+		resp.Rcode = dns.RcodeNameError
+	}
+	return resp
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package dnsquery
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const dnsMessageContentType = "application/dns-message"
+
+// dohClient is a DNS over HTTPS (RFC 8484) client, the address is the URL of the server.
+type dohClient struct {
+	httpClient *http.Client
+}
+
+func newDoHClient(timeout time.Duration, tlsConfig *tls.Config) *dohClient {
+	return &dohClient{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				TLSClientConfig:     tlsConfig,
+				DialContext:         (&net.Dialer{Timeout: timeout}).DialContext,
+				TLSHandshakeTimeout: timeout,
+				ForceAttemptHTTP2:   true,
+			},
+		},
+	}
+}
+
+func (c *dohClient) Exchange(msg *dns.Msg, address string) (*dns.Msg, time.Duration, error) {
+	// the ID should be 0 to be HTTP cache friendly
+	msg.Id = 0
+	bs, err := msg.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(bs))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("'%s' returned HTTP status code %d", address, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(body); err != nil {
+		return nil, 0, fmt.Errorf("error on unpacking '%s' response : %v", address, err)
+	}
+	return answer, rtt, nil
+}
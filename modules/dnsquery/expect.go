@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package dnsquery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// expectation is the parsed ExpectConfig.
+type expectation struct {
+	addresses map[string]bool
+	hasV4     bool
+	hasV6     bool
+	cname     string
+	minTTL    uint32
+}
+
+func newExpectation(cfg ExpectConfig) (*expectation, error) {
+	e := &expectation{addresses: make(map[string]bool)}
+	for _, v := range cfg.Addresses {
+		ip := net.ParseIP(strings.TrimSpace(v))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address '%s'", v)
+		}
+		if ip.To4() != nil {
+			e.hasV4 = true
+		} else {
+			e.hasV6 = true
+		}
+		e.addresses[ip.String()] = true
+	}
+	if cfg.CNAME != "" {
+		e.cname = dns.Fqdn(strings.ToLower(cfg.CNAME))
+	}
+	if cfg.MinTTL < 0 {
+		return nil, fmt.Errorf("invalid min TTL (%d)", cfg.MinTTL)
+	}
+	e.minTTL = uint32(cfg.MinTTL)
+	return e, nil
+}
+
+func (e *expectation) empty() bool {
+	return len(e.addresses) == 0 && e.cname == "" && e.minTTL == 0
+}
+
+// check returns an error if the answer doesn't meet the expectations:
+//   - the A/AAAA answers are all among the expected addresses, and there is at least one
+//     if an address of the family is expected.
+//   - the answer has a CNAME record pointing to the expected name.
+//   - the TTLs of all the answer records are at least the min TTL.
+func (e *expectation) check(rtype uint16, resp *dns.Msg) error {
+	var addresses int
+	var cnameFound bool
+
+	for _, rr := range resp.Answer {
+		if rr.Header().Ttl < e.minTTL {
+			return fmt.Errorf("record '%s' TTL %d is less than %d", rr.Header().Name, rr.Header().Ttl, e.minTTL)
+		}
+
+		var ip net.IP
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		case *dns.CNAME:
+			cnameFound = cnameFound || strings.EqualFold(v.Target, e.cname)
+		}
+		if ip != nil && len(e.addresses) > 0 {
+			if !e.addresses[ip.String()] {
+				return fmt.Errorf("unexpected address %s", ip)
+			}
+			addresses++
+		}
+	}
+
+	if addresses == 0 && ((rtype == dns.TypeA && e.hasV4) || (rtype == dns.TypeAAAA && e.hasV6)) {
+		return errors.New("no expected addresses in the answer")
+	}
+	if e.cname != "" && !cnameFound {
+		return fmt.Errorf("no CNAME record pointing to %s", e.cname)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package dnsquery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	networkUDP   = "udp"
+	networkTCP   = "tcp"
+	networkTLS   = "tcp-tls"
+	networkHTTPS = "https"
+)
+
+var defaultPorts = map[string]int{
+	networkUDP:   53,
+	networkTCP:   53,
+	networkTLS:   853,
+	networkHTTPS: 443,
+}
+
+func (d *DNSQuery) setup() error {
+	if len(d.Domains) == 0 {
+		return errors.New("no domains specified")
+	}
+
+	if len(d.Servers) == 0 {
+		return errors.New("no servers specified")
+	}
+
+	if d.Network == "" {
+		d.Network = defaultNetwork
+	}
+	if _, ok := defaultPorts[d.Network]; !ok {
+		return fmt.Errorf("wrong network transport : %s", d.Network)
+	}
+
+	expect, err := newExpectation(d.Expect)
+	if err != nil {
+		return fmt.Errorf("error on parsing expectations : %v", err)
+	}
+	d.expect = expect
+
+	return nil
+}
+
+func (d *DNSQuery) initExchanger() (exchanger, error) {
+	tlsConfig, err := tlscfg.NewTLSConfig(d.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating TLS config : %v", err)
+	}
+	return d.exchangerFactory(d.Network, d.Timeout.Duration, tlsConfig), nil
+}
+
+// initQueries creates a query of every record type for every server.
+func (d *DNSQuery) initQueries() error {
+	rtypes, err := d.recordTypes()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range d.Servers {
+		if seen[name] {
+			return fmt.Errorf("server '%s' is duplicated", name)
+		}
+		seen[name] = true
+
+		srv := &server{name: name, address: d.serverAddress(name)}
+		d.servers = append(d.servers, srv)
+
+		for _, rtype := range rtypes {
+			d.queries = append(d.queries, newQuery(srv, rtype))
+		}
+	}
+	return nil
+}
+
+// recordTypes returns the record types to query, 'record_type' is the single type form of 'record_types'.
+func (d *DNSQuery) recordTypes() ([]uint16, error) {
+	names := d.RecordTypes
+	if d.RecordType != "" {
+		names = append([]string{d.RecordType}, names...)
+	}
+	if len(names) == 0 {
+		names = []string{defaultRecordType}
+	}
+
+	var rtypes []uint16
+	seen := make(map[uint16]bool)
+	for _, name := range names {
+		rtype, err := parseRecordType(name)
+		if err != nil {
+			return nil, fmt.Errorf("error on parsing record type : %s", err)
+		}
+		if !seen[rtype] {
+			seen[rtype] = true
+			rtypes = append(rtypes, rtype)
+		}
+	}
+	return rtypes, nil
+}
+
+// serverAddress returns the address of the server for the exchanger: host:port or, for DNS over HTTPS, the URL.
+func (d *DNSQuery) serverAddress(name string) string {
+	if d.Network == networkHTTPS && strings.HasPrefix(name, "https://") {
+		return name
+	}
+	port := d.Port
+	if port == 0 {
+		port = defaultPorts[d.Network]
+	}
+	address := net.JoinHostPort(name, strconv.Itoa(port))
+	if d.Network == networkHTTPS {
+		return "https://" + address + "/dns-query"
+	}
+	return address
+}
+
+func (d *DNSQuery) initCharts() error {
+	for _, q := range d.queries {
+		labels := map[string]string{
+			"server":      q.server.name,
+			"record_type": q.typeName,
+		}
+		if err := d.queryTmpl.Add(d.charts, q.key, labels); err != nil {
+			return err
+		}
+		if d.DNSSEC {
+			if err := d.dnssecTmpl.Add(d.charts, q.key, labels); err != nil {
+				return err
+			}
+		}
+		if !d.expect.empty() {
+			if err := d.assertionTmpl.Add(d.charts, q.key, labels); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseRecordType(recordType string) (uint16, error) {
+	rtype, ok := dns.StringToType[strings.ToUpper(recordType)]
+	if !ok || rtype == dns.TypeNone {
+		return 0, fmt.Errorf("unknown record type : %s", recordType)
+	}
+	return rtype, nil
+}
@@ -3,16 +3,13 @@
 package dnsquery
 
 import (
-	"net"
-	"strconv"
-
 	"github.com/miekg/dns"
 )
 
 type task struct {
-	server *server
+	query  *query
 	domain string
-	rtype  uint16
+	dnssec bool
 }
 
 func newWorker(exchanger exchanger, task chan task, taskDone chan struct{}) *worker {
@@ -41,15 +38,35 @@ func (w *worker) workLoop() {
 }
 
 func (w *worker) doWork(t task) {
-	msg := new(dns.Msg)
-	msg.SetQuestion(dns.Fqdn(t.domain), t.rtype)
-	address := net.JoinHostPort(t.server.name, strconv.Itoa(t.server.port))
+	q := t.query
+	msg := newMsg(t.domain, q.rtype, t.dnssec)
 
-	resp, rtt, err := w.exchanger.Exchange(msg, address)
+	resp, rtt, err := w.exchanger.Exchange(msg, q.server.address)
 
-	t.server.resp = resp
-	t.server.rtt = rtt
-	t.server.err = err
+	q.resp = resp
+	q.rtt = rtt
+	q.err = err
+	q.bogus = false
+
+	// a validating resolver answers SERVFAIL if the DNSSEC validation fails,
+	// the answer is bogus if the resolver answers with the checking disabled.
+	if err == nil && t.dnssec && resp != nil && resp.Rcode == dns.RcodeServerFailure {
+		msg = newMsg(t.domain, q.rtype, t.dnssec)
+		msg.CheckingDisabled = true
+		if cdResp, _, err := w.exchanger.Exchange(msg, q.server.address); err == nil && cdResp != nil {
+			q.bogus = cdResp.Rcode != dns.RcodeServerFailure
+		}
+	}
 
 	w.taskDone <- struct{}{}
 }
+
+func newMsg(domain string, rtype uint16, dnssec bool) *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), rtype)
+	if dnssec {
+		msg.AuthenticatedData = true
+		msg.SetEdns0(4096, true)
+	}
+	return msg
+}
@@ -9,6 +9,10 @@ var (
 		fileExistenceChart.Copy(),
 		fileModTimeAgoChart.Copy(),
 		fileSizeChart.Copy(),
+		fileChecksumChangedChart.Copy(),
+		fileLinesChart.Copy(),
+		fileModeComplianceChart.Copy(),
+		fileOwnerComplianceChart.Copy(),
 	}
 
 	fileExistenceChart = module.Chart{
@@ -35,6 +39,34 @@ var (
 		Fam:   "files",
 		Ctx:   "filecheck.file_size",
 	}
+	fileChecksumChangedChart = module.Chart{
+		ID:    "file_checksum_changed",
+		Title: "File Checksum Change (0: not changed, 1: changed)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_checksum_changed",
+	}
+	fileLinesChart = module.Chart{
+		ID:    "file_lines",
+		Title: "File Number of Lines",
+		Units: "lines",
+		Fam:   "files",
+		Ctx:   "filecheck.file_lines",
+	}
+	fileModeComplianceChart = module.Chart{
+		ID:    "file_mode_compliance",
+		Title: "File Permissions Compliance (0: not compliant, 1: compliant)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_mode_compliance",
+	}
+	fileOwnerComplianceChart = module.Chart{
+		ID:    "file_owner_compliance",
+		Title: "File Owner Compliance (0: not compliant, 1: compliant)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_owner_compliance",
+	}
 )
 
 var (
@@ -43,6 +75,9 @@ var (
 		dirModTimeChart.Copy(),
 		dirNumOfFilesChart.Copy(),
 		dirSizeChart.Copy(),
+		dirOldestFileAgeChart.Copy(),
+		dirModeComplianceChart.Copy(),
+		dirOwnerComplianceChart.Copy(),
 	}
 
 	dirExistenceChart = module.Chart{
@@ -76,4 +111,25 @@ var (
 		Fam:   "dirs",
 		Ctx:   "filecheck.dir_size",
 	}
+	dirOldestFileAgeChart = module.Chart{
+		ID:    "dir_oldest_file_age",
+		Title: "Dir Oldest File Age",
+		Units: "seconds",
+		Fam:   "dirs",
+		Ctx:   "filecheck.dir_oldest_file_age",
+	}
+	dirModeComplianceChart = module.Chart{
+		ID:    "dir_mode_compliance",
+		Title: "Dir Permissions Compliance (0: not compliant, 1: compliant)",
+		Units: "boolean",
+		Fam:   "dirs",
+		Ctx:   "filecheck.dir_mode_compliance",
+	}
+	dirOwnerComplianceChart = module.Chart{
+		ID:    "dir_owner_compliance",
+		Title: "Dir Owner Compliance (0: not compliant, 1: compliant)",
+		Units: "boolean",
+		Fam:   "dirs",
+		Ctx:   "filecheck.dir_owner_compliance",
+	}
 )
@@ -3,6 +3,7 @@
 package filecheck
 
 import (
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -24,6 +25,15 @@ func hasMeta(path string) bool {
 	return strings.ContainsAny(path, magicChars)
 }
 
+func isExcluded(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func removeDuplicates(s []string) []string {
 	set := make(map[string]bool, len(s))
 	uniq := s[:0]
@@ -52,11 +52,21 @@ func (fc *Filecheck) collectDir(ms map[string]int64, path string, curTime time.T
 			ms[dirDimID(path, "size_bytes")] = size
 		}
 	}
+	if fc.Dirs.CollectOldestFileAge {
+		if modTime, err := calcDirOldestFileModTime(path); err == nil {
+			var age int64
+			if !modTime.IsZero() {
+				age = int64(curTime.Sub(modTime).Seconds())
+			}
+			ms[dirDimID(path, "oldest_file_age")] = age
+		}
+	}
+	fc.dirsPerms.collect(ms, func(metric string) string { return dirDimID(path, metric) }, info)
 }
 
 func (fc Filecheck) discoveryDirs() (dirs []string) {
 	for _, path := range fc.Dirs.Include {
-		if hasMeta(path) {
+		if hasMeta(path) || isExcluded(path, fc.Dirs.Exclude) {
 			continue
 		}
 		dirs = append(dirs, path)
@@ -68,6 +78,9 @@ func (fc Filecheck) discoveryDirs() (dirs []string) {
 		}
 		matches, _ := filepath.Glob(path)
 		for _, v := range matches {
+			if isExcluded(v, fc.Dirs.Exclude) {
+				continue
+			}
 			fi, err := os.Lstat(v)
 			if err == nil && fi.IsDir() {
 				dirs = append(dirs, v)
@@ -100,22 +113,13 @@ func (fc *Filecheck) addDirToCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case dirExistenceChart.ID:
-			id = dirDimID(path, "exists")
-		case dirModTimeChart.ID:
-			id = dirDimID(path, "mtime_ago")
-		case dirNumOfFilesChart.ID:
-			id = dirDimID(path, "num_of_files")
-		case dirSizeChart.ID:
-			id = dirDimID(path, "size_bytes")
-		default:
+		metric := dirChartMetric(chart.ID)
+		if metric == "" {
 			fc.Warningf("add dimension: couldn't dim id for '%s' chart (dir '%s')", chart.ID, path)
 			continue
 		}
 
-		dim := &module.Dim{ID: id, Name: path}
+		dim := &module.Dim{ID: dirDimID(path, metric), Name: path}
 
 		if err := chart.AddDim(dim); err != nil {
 			fc.Warning(err)
@@ -131,22 +135,13 @@ func (fc *Filecheck) removeDirFromCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case dirExistenceChart.ID:
-			id = dirDimID(path, "exists")
-		case dirModTimeChart.ID:
-			id = dirDimID(path, "mtime_ago")
-		case dirNumOfFilesChart.ID:
-			id = dirDimID(path, "num_of_files")
-		case dirSizeChart.ID:
-			id = dirDimID(path, "size_bytes")
-		default:
+		metric := dirChartMetric(chart.ID)
+		if metric == "" {
 			fc.Warningf("remove dimension: couldn't dim id for '%s' chart (dir '%s')", chart.ID, path)
 			continue
 		}
 
-		if err := chart.MarkDimRemove(id, true); err != nil {
+		if err := chart.MarkDimRemove(dirDimID(path, metric), true); err != nil {
 			fc.Warning(err)
 			continue
 		}
@@ -154,6 +149,27 @@ func (fc *Filecheck) removeDirFromCharts(path string) {
 	}
 }
 
+func dirChartMetric(chartID string) string {
+	switch chartID {
+	case dirExistenceChart.ID:
+		return "exists"
+	case dirModTimeChart.ID:
+		return "mtime_ago"
+	case dirNumOfFilesChart.ID:
+		return "num_of_files"
+	case dirSizeChart.ID:
+		return "size_bytes"
+	case dirOldestFileAgeChart.ID:
+		return "oldest_file_age"
+	case dirModeComplianceChart.ID:
+		return "mode_compliant"
+	case dirOwnerComplianceChart.ID:
+		return "owner_compliant"
+	default:
+		return ""
+	}
+}
+
 func dirDimID(path, metric string) string {
 	return fmt.Sprintf("dir_%s_%s", path, metric)
 }
@@ -182,3 +198,26 @@ func calcDirSize(dirpath string) (int64, error) {
 	})
 	return size, err
 }
+
+// calcDirOldestFileModTime returns the modification time of the oldest regular file in the directory (not recursive),
+// it is zero if there are no files.
+func calcDirOldestFileModTime(dirpath string) (time.Time, error) {
+	entries, err := os.ReadDir(dirpath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var oldest time.Time
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+	return oldest, nil
+}
@@ -45,11 +45,46 @@ func (fc *Filecheck) collectFile(ms map[string]int64, path string, curTime time.
 	ms[fileDimID(path, "exists")] = 1
 	ms[fileDimID(path, "size_bytes")] = info.Size()
 	ms[fileDimID(path, "mtime_ago")] = int64(curTime.Sub(info.ModTime()).Seconds())
+
+	if fc.Files.CollectChecksum || fc.Files.CollectLineCount {
+		fc.collectFileContent(ms, path, info)
+	}
+	fc.filesPerms.collect(ms, func(metric string) string { return fileDimID(path, metric) }, info)
+}
+
+func (fc *Filecheck) collectFileContent(ms map[string]int64, path string, info os.FileInfo) {
+	hashContent := fc.Files.CollectChecksum && info.Size() <= fc.Files.ChecksumMaxSize
+	if fc.Files.CollectChecksum && !hashContent {
+		fc.Debugf("file '%s' size (%d) exceeds checksum max size (%d), skipping checksum", path, info.Size(), fc.Files.ChecksumMaxSize)
+	}
+	if !hashContent && !fc.Files.CollectLineCount {
+		return
+	}
+
+	var maxHashSize int64
+	if hashContent {
+		maxHashSize = fc.Files.ChecksumMaxSize
+	}
+	res, err := scanFile(path, maxHashSize, fc.Files.CollectLineCount)
+	if err != nil {
+		fc.Debug(err)
+		return
+	}
+
+	if fc.Files.CollectLineCount {
+		ms[fileDimID(path, "lines")] = res.lines
+	}
+	if res.checksum == "" {
+		return
+	}
+	prev, ok := fc.checksums[path]
+	fc.checksums[path] = res.checksum
+	ms[fileDimID(path, "checksum_changed")] = boolToInt(ok && prev != res.checksum)
 }
 
 func (fc Filecheck) discoveryFiles() (files []string) {
 	for _, path := range fc.Files.Include {
-		if hasMeta(path) {
+		if hasMeta(path) || isExcluded(path, fc.Files.Exclude) {
 			continue
 		}
 		files = append(files, path)
@@ -61,6 +96,9 @@ func (fc Filecheck) discoveryFiles() (files []string) {
 		}
 		matches, _ := filepath.Glob(path)
 		for _, v := range matches {
+			if isExcluded(v, fc.Files.Exclude) {
+				continue
+			}
 			fi, err := os.Lstat(v)
 			if err == nil && fi.Mode().IsRegular() {
 				files = append(files, v)
@@ -82,6 +120,7 @@ func (fc *Filecheck) updateFilesCharts(files []string) {
 	for path := range fc.collectedFiles {
 		if !set[path] {
 			delete(fc.collectedFiles, path)
+			delete(fc.checksums, path)
 			fc.removeFileFromCharts(path)
 		}
 	}
@@ -93,20 +132,13 @@ func (fc *Filecheck) addFileToCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case fileExistenceChart.ID:
-			id = fileDimID(path, "exists")
-		case fileModTimeAgoChart.ID:
-			id = fileDimID(path, "mtime_ago")
-		case fileSizeChart.ID:
-			id = fileDimID(path, "size_bytes")
-		default:
+		metric := fileChartMetric(chart.ID)
+		if metric == "" {
 			fc.Warningf("add dimension: couldn't dim id for '%s' chart (file '%s')", chart.ID, path)
 			continue
 		}
 
-		dim := &module.Dim{ID: id, Name: path}
+		dim := &module.Dim{ID: fileDimID(path, metric), Name: path}
 
 		if err := chart.AddDim(dim); err != nil {
 			fc.Warning(err)
@@ -122,20 +154,13 @@ func (fc *Filecheck) removeFileFromCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case fileExistenceChart.ID:
-			id = fileDimID(path, "exists")
-		case fileModTimeAgoChart.ID:
-			id = fileDimID(path, "mtime_ago")
-		case fileSizeChart.ID:
-			id = fileDimID(path, "size_bytes")
-		default:
+		metric := fileChartMetric(chart.ID)
+		if metric == "" {
 			fc.Warningf("remove dimension: couldn't dim id for '%s' chart (file '%s')", chart.ID, path)
 			continue
 		}
 
-		if err := chart.MarkDimRemove(id, true); err != nil {
+		if err := chart.MarkDimRemove(fileDimID(path, metric), true); err != nil {
 			fc.Warning(err)
 			continue
 		}
@@ -143,6 +168,27 @@ func (fc *Filecheck) removeFileFromCharts(path string) {
 	}
 }
 
+func fileChartMetric(chartID string) string {
+	switch chartID {
+	case fileExistenceChart.ID:
+		return "exists"
+	case fileModTimeAgoChart.ID:
+		return "mtime_ago"
+	case fileSizeChart.ID:
+		return "size_bytes"
+	case fileChecksumChangedChart.ID:
+		return "checksum_changed"
+	case fileLinesChart.ID:
+		return "lines"
+	case fileModeComplianceChart.ID:
+		return "mode_compliant"
+	case fileOwnerComplianceChart.ID:
+		return "owner_compliant"
+	default:
+		return ""
+	}
+}
+
 func fileDimID(path, metric string) string {
 	return fmt.Sprintf("file_%s_%s", path, metric)
 }
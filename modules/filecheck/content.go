@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package filecheck
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+)
+
+type scanResult struct {
+	checksum string
+	lines    int64
+}
+
+// scanFile reads the file once. The content is hashed if maxHashSize is positive, the checksum is empty
+// if the file has grown beyond maxHashSize while reading. The last line is counted even if it has no newline.
+func scanFile(path string, maxHashSize int64, countLines bool) (scanResult, error) {
+	var res scanResult
+
+	f, err := os.Open(path)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var h hash.Hash
+	if maxHashSize > 0 {
+		h = sha256.New()
+	}
+
+	buf := make([]byte, 32*1024)
+	var read int64
+	var last byte
+	for h != nil || countLines {
+		n, err := f.Read(buf)
+		if n > 0 {
+			read += int64(n)
+			if h != nil && read > maxHashSize {
+				h = nil
+			}
+			if h != nil {
+				_, _ = h.Write(buf[:n])
+			}
+			if countLines {
+				res.lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+				last = buf[n-1]
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, err
+		}
+	}
+
+	if countLines && read > 0 && last != '\n' {
+		res.lines++
+	}
+	if h != nil {
+		res.checksum = hex.EncodeToString(h.Sum(nil))
+	}
+	return res, nil
+}
@@ -22,12 +22,15 @@ func New() *Filecheck {
 	return &Filecheck{
 		Config: Config{
 			DiscoveryEvery: web.Duration{Duration: time.Second * 30},
-			Files:          filesConfig{},
+			Files: filesConfig{
+				ChecksumMaxSize: 10 * 1024 * 1024,
+			},
 			Dirs: dirsConfig{
 				CollectDirSize: true,
 			},
 		},
 		collectedFiles: make(map[string]bool),
+		checksums:      make(map[string]string),
 		collectedDirs:  make(map[string]bool),
 	}
 }
@@ -39,13 +42,25 @@ type (
 		Dirs           dirsConfig   `yaml:"dirs"`
 	}
 	filesConfig struct {
-		Include []string `yaml:"include"`
-		Exclude []string `yaml:"exclude"`
+		Include          []string    `yaml:"include"`
+		Exclude          []string    `yaml:"exclude"`
+		CollectChecksum  bool        `yaml:"collect_checksum"`
+		ChecksumMaxSize  int64       `yaml:"checksum_max_size"`
+		CollectLineCount bool        `yaml:"collect_line_count"`
+		Permissions      permsConfig `yaml:"permissions"`
 	}
 	dirsConfig struct {
-		Include        []string `yaml:"include"`
-		Exclude        []string `yaml:"exclude"`
-		CollectDirSize bool     `yaml:"collect_dir_size"`
+		Include              []string    `yaml:"include"`
+		Exclude              []string    `yaml:"exclude"`
+		CollectDirSize       bool        `yaml:"collect_dir_size"`
+		CollectOldestFileAge bool        `yaml:"collect_oldest_file_age"`
+		Permissions          permsConfig `yaml:"permissions"`
+	}
+	// permsConfig is the expected permissions, the empty values are not checked.
+	permsConfig struct {
+		Mode  string `yaml:"mode"`
+		Owner string `yaml:"owner"`
+		Group string `yaml:"group"`
 	}
 )
 
@@ -56,10 +71,13 @@ type Filecheck struct {
 	lastDiscoveryFiles time.Time
 	curFiles           []string
 	collectedFiles     map[string]bool
+	checksums          map[string]string
+	filesPerms         *permsCheck
 
 	lastDiscoveryDirs time.Time
 	curDirs           []string
 	collectedDirs     map[string]bool
+	dirsPerms         *permsCheck
 
 	charts *module.Charts
 }
@@ -74,6 +92,20 @@ func (fc *Filecheck) Init() bool {
 		return false
 	}
 
+	filesPerms, err := newPermsCheck(fc.Files.Permissions)
+	if err != nil {
+		fc.Errorf("error on initializing files permissions check: %v", err)
+		return false
+	}
+	fc.filesPerms = filesPerms
+
+	dirsPerms, err := newPermsCheck(fc.Dirs.Permissions)
+	if err != nil {
+		fc.Errorf("error on initializing dirs permissions check: %v", err)
+		return false
+	}
+	fc.dirsPerms = dirsPerms
+
 	charts, err := fc.initCharts()
 	if err != nil {
 		fc.Errorf("error on charts initialization: %v", err)
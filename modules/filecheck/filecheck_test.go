@@ -3,8 +3,13 @@
 package filecheck
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 
@@ -53,6 +58,24 @@ func TestFilecheck_Init(t *testing.T) {
 					CollectDirSize: true,
 				},
 			},
+			wantNumOfCharts: 3 + 4,
+		},
+		"files->include and dirs->include with all checks": {
+			config: Config{
+				Files: filesConfig{
+					Include:          []string{"/path/to/file1"},
+					CollectChecksum:  true,
+					ChecksumMaxSize:  1024,
+					CollectLineCount: true,
+					Permissions:      permsConfig{Mode: "0640", Owner: "0"},
+				},
+				Dirs: dirsConfig{
+					Include:              []string{"/path/to/dir1"},
+					CollectDirSize:       true,
+					CollectOldestFileAge: true,
+					Permissions:          permsConfig{Mode: "0750", Group: "0"},
+				},
+			},
 			wantNumOfCharts: len(fileCharts) + len(dirCharts),
 		},
 		"only files->include": {
@@ -64,7 +87,7 @@ func TestFilecheck_Init(t *testing.T) {
 					},
 				},
 			},
-			wantNumOfCharts: len(fileCharts),
+			wantNumOfCharts: 3,
 		},
 		"only dirs->include": {
 			config: Config{
@@ -76,7 +99,43 @@ func TestFilecheck_Init(t *testing.T) {
 					CollectDirSize: true,
 				},
 			},
-			wantNumOfCharts: len(dirCharts),
+			wantNumOfCharts: 4,
+		},
+		"bad files->permissions->mode": {
+			config: Config{
+				Files: filesConfig{
+					Include:     []string{"/path/to/file1"},
+					Permissions: permsConfig{Mode: "rw-r-----"},
+				},
+			},
+			wantFail: true,
+		},
+		"unknown dirs->permissions->owner": {
+			config: Config{
+				Dirs: dirsConfig{
+					Include:     []string{"/path/to/dir1"},
+					Permissions: permsConfig{Owner: "no_such_user_filecheck"},
+				},
+			},
+			wantFail: true,
+		},
+		"bad files->exclude pattern": {
+			config: Config{
+				Files: filesConfig{
+					Include: []string{"/path/to/*"},
+					Exclude: []string{"/path/to/[.log"},
+				},
+			},
+			wantFail: true,
+		},
+		"zero files->checksum_max_size": {
+			config: Config{
+				Files: filesConfig{
+					Include:         []string{"/path/to/file1"},
+					CollectChecksum: true,
+				},
+			},
+			wantFail: true,
 		},
 	}
 
@@ -101,6 +160,7 @@ func TestFilecheck_Check(t *testing.T) {
 	}{
 		"collect files":                   {prepare: prepareFilecheckFiles},
 		"collect files filepath pattern":  {prepare: prepareFilecheckGlobFiles},
+		"collect files with exclude":      {prepare: prepareFilecheckGlobFilesWithExclude},
 		"collect only non existent files": {prepare: prepareFilecheckNonExistentFiles},
 		"collect dirs":                    {prepare: prepareFilecheckDirs},
 		"collect dirs filepath pattern":   {prepare: prepareFilecheckGlobDirs},
@@ -151,6 +211,31 @@ func TestFilecheck_Collect(t *testing.T) {
 				"num_of_dirs":                             0,
 			},
 		},
+		"collect files with exclude": {
+			prepare: prepareFilecheckGlobFilesWithExclude,
+			wantCollected: map[string]int64{
+				"file_testdata/file.log_exists":     1,
+				"file_testdata/file.log_mtime_ago":  4161,
+				"file_testdata/file.log_size_bytes": 5707,
+				"num_of_files":                      1,
+				"num_of_dirs":                       0,
+			},
+		},
+		"collect files with line count": {
+			prepare: prepareFilecheckFilesWithLineCount,
+			wantCollected: map[string]int64{
+				"file_testdata/empty_file.log_exists":     1,
+				"file_testdata/empty_file.log_mtime_ago":  5081,
+				"file_testdata/empty_file.log_size_bytes": 0,
+				"file_testdata/empty_file.log_lines":      0,
+				"file_testdata/file.log_exists":           1,
+				"file_testdata/file.log_mtime_ago":        4161,
+				"file_testdata/file.log_size_bytes":       5707,
+				"file_testdata/file.log_lines":            42,
+				"num_of_files":                            2,
+				"num_of_dirs":                             0,
+			},
+		},
 		"collect only non existent files": {
 			prepare: prepareFilecheckNonExistentFiles,
 			wantCollected: map[string]int64{
@@ -237,6 +322,78 @@ func TestFilecheck_Collect(t *testing.T) {
 	}
 }
 
+func TestFilecheck_Collect_Checksum(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.conf")
+	big := filepath.Join(dir, "big.conf")
+	require.NoError(t, os.WriteFile(small, []byte("key = value\n"), 0644))
+	require.NoError(t, os.WriteFile(big, make([]byte, 2048), 0644))
+
+	fc := New()
+	fc.Files.Include = []string{small, big}
+	fc.Files.CollectChecksum = true
+	fc.Files.ChecksumMaxSize = 1024
+	require.True(t, fc.Init())
+
+	smallID, bigID := fileDimID(small, "checksum_changed"), fileDimID(big, "checksum_changed")
+
+	collected := fc.Collect()
+	assert.Equal(t, int64(0), collected[smallID])
+	_, ok := collected[bigID]
+	assert.False(t, ok, "checksum of the file exceeding the max size is collected")
+
+	require.NoError(t, os.WriteFile(small, []byte("key = new value\n"), 0644))
+	assert.Equal(t, int64(1), fc.Collect()[smallID])
+	assert.Equal(t, int64(0), fc.Collect()[smallID])
+}
+
+func TestFilecheck_Collect_Permissions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.conf")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	require.NoError(t, os.Chmod(file, 0640))
+	require.NoError(t, os.Chmod(dir, 0750))
+
+	fc := New()
+	fc.Files.Include = []string{file}
+	fc.Files.Permissions = permsConfig{Mode: "0640", Owner: strconv.Itoa(os.Getuid())}
+	fc.Dirs.Include = []string{dir}
+	fc.Dirs.Permissions = permsConfig{Mode: "0700", Group: strconv.Itoa(os.Getgid() + 1)}
+	require.True(t, fc.Init())
+
+	collected := fc.Collect()
+
+	assert.Equal(t, int64(1), collected[fileDimID(file, "mode_compliant")])
+	assert.Equal(t, int64(1), collected[fileDimID(file, "owner_compliant")])
+	assert.Equal(t, int64(0), collected[dirDimID(dir, "mode_compliant")])
+	assert.Equal(t, int64(0), collected[dirDimID(dir, "owner_compliant")])
+
+	require.NoError(t, os.Chmod(file, 0666))
+	assert.Equal(t, int64(0), fc.Collect()[fileDimID(file, "mode_compliant")])
+}
+
+func TestFilecheck_Collect_OldestFileAge(t *testing.T) {
+	queue, empty := t.TempDir(), t.TempDir()
+	for i, age := range []time.Duration{time.Minute, time.Hour, time.Second} {
+		path := filepath.Join(queue, fmt.Sprintf("msg%d", i))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		mtime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(queue, "subdir"), 0755))
+
+	fc := New()
+	fc.Dirs.Include = []string{queue, empty}
+	fc.Dirs.CollectOldestFileAge = true
+	require.True(t, fc.Init())
+
+	collected := fc.Collect()
+
+	assert.InDelta(t, time.Hour.Seconds(), collected[dirDimID(queue, "oldest_file_age")], 5)
+	assert.Equal(t, int64(0), collected[dirDimID(empty, "oldest_file_age")])
+	assert.Equal(t, int64(4), collected[dirDimID(queue, "num_of_files")])
+}
+
 func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, fc *Filecheck, collected map[string]int64) {
 	// TODO: check other charts
 	for _, chart := range *fc.Charts() {
@@ -275,6 +432,27 @@ func prepareFilecheckGlobFiles() *Filecheck {
 	return fc
 }
 
+func prepareFilecheckGlobFilesWithExclude() *Filecheck {
+	fc := New()
+	fc.Config.Files.Include = []string{
+		"testdata/*.log",
+	}
+	fc.Config.Files.Exclude = []string{
+		"testdata/empty_*",
+	}
+	return fc
+}
+
+func prepareFilecheckFilesWithLineCount() *Filecheck {
+	fc := New()
+	fc.Config.Files.Include = []string{
+		"testdata/empty_file.log",
+		"testdata/file.log",
+	}
+	fc.Config.Files.CollectLineCount = true
+	return fc
+}
+
 func prepareFilecheckNonExistentFiles() *Filecheck {
 	fc := New()
 	fc.Config.Files.Include = []string{
@@ -4,6 +4,8 @@ package filecheck
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -12,6 +14,14 @@ func (fc Filecheck) validateConfig() error {
 	if len(fc.Files.Include) == 0 && len(fc.Dirs.Include) == 0 {
 		return errors.New("both 'files->include' and 'dirs->include' are empty")
 	}
+	if fc.Files.CollectChecksum && fc.Files.ChecksumMaxSize <= 0 {
+		return errors.New("'files->checksum_max_size' must be positive")
+	}
+	for _, pattern := range append(fc.Files.Exclude, fc.Dirs.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad exclude pattern '%s': %v", pattern, err)
+		}
+	}
 	return nil
 }
 
@@ -22,14 +32,45 @@ func (fc Filecheck) initCharts() (*module.Charts, error) {
 		if err := charts.Add(*fileCharts.Copy()...); err != nil {
 			return nil, err
 		}
+		var remove []string
+		if !fc.Files.CollectChecksum {
+			remove = append(remove, fileChecksumChangedChart.ID)
+		}
+		if !fc.Files.CollectLineCount {
+			remove = append(remove, fileLinesChart.ID)
+		}
+		if !fc.filesPerms.hasMode() {
+			remove = append(remove, fileModeComplianceChart.ID)
+		}
+		if !fc.filesPerms.hasOwner() {
+			remove = append(remove, fileOwnerComplianceChart.ID)
+		}
+		for _, id := range remove {
+			if err := charts.Remove(id); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(fc.Dirs.Include) > 0 {
 		if err := charts.Add(*dirCharts.Copy()...); err != nil {
 			return nil, err
 		}
+		var remove []string
 		if !fc.Dirs.CollectDirSize {
-			if err := charts.Remove(dirSizeChart.ID); err != nil {
+			remove = append(remove, dirSizeChart.ID)
+		}
+		if !fc.Dirs.CollectOldestFileAge {
+			remove = append(remove, dirOldestFileAgeChart.ID)
+		}
+		if !fc.dirsPerms.hasMode() {
+			remove = append(remove, dirModeComplianceChart.ID)
+		}
+		if !fc.dirsPerms.hasOwner() {
+			remove = append(remove, dirOwnerComplianceChart.ID)
+		}
+		for _, id := range remove {
+			if err := charts.Remove(id); err != nil {
 				return nil, err
 			}
 		}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package filecheck
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// permsCheck checks the file mode and ownership, the negative ids are not checked.
+type permsCheck struct {
+	mode    os.FileMode
+	modeSet bool
+	uid     int
+	gid     int
+}
+
+func newPermsCheck(cfg permsConfig) (*permsCheck, error) {
+	pc := &permsCheck{uid: -1, gid: -1}
+
+	if cfg.Mode != "" {
+		mode, err := strconv.ParseUint(cfg.Mode, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, fmt.Errorf("bad mode '%s', expected octal permission bits (e.g. '0640')", cfg.Mode)
+		}
+		pc.mode, pc.modeSet = os.FileMode(mode), true
+	}
+	if cfg.Owner != "" {
+		uid, err := lookupID(cfg.Owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("owner '%s': %v", cfg.Owner, err)
+		}
+		pc.uid = uid
+	}
+	if cfg.Group != "" {
+		gid, err := lookupID(cfg.Group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("group '%s': %v", cfg.Group, err)
+		}
+		pc.gid = gid
+	}
+	return pc, nil
+}
+
+// lookupID returns the numeric id, the name is looked up only if it is not a number.
+func lookupID(nameOrID string, lookup func(name string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+	id, err := lookup(nameOrID)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+func (pc *permsCheck) hasMode() bool {
+	return pc != nil && pc.modeSet
+}
+
+func (pc *permsCheck) hasOwner() bool {
+	return pc != nil && (pc.uid >= 0 || pc.gid >= 0)
+}
+
+func (pc *permsCheck) collect(ms map[string]int64, dimID func(metric string) string, info os.FileInfo) {
+	if pc.hasMode() {
+		ms[dimID("mode_compliant")] = boolToInt(info.Mode().Perm() == pc.mode)
+	}
+	if !pc.hasOwner() {
+		return
+	}
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return
+	}
+	compliant := (pc.uid < 0 || uid == pc.uid) && (pc.gid < 0 || gid == pc.gid)
+	ms[dimID("owner_compliant")] = boolToInt(compliant)
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
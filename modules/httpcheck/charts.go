@@ -26,6 +26,21 @@ var charts = Charts{
 			{ID: "time"},
 		},
 	},
+	{
+		ID:    "response_time_phases",
+		Title: "HTTP Response Time Phases",
+		Units: "ms",
+		Fam:   "response",
+		Ctx:   "httpcheck.response_time_phases",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "dns_lookup_time", Name: "dns", Div: 1000},
+			{ID: "connect_time", Name: "connect", Div: 1000},
+			{ID: "tls_handshake_time", Name: "tls", Div: 1000},
+			{ID: "first_byte_time", Name: "ttfb", Div: 1000},
+			{ID: "transfer_time", Name: "transfer", Div: 1000},
+		},
+	},
 	{
 		ID:    "response_length",
 		Title: "HTTP Response Body Length",
@@ -36,6 +51,28 @@ var charts = Charts{
 			{ID: "length"},
 		},
 	},
+	{
+		ID:    "response_size",
+		Title: "HTTP Response Size",
+		Units: "bytes",
+		Fam:   "response",
+		Ctx:   "httpcheck.response_size",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "header_size", Name: "header"},
+			{ID: "length", Name: "body"},
+		},
+	},
+	{
+		ID:    "redirects",
+		Title: "HTTP Redirects",
+		Units: "redirects",
+		Fam:   "response",
+		Ctx:   "httpcheck.redirects",
+		Dims: Dims{
+			{ID: "redirects"},
+		},
+	},
 	{
 		ID:    "request_status",
 		Title: "HTTP Check Status",
@@ -47,11 +84,12 @@ var charts = Charts{
 			{ID: "no_connection", Name: "no connection"},
 			{ID: "timeout"},
 			{ID: "bad_content", Name: "bad content"},
+			{ID: "bad_header", Name: "bad header"},
 			{ID: "bad_status", Name: "bad status"},
-			//{ID: "dns_lookup_error", Name: "dns lookup error"},
-			//{ID: "address_parse_error", Name: "address parse error"},
-			//{ID: "redirect_error", Name: "redirect error"},
-			//{ID: "body_read_error", Name: "body read error"},
+			{ID: "dns_lookup_error", Name: "dns lookup error"},
+			{ID: "address_parse_error", Name: "address parse error"},
+			{ID: "redirect_error", Name: "redirect error"},
+			{ID: "body_read_error", Name: "body read error"},
 		},
 	},
 	{
@@ -65,3 +103,61 @@ var charts = Charts{
 		},
 	},
 }
+
+// certExpiryChart is added on the first HTTPS response.
+var certExpiryChart = Chart{
+	ID:    "cert_expiry",
+	Title: "HTTPS Certificate Time Until Expiration",
+	Units: "days",
+	Fam:   "certificate",
+	Ctx:   "httpcheck.cert_expiry",
+	Dims: Dims{
+		{ID: "cert_expiry", Name: "time", Div: 86400},
+	},
+}
+
+var (
+	flowTimeChart = Chart{
+		ID:    "flow_time",
+		Title: "HTTP Flow Duration",
+		Units: "ms",
+		Fam:   "flow",
+		Ctx:   "httpcheck.flow_time",
+		Dims: Dims{
+			{ID: "flow_time", Name: "time", Div: 1000},
+		},
+	}
+	flowStepsTimeChart = Chart{
+		ID:    "flow_steps_time",
+		Title: "HTTP Flow Steps Response Time",
+		Units: "ms",
+		Fam:   "flow",
+		Ctx:   "httpcheck.flow_steps_time",
+		Type:  module.Stacked,
+	}
+	flowStepsStatusChart = Chart{
+		ID:    "flow_steps_status",
+		Title: "HTTP Flow Steps Status",
+		Units: "boolean",
+		Fam:   "flow",
+		Ctx:   "httpcheck.flow_steps_status",
+	}
+)
+
+// newFlowCharts returns the charts of a multi-step check: the flow status (the status of the first failed step)
+// and duration, and the response time and status of every step.
+func newFlowCharts(steps []*step) *Charts {
+	cs := &Charts{}
+	for _, id := range []string{"request_status", "current_state_duration"} {
+		_ = cs.Add(charts.Get(id).Copy())
+	}
+
+	stepsTime := flowStepsTimeChart.Copy()
+	stepsStatus := flowStepsStatusChart.Copy()
+	for _, s := range steps {
+		_ = stepsTime.AddDim(&module.Dim{ID: "step_" + s.name + "_time", Name: s.name, Div: 1000})
+		_ = stepsStatus.AddDim(&module.Dim{ID: "step_" + s.name + "_passed", Name: s.name})
+	}
+	_ = cs.Add(flowTimeChart.Copy(), stepsTime, stepsStatus)
+	return cs
+}
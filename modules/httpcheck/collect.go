@@ -3,10 +3,12 @@
 package httpcheck
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/stm"
@@ -17,18 +19,25 @@ type reqErrCode int
 
 const (
 	codeTimeout reqErrCode = iota
-	//codeDNSLookup
-	//codeParseAddress
-	//codeRedirect
+	codeDNSLookup
+	codeParseAddress
+	codeRedirect
 	codeNoConnection
 )
 
 func (hc *HTTPCheck) collect() (map[string]int64, error) {
+	if len(hc.steps) > 0 {
+		return hc.collectSteps()
+	}
+
 	req, err := web.NewHTTPRequest(hc.Request)
 	if err != nil {
 		return nil, fmt.Errorf("error on creating HTTP requests to %s : %v", hc.Request.URL, err)
 	}
 
+	trace := newRequestTrace(hc.now)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
 	var mx metrics
 
 	start := time.Now()
@@ -38,11 +47,14 @@ func (hc *HTTPCheck) collect() (map[string]int64, error) {
 
 	if err != nil {
 		hc.Warning(err)
-		hc.collectErrResponse(&mx, err)
+		setErrStatus(&mx.Status, err)
 	} else {
 		mx.ResponseTime = durationToMs(dur)
 		hc.collectOKResponse(&mx, resp)
 	}
+	mx.Phases = trace.done()
+	mx.Redirects = countRedirects(resp)
+	hc.collectCertExpiry(&mx, resp)
 
 	changed := hc.metrics.Status != mx.Status
 	if changed {
@@ -52,89 +64,174 @@ func (hc *HTTPCheck) collect() (map[string]int64, error) {
 	}
 	hc.metrics = mx
 
-	//if err == nil || mx.Status.RedirectError {
-	//	mx.ResponseTime = durationToMs(end)
-	//}
-
 	return stm.ToMap(mx), nil
 }
 
-func (hc HTTPCheck) collectErrResponse(mx *metrics, err error) {
+func setErrStatus(st *status, err error) {
 	switch code := decodeReqError(err); code {
 	default:
 		panic(fmt.Sprintf("unknown request error code : %d", code))
 	case codeNoConnection:
-		mx.Status.NoConnection = true
-	//case codeDNSLookup:
-	//	mx.Status.DNSLookupError = true
-	//case codeParseAddress:
-	//	mx.Status.ParseAddressError = true
-	//case codeRedirect:
-	//	mx.Status.RedirectError = true
+		st.NoConnection = true
+	case codeDNSLookup:
+		st.DNSLookupError = true
+	case codeParseAddress:
+		st.ParseAddressError = true
+	case codeRedirect:
+		st.RedirectError = true
 	case codeTimeout:
-		mx.Status.Timeout = true
+		st.Timeout = true
 	}
 }
 
 func (hc HTTPCheck) collectOKResponse(mx *metrics, resp *http.Response) {
-	if !hc.acceptedStatuses[resp.StatusCode] {
-		mx.Status.BadStatusCode = true
-		return
+	mx.HeaderSize = headerSize(resp.Header)
+
+	var bs []byte
+	bs, mx.Status = hc.checkResponse(hc.checker, resp)
+	mx.ResponseLength = len(bs)
+}
+
+// checkResponse reads the response body if the status code and the headers are accepted, and checks it.
+func (hc HTTPCheck) checkResponse(c *responseChecker, resp *http.Response) ([]byte, status) {
+	if !c.acceptedStatuses[resp.StatusCode] {
+		return nil, status{BadStatusCode: true}
+	}
+
+	if !hc.matchHeaders(c, resp.Header) {
+		return nil, status{BadHeader: true}
 	}
 
 	bs, err := io.ReadAll(resp.Body)
 	if err != nil && err != io.EOF {
 		hc.Warningf("error on reading body : %v", err)
-		mx.Status.BadContent = true
-		return
+		return bs, status{BodyReadError: true}
 	}
 
-	mx.ResponseLength = len(bs)
+	if c.reResponse != nil && !c.reResponse.Match(bs) {
+		return bs, status{BadContent: true}
+	}
 
-	if hc.reResponse != nil && !hc.reResponse.Match(bs) {
-		mx.Status.BadContent = true
-		return
+	if !hc.matchJSON(c, bs) {
+		return bs, status{BadContent: true}
 	}
 
-	mx.Status.Success = true
+	return bs, status{Success: true}
+}
+
+func (hc HTTPCheck) matchHeaders(c *responseChecker, header http.Header) bool {
+	for _, m := range c.headerMatchers {
+		values, ok := header[m.key]
+		if !ok {
+			hc.Debugf("header match: no '%s' header", m.key)
+			return false
+		}
+		if m.value == nil {
+			continue
+		}
+		if !anyMatch(values, m.value.MatchString) {
+			hc.Debugf("header match: '%s' header %v doesn't match", m.key, values)
+			return false
+		}
+	}
+	return true
+}
+
+func (hc HTTPCheck) matchJSON(c *responseChecker, body []byte) bool {
+	if len(c.jsonMatchers) == 0 {
+		return true
+	}
+	doc, err := decodeJSON(body)
+	if err != nil {
+		hc.Debugf("json match: error on decoding body : %v", err)
+		return false
+	}
+	for _, m := range c.jsonMatchers {
+		values := m.path.eval(doc)
+		if len(values) == 0 {
+			hc.Debugf("json match: '%s' selects nothing", m.expr)
+			return false
+		}
+		if m.value == nil {
+			continue
+		}
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = jsonValueString(v)
+		}
+		if !anyMatch(strs, m.value.MatchString) {
+			hc.Debugf("json match: '%s' values %v don't match", m.expr, strs)
+			return false
+		}
+	}
+	return true
+}
+
+func (hc *HTTPCheck) collectCertExpiry(mx *metrics, resp *http.Response) {
+	if resp == nil || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return
+	}
+	if hc.charts.Get(certExpiryChart.ID) == nil {
+		if err := hc.charts.Add(certExpiryChart.Copy()); err != nil {
+			hc.Warning(err)
+		}
+	}
+	v := int64(resp.TLS.PeerCertificates[0].NotAfter.Sub(hc.now()).Seconds())
+	mx.CertExpiry = &v
 }
 
 func decodeReqError(err error) reqErrCode {
 	if err == nil {
 		panic("nil error")
 	}
-	if v, ok := err.(net.Error); ok && v.Timeout() {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
 		return codeTimeout
 	}
+	if errors.Is(err, web.ErrRedirectAttempted) || errors.Is(err, errTooManyRedirects) {
+		return codeRedirect
+	}
+
+	var dnsErr *net.DNSError
+	var parseErr *net.ParseError
+	var addrErr *net.AddrError
+	switch {
+	case errors.As(err, &dnsErr):
+		return codeDNSLookup
+	case errors.As(err, &parseErr), errors.As(err, &addrErr):
+		return codeParseAddress
+	}
 	return codeNoConnection
-	//
-	//netErr, isNetErr := err.(net.Error)
-	//if isNetErr && netErr.Timeout() {
-	//	return codeTimeout
-	//}
-	//
-	//urlErr, isURLErr := err.(*url.Error)
-	//if !isURLErr {
-	//	return codeNoConnection
-	//}
-	//
-	//if urlErr.Err == web.ErrRedirectAttempted {
-	//	return codeRedirect
-	//}
-	//
-	//opErr, isOpErr := (urlErr.Err).(*net.OpError)
-	//if !isOpErr {
-	//	return codeNoConnection
-	//}
-	//
-	//switch (opErr.Err).(type) {
-	//case *net.DNSError:
-	//	return codeDNSLookup
-	//case *net.ParseError:
-	//	return codeParseAddress
-	//}
-	//
-	//return codeNoConnection
+}
+
+// countRedirects returns the number of redirects that led to the response.
+func countRedirects(resp *http.Response) int {
+	var n int
+	for resp != nil && resp.Request != nil && resp.Request.Response != nil {
+		n++
+		resp = resp.Request.Response
+	}
+	return n
+}
+
+// headerSize returns the size of the header fields in the HTTP/1.1 form ("Key: value\r\n").
+func headerSize(header http.Header) int {
+	var size int
+	for k, vs := range header {
+		for _, v := range vs {
+			size += len(k) + len(v) + 4
+		}
+	}
+	return size
+}
+
+func anyMatch(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
 }
 
 func closeBody(resp *http.Response) {
@@ -4,7 +4,6 @@ package httpcheck
 
 import (
 	"net/http"
-	"regexp"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/web"
@@ -26,6 +25,7 @@ func init() {
 var (
 	defaultHTTPTimeout      = time.Second
 	defaultAcceptedStatuses = []int{200}
+	defaultMaxRedirects     = 10
 )
 
 // New creates HTTPCheck with default values.
@@ -37,19 +37,40 @@ func New() *HTTPCheck {
 			},
 		},
 		AcceptedStatuses: defaultAcceptedStatuses,
+		MaxRedirects:     defaultMaxRedirects,
 	}
 	return &HTTPCheck{
-		Config:           config,
-		acceptedStatuses: make(map[int]bool),
+		Config: config,
+		charts: charts.Copy(),
+		now:    time.Now,
 	}
 }
 
-// Config is the HTTPCheck module configuration.
-type Config struct {
-	web.HTTP         `yaml:",inline"`
-	AcceptedStatuses []int  `yaml:"status_accepted"`
-	ResponseMatch    string `yaml:"response_match"`
-}
+type (
+	// Config is the HTTPCheck module configuration.
+	Config struct {
+		web.HTTP         `yaml:",inline"`
+		AcceptedStatuses []int               `yaml:"status_accepted"`
+		ResponseMatch    string              `yaml:"response_match"`
+		HeaderMatch      []HeaderMatchConfig `yaml:"header_match"`
+		JSONMatch        []JSONMatchConfig   `yaml:"json_match"`
+		MaxRedirects     int                 `yaml:"max_redirects"`
+		Steps            []StepConfig        `yaml:"steps"`
+		Variables        map[string]string   `yaml:"variables"`
+	}
+	// HeaderMatchConfig asserts the response header value, the value is a pkg/matcher expression.
+	// If the value is not set, the header must be present.
+	HeaderMatchConfig struct {
+		Key   string `yaml:"key"`
+		Value string `yaml:"value"`
+	}
+	// JSONMatchConfig asserts the value the JSONPath expression selects in the response body,
+	// the value is a pkg/matcher expression. If the value is not set, the path must exist.
+	JSONMatchConfig struct {
+		Path  string `yaml:"path"`
+		Value string `yaml:"value"`
+	}
+)
 
 type client interface {
 	Do(*http.Request) (*http.Response, error)
@@ -60,10 +81,14 @@ type HTTPCheck struct {
 	Config      `yaml:",inline"`
 	UpdateEvery int `yaml:"update_every"`
 
-	acceptedStatuses map[int]bool
-	reResponse       *regexp.Regexp
-	client           client
-	metrics          metrics
+	charts *Charts
+
+	checker    *responseChecker
+	steps      []*step
+	client     client
+	httpClient *http.Client
+	metrics    metrics
+	now        func() time.Time
 }
 
 // Cleanup makes cleanup.
@@ -71,36 +96,45 @@ func (HTTPCheck) Cleanup() {}
 
 // Init makes initialization
 func (hc *HTTPCheck) Init() bool {
-	if hc.URL == "" {
-		hc.Error("URL not set")
+	if err := hc.validateConfig(); err != nil {
+		hc.Errorf("config validation: %v", err)
 		return false
 	}
 
-	client, err := web.NewHTTPClient(hc.Client)
+	client, err := hc.initHTTPClient()
 	if err != nil {
 		hc.Errorf("error on creating HTTP client : %v", err)
 		return false
 	}
 	hc.client = client
+	hc.httpClient = client
 
-	if hc.ResponseMatch != "" {
-		re, err := regexp.Compile(hc.ResponseMatch)
+	if len(hc.Steps) > 0 {
+		steps, err := hc.initSteps()
 		if err != nil {
-			hc.Errorf("error on creating regexp %s : %s", hc.ResponseMatch, err)
+			hc.Errorf("error on creating steps : %v", err)
 			return false
 		}
-		hc.reResponse = re
+		hc.steps = steps
+		hc.charts = newFlowCharts(steps)
+
+		hc.Debugf("using %d steps", len(hc.steps))
+		hc.Debugf("using HTTP timeout %s", hc.Timeout.Duration)
+		return true
 	}
 
-	for _, v := range hc.AcceptedStatuses {
-		hc.acceptedStatuses[v] = true
+	checker, err := newResponseChecker(hc.AcceptedStatuses, hc.ResponseMatch, hc.HeaderMatch, hc.JSONMatch)
+	if err != nil {
+		hc.Errorf("error on creating response checker : %v", err)
+		return false
 	}
+	hc.checker = checker
 
 	hc.Debugf("using URL %s", hc.URL)
 	hc.Debugf("using HTTP timeout %s", hc.Timeout.Duration)
 	hc.Debugf("using accepted HTTP statuses %v", hc.AcceptedStatuses)
-	if hc.reResponse != nil {
-		hc.Debugf("using response match regexp %s", hc.reResponse)
+	if hc.checker.reResponse != nil {
+		hc.Debugf("using response match regexp %s", hc.checker.reResponse)
 	}
 
 	return true
@@ -110,7 +144,7 @@ func (hc *HTTPCheck) Init() bool {
 func (hc *HTTPCheck) Check() bool { return len(hc.Collect()) > 0 }
 
 // Charts creates Charts
-func (hc HTTPCheck) Charts() *Charts { return charts.Copy() }
+func (hc *HTTPCheck) Charts() *Charts { return hc.charts }
 
 // Collect collects metrics
 func (hc *HTTPCheck) Collect() map[string]int64 {
@@ -5,10 +5,15 @@ package httpcheck
 import (
 	"bytes"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/stretchr/testify/assert"
@@ -99,51 +104,51 @@ func TestHTTPCheck_Collect_TimeoutError(t *testing.T) {
 
 }
 
-//func TestHTTPCheck_Collect_DNSLookupError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.DNSError{}}})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{DNSLookupError: true}}),
-//		job.Collect(),
-//	)
-//}
-
-//func TestHTTPCheck_Collect_AddressParseError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.ParseError{}}})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{ParseAddressError: true}}),
-//		job.Collect(),
-//	)
-//
-//}
-
-//func TestHTTPCheck_Collect_RedirectError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: web.ErrRedirectAttempted})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{RedirectError: true}}),
-//		job.Collect(),
-//	)
-//}
+func TestHTTPCheck_Collect_DNSLookupError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.DNSError{}}})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{DNSLookupError: true}}),
+		job.Collect(),
+	)
+}
+
+func TestHTTPCheck_Collect_AddressParseError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.ParseError{}}})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{ParseAddressError: true}}),
+		job.Collect(),
+	)
+
+}
+
+func TestHTTPCheck_Collect_RedirectError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: web.ErrRedirectAttempted})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{RedirectError: true}}),
+		job.Collect(),
+	)
+}
 
 func TestHTTPCheck_Collect_BadContentError(t *testing.T) {
 	job := New()
@@ -245,6 +250,169 @@ func TestHTTPCheck_Collect_InState(t *testing.T) {
 	)
 }
 
+func TestHTTPCheck_Init_Matchers(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"valid matchers": {
+			config: Config{
+				HeaderMatch: []HeaderMatchConfig{{Key: "content-type", Value: "* application/json*"}, {Key: "X-Id"}},
+				JSONMatch:   []JSONMatchConfig{{Path: "$.status", Value: "= ok"}, {Path: "$.items[0]"}},
+			},
+		},
+		"fail when header match 'key' not set": {
+			wantFail: true,
+			config:   Config{HeaderMatch: []HeaderMatchConfig{{Value: "= a"}}},
+		},
+		"fail when header match 'value' is invalid": {
+			wantFail: true,
+			config:   Config{HeaderMatch: []HeaderMatchConfig{{Key: "a", Value: "~ ("}}},
+		},
+		"fail when json match 'path' is invalid": {
+			wantFail: true,
+			config:   Config{JSONMatch: []JSONMatchConfig{{Path: "status"}}},
+		},
+		"fail when json match 'value' is invalid": {
+			wantFail: true,
+			config:   Config{JSONMatch: []JSONMatchConfig{{Path: "$.status", Value: "~ ("}}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.URL = testURL
+			job.HeaderMatch = test.config.HeaderMatch
+			job.JSONMatch = test.config.JSONMatch
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestHTTPCheck_Collect_Server(t *testing.T) {
+	body := `{"status": "ok", "items": [{"id": 1}, {"id": 2}]}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusFound)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	})
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := map[string]struct {
+		prepare    func(job *HTTPCheck)
+		wantStatus status
+		redirects  int64
+	}{
+		"success": {
+			prepare: func(job *HTTPCheck) {
+				job.HeaderMatch = []HeaderMatchConfig{{Key: "Content-Type", Value: "* application/json*"}}
+				job.JSONMatch = []JSONMatchConfig{{Path: "$.status", Value: "= ok"}, {Path: "$.items[*].id", Value: "= 2"}}
+			},
+			wantStatus: status{Success: true},
+			redirects:  2,
+		},
+		"header doesn't match": {
+			prepare: func(job *HTTPCheck) {
+				job.HeaderMatch = []HeaderMatchConfig{{Key: "Content-Type", Value: "* text/html*"}}
+			},
+			wantStatus: status{BadHeader: true},
+			redirects:  2,
+		},
+		"header is missing": {
+			prepare: func(job *HTTPCheck) {
+				job.HeaderMatch = []HeaderMatchConfig{{Key: "X-Request-Id"}}
+			},
+			wantStatus: status{BadHeader: true},
+			redirects:  2,
+		},
+		"json value doesn't match": {
+			prepare: func(job *HTTPCheck) {
+				job.JSONMatch = []JSONMatchConfig{{Path: "$.status", Value: "= degraded"}}
+			},
+			wantStatus: status{BadContent: true},
+			redirects:  2,
+		},
+		"json path selects nothing": {
+			prepare: func(job *HTTPCheck) {
+				job.JSONMatch = []JSONMatchConfig{{Path: "$.items[5].id"}}
+			},
+			wantStatus: status{BadContent: true},
+			redirects:  2,
+		},
+		"too many redirects": {
+			prepare: func(job *HTTPCheck) {
+				job.MaxRedirects = 1
+			},
+			wantStatus: status{RedirectError: true},
+			redirects:  1,
+		},
+		"redirects not followed": {
+			prepare: func(job *HTTPCheck) {
+				job.NotFollowRedirect = true
+			},
+			wantStatus: status{RedirectError: true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.URL = srv.URL + "/start"
+			job.InsecureSkipVerify = true
+			job.UpdateEvery = 5
+			test.prepare(job)
+			require.True(t, job.Init())
+
+			mx := job.Collect()
+			require.NotNil(t, mx)
+
+			wantStatus := stm.ToMap(test.wantStatus)
+			for k, v := range wantStatus {
+				assert.Equalf(t, v, mx[k], "status '%s'", k)
+			}
+			assert.Equal(t, test.redirects, mx["redirects"])
+
+			if test.wantStatus.RedirectError {
+				return
+			}
+			assert.Greater(t, mx["connect_time"], int64(0))
+			assert.Greater(t, mx["tls_handshake_time"], int64(0))
+			assert.Greater(t, mx["first_byte_time"], int64(0))
+			assert.Greater(t, mx["header_size"], int64(0))
+			assert.Greater(t, mx["cert_expiry"], int64(0))
+			assert.NotNil(t, job.Charts().Get(certExpiryChart.ID))
+			if test.wantStatus.Success {
+				assert.Equal(t, int64(len(body)), mx["length"])
+			}
+			ensureCollectedHasAllChartsDimsVarsIDs(t, job, mx)
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, hc *HTTPCheck, mx map[string]int64) {
+	for _, chart := range *hc.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+	}
+}
+
 type clientFunc func(r *http.Request) (*http.Response, error)
 
 func (f clientFunc) Do(r *http.Request) (*http.Response, error) { return f(r) }
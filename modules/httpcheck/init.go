@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+var errTooManyRedirects = errors.New("too many redirects")
+
+type (
+	// responseChecker checks the response status code, headers and body.
+	responseChecker struct {
+		acceptedStatuses map[int]bool
+		reResponse       *regexp.Regexp
+		headerMatchers   []headerMatcher
+		jsonMatchers     []jsonMatcher
+	}
+	headerMatcher struct {
+		key   string
+		value matcher.Matcher // nil means the header must be present
+	}
+	jsonMatcher struct {
+		expr  string
+		path  jsonPath
+		value matcher.Matcher // nil means the path must exist
+	}
+)
+
+func (hc HTTPCheck) validateConfig() error {
+	if len(hc.Steps) > 0 {
+		if hc.URL != "" {
+			return errors.New("'url' and 'steps' are mutually exclusive")
+		}
+		return nil
+	}
+	if hc.URL == "" {
+		return errors.New("'url' or 'steps' is required but not set")
+	}
+	if _, err := web.NewHTTPRequest(hc.Request); err != nil {
+		return fmt.Errorf("error on creating HTTP request to %s : %v", hc.URL, err)
+	}
+	return nil
+}
+
+func (hc HTTPCheck) initHTTPClient() (*http.Client, error) {
+	client, err := web.NewHTTPClient(hc.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	// a kept alive connection would have the DNS lookup, connect and TLS handshake phases measured only once
+	if t, ok := client.Transport.(*http.Transport); ok {
+		t.DisableKeepAlives = true
+	}
+
+	if !hc.NotFollowRedirect {
+		maxRedirects := hc.MaxRedirects
+		client.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return errTooManyRedirects
+			}
+			return nil
+		}
+	}
+	return client, nil
+}
+
+func newResponseChecker(statuses []int, responseMatch string, headerMatch []HeaderMatchConfig,
+	jsonMatch []JSONMatchConfig) (*responseChecker, error) {
+	c := &responseChecker{acceptedStatuses: make(map[int]bool)}
+
+	for _, v := range statuses {
+		c.acceptedStatuses[v] = true
+	}
+
+	if responseMatch != "" {
+		re, err := regexp.Compile(responseMatch)
+		if err != nil {
+			return nil, fmt.Errorf("error on creating regexp %s : %s", responseMatch, err)
+		}
+		c.reResponse = re
+	}
+
+	var err error
+	if c.headerMatchers, err = newHeaderMatchers(headerMatch); err != nil {
+		return nil, err
+	}
+	if c.jsonMatchers, err = newJSONMatchers(jsonMatch); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newHeaderMatchers(configs []HeaderMatchConfig) ([]headerMatcher, error) {
+	var ms []headerMatcher
+	for _, cfg := range configs {
+		if cfg.Key == "" {
+			return nil, errors.New("header match 'key' not set")
+		}
+		m := headerMatcher{key: http.CanonicalHeaderKey(cfg.Key)}
+		if cfg.Value != "" {
+			v, err := matcher.Parse(cfg.Value)
+			if err != nil {
+				return nil, fmt.Errorf("header match '%s': %v", cfg.Key, err)
+			}
+			m.value = v
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+func newJSONMatchers(configs []JSONMatchConfig) ([]jsonMatcher, error) {
+	var ms []jsonMatcher
+	for _, cfg := range configs {
+		if cfg.Path == "" {
+			return nil, errors.New("json match 'path' not set")
+		}
+		path, err := parseJSONPath(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		m := jsonMatcher{expr: cfg.Path, path: path}
+		if cfg.Value != "" {
+			v, err := matcher.Parse(cfg.Value)
+			if err != nil {
+				return nil, fmt.Errorf("json match '%s': %v", cfg.Path, err)
+			}
+			m.value = v
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath expression. Only a subset is supported: the root '$',
+// child members ('.name', "['name']"), array indexes ('[0]', '[-1]' counts from the end)
+// and wildcards ('.*', '[*]').
+type jsonPath []jsonPathStep
+
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("jsonpath '%s': must start with '$'", expr)
+	}
+	s = s[1:]
+
+	var path jsonPath
+	for s != "" {
+		var step jsonPathStep
+		var err error
+		switch s[0] {
+		case '.':
+			step, s, err = parseJSONPathMember(s[1:])
+		case '[':
+			step, s, err = parseJSONPathBracket(s[1:])
+		default:
+			err = fmt.Errorf("unexpected '%c'", s[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath '%s': %v", expr, err)
+		}
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+func parseJSONPathMember(s string) (jsonPathStep, string, error) {
+	i := strings.IndexAny(s, ".[")
+	if i == -1 {
+		i = len(s)
+	}
+	name := s[:i]
+	if name == "" {
+		return jsonPathStep{}, "", errors.New("empty member name (recursive descent is not supported)")
+	}
+	if name == "*" {
+		return jsonPathStep{wildcard: true}, s[i:], nil
+	}
+	return jsonPathStep{key: name}, s[i:], nil
+}
+
+func parseJSONPathBracket(s string) (jsonPathStep, string, error) {
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		end := strings.IndexByte(s[1:], quote)
+		if end == -1 || !strings.HasPrefix(s[end+2:], "]") {
+			return jsonPathStep{}, "", errors.New("unterminated quoted member name")
+		}
+		return jsonPathStep{key: s[1 : end+1]}, s[end+3:], nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end == -1 {
+		return jsonPathStep{}, "", errors.New("missing ']'")
+	}
+	v := strings.TrimSpace(s[:end])
+	if v == "*" {
+		return jsonPathStep{wildcard: true}, s[end+1:], nil
+	}
+	index, err := strconv.Atoi(v)
+	if err != nil {
+		return jsonPathStep{}, "", fmt.Errorf("invalid array index '%s'", v)
+	}
+	return jsonPathStep{index: index, isIndex: true}, s[end+1:], nil
+}
+
+// eval returns the values the path selects in the decoded JSON document.
+func (p jsonPath) eval(doc interface{}) []interface{} {
+	values := []interface{}{doc}
+	for _, step := range p {
+		var next []interface{}
+		for _, v := range values {
+			next = append(next, step.eval(v)...)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+	return values
+}
+
+func (s jsonPathStep) eval(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			var values []interface{}
+			for _, key := range sortedKeys(v) {
+				values = append(values, v[key])
+			}
+			return values
+		}
+		if s.isIndex {
+			return nil
+		}
+		if value, ok := v[s.key]; ok {
+			return []interface{}{value}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return v
+		}
+		if !s.isIndex {
+			return nil
+		}
+		i := s.index
+		if i < 0 {
+			i += len(v)
+		}
+		if i >= 0 && i < len(v) {
+			return []interface{}{v[i]}
+		}
+	}
+	return nil
+}
+
+// jsonValueString returns the value as a string: strings as is, other values as JSON.
+func jsonValueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	bs, _ := json.Marshal(v)
+	return string(bs)
+}
+
+func decodeJSON(bs []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
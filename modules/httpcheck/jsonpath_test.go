@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	doc, err := decodeJSON([]byte(`{
+  "status": "ok",
+  "count": 2,
+  "ready": true,
+  "data": {"items": [{"id": 1, "tags": ["a"]}, {"id": 2.5, "tags": []}], "content-type": "json"}
+}`))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path     string
+		wantFail bool
+		want     []string
+	}{
+		"root":                {path: "$", want: []string{`{"count":2,"data":{"content-type":"json","items":[{"id":1,"tags":["a"]},{"id":2.5,"tags":[]}]},"ready":true,"status":"ok"}`}},
+		"string member":       {path: "$.status", want: []string{"ok"}},
+		"number member":       {path: "$.count", want: []string{"2"}},
+		"bool member":         {path: "$.ready", want: []string{"true"}},
+		"bracket member":      {path: "$.data['content-type']", want: []string{"json"}},
+		"array index":         {path: "$.data.items[1].id", want: []string{"2.5"}},
+		"negative index":      {path: "$.data.items[-1].id", want: []string{"2.5"}},
+		"wildcard index":      {path: "$.data.items[*].id", want: []string{"1", "2.5"}},
+		"wildcard member":     {path: "$.data.*", want: []string{"json", `[{"id":1,"tags":["a"]},{"id":2.5,"tags":[]}]`}},
+		"not existing member": {path: "$.data.nothing"},
+		"index out of range":  {path: "$.data.items[2]"},
+		"index of an object":  {path: "$.data[0]"},
+		"fail without root":   {path: "data.items", wantFail: true},
+		"fail on recursion":   {path: "$..id", wantFail: true},
+		"fail on bad index":   {path: "$.data.items[x]", wantFail: true},
+		"fail on unclosed":    {path: "$.data['items", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseJSONPath(test.path)
+			if test.wantFail {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, v := range path.eval(doc) {
+				got = append(got, jsonValueString(v))
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -7,16 +7,30 @@ type metrics struct {
 	InState        int    `stm:"in_state"`
 	ResponseTime   int    `stm:"time"`
 	ResponseLength int    `stm:"length"`
+	HeaderSize     int    `stm:"header_size"`
+	Phases         phases `stm:""`
+	Redirects      int    `stm:"redirects"`
+	CertExpiry     *int64 `stm:"cert_expiry"` // seconds until the server certificate expires, HTTPS only
 }
 
 type status struct {
-	Success bool `stm:"success"` // No error on request, body reading and checking its content
-	Timeout bool `stm:"timeout"`
-	//DNSLookupError    bool `stm:"dns_lookup_error"`
-	//ParseAddressError bool `stm:"address_parse_error"`
-	//RedirectError     bool `stm:"redirect_error"`
-	//BodyReadError     bool `stm:"body_read_error"`
-	BadContent    bool `stm:"bad_content"`
-	BadStatusCode bool `stm:"bad_status"`
-	NoConnection  bool `stm:"no_connection"` // All other errors basically
+	Success           bool `stm:"success"` // No error on request, body reading and checking its content
+	Timeout           bool `stm:"timeout"`
+	DNSLookupError    bool `stm:"dns_lookup_error"`
+	ParseAddressError bool `stm:"address_parse_error"`
+	RedirectError     bool `stm:"redirect_error"`
+	BodyReadError     bool `stm:"body_read_error"`
+	BadContent        bool `stm:"bad_content"`
+	BadHeader         bool `stm:"bad_header"`
+	BadStatusCode     bool `stm:"bad_status"`
+	NoConnection      bool `stm:"no_connection"` // All other errors basically
+}
+
+// phases are the request phases durations in microseconds, summed over the redirects.
+type phases struct {
+	DNSLookup    int64 `stm:"dns_lookup_time"`
+	Connect      int64 `stm:"connect_time"`
+	TLSHandshake int64 `stm:"tls_handshake_time"`
+	FirstByte    int64 `stm:"first_byte_time"`
+	Transfer     int64 `stm:"transfer_time"`
 }
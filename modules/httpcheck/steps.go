@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+type (
+	// StepConfig is a request of a multi-step check. The URL, body, header values and credentials
+	// can reference variables as ${name}.
+	StepConfig struct {
+		Name             string `yaml:"name"`
+		web.Request      `yaml:",inline"`
+		AcceptedStatuses []int               `yaml:"status_accepted"`
+		ResponseMatch    string              `yaml:"response_match"`
+		HeaderMatch      []HeaderMatchConfig `yaml:"header_match"`
+		JSONMatch        []JSONMatchConfig   `yaml:"json_match"`
+		Extract          []ExtractConfig     `yaml:"extract"`
+	}
+	// ExtractConfig sets the variable to a value of the step response, one of JSONPath, Regexp
+	// (the first group if any, otherwise the whole match) and Header must be set.
+	ExtractConfig struct {
+		Name     string `yaml:"name"`
+		JSONPath string `yaml:"json_path"`
+		Regexp   string `yaml:"regexp"`
+		Header   string `yaml:"header"`
+	}
+)
+
+type (
+	step struct {
+		name     string
+		request  web.Request
+		checker  *responseChecker
+		extracts []extractor
+	}
+	extractor struct {
+		name   string
+		path   jsonPath
+		re     *regexp.Regexp
+		header string
+	}
+)
+
+var reVariable = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)}`)
+
+func (hc HTTPCheck) initSteps() ([]*step, error) {
+	defined := make(map[string]bool)
+	for name := range hc.Variables {
+		defined[name] = true
+	}
+	seen := make(map[string]bool)
+
+	var steps []*step
+	for i, cfg := range hc.Steps {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("step%d", i+1)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("step '%s' is duplicated", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		s, err := newStep(cfg, hc.AcceptedStatuses)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %v", cfg.Name, err)
+		}
+		for _, name := range s.variables() {
+			if !defined[name] {
+				return nil, fmt.Errorf("step '%s': variable '%s' is not defined in 'variables' or extracted by a previous step", s.name, name)
+			}
+		}
+		for _, e := range s.extracts {
+			defined[e.name] = true
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func newStep(cfg StepConfig, defaultStatuses []int) (*step, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("'url' not set")
+	}
+
+	statuses := cfg.AcceptedStatuses
+	if len(statuses) == 0 {
+		statuses = defaultStatuses
+	}
+	checker, err := newResponseChecker(statuses, cfg.ResponseMatch, cfg.HeaderMatch, cfg.JSONMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &step{name: cfg.Name, request: cfg.Request.Copy(), checker: checker}
+	for _, ecfg := range cfg.Extract {
+		e, err := newExtractor(ecfg)
+		if err != nil {
+			return nil, err
+		}
+		s.extracts = append(s.extracts, e)
+	}
+	return s, nil
+}
+
+func newExtractor(cfg ExtractConfig) (extractor, error) {
+	if cfg.Name == "" {
+		return extractor{}, errors.New("extract 'name' not set")
+	}
+
+	e := extractor{name: cfg.Name, header: http.CanonicalHeaderKey(cfg.Header)}
+	var n int
+	if cfg.JSONPath != "" {
+		n++
+		path, err := parseJSONPath(cfg.JSONPath)
+		if err != nil {
+			return extractor{}, fmt.Errorf("extract '%s': %v", cfg.Name, err)
+		}
+		e.path = path
+	}
+	if cfg.Regexp != "" {
+		n++
+		re, err := regexp.Compile(cfg.Regexp)
+		if err != nil {
+			return extractor{}, fmt.Errorf("extract '%s': %v", cfg.Name, err)
+		}
+		e.re = re
+	}
+	if cfg.Header != "" {
+		n++
+	}
+	if n != 1 {
+		return extractor{}, fmt.Errorf("extract '%s': one of 'json_path', 'regexp' and 'header' must be set", cfg.Name)
+	}
+	return e, nil
+}
+
+// variables returns the names of the variables the step request references.
+func (s *step) variables() []string {
+	var names []string
+	for _, v := range s.templates() {
+		for _, m := range reVariable.FindAllStringSubmatch(v, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+func (s *step) templates() []string {
+	vs := []string{s.request.URL, s.request.Body, s.request.Username, s.request.Password}
+	for _, v := range s.request.Headers {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// newRequest returns the step request with the variables substituted.
+func (s *step) newRequest(vars map[string]string) (*http.Request, error) {
+	expand := func(v string) string {
+		return reVariable.ReplaceAllStringFunc(v, func(m string) string {
+			return vars[reVariable.FindStringSubmatch(m)[1]]
+		})
+	}
+
+	r := s.request.Copy()
+	r.URL = expand(r.URL)
+	r.Body = expand(r.Body)
+	r.Username = expand(r.Username)
+	r.Password = expand(r.Password)
+	for k, v := range r.Headers {
+		r.Headers[k] = expand(v)
+	}
+	return web.NewHTTPRequest(r)
+}
+
+// extract returns the value of the response, false if the response doesn't have it.
+func (e extractor) extract(resp *http.Response, body []byte) (string, bool) {
+	switch {
+	case e.header != "":
+		v := resp.Header.Get(e.header)
+		return v, v != ""
+	case e.re != nil:
+		m := e.re.FindSubmatch(body)
+		if m == nil {
+			return "", false
+		}
+		if len(m) > 1 {
+			return string(m[1]), true
+		}
+		return string(m[0]), true
+	default:
+		doc, err := decodeJSON(body)
+		if err != nil {
+			return "", false
+		}
+		values := e.path.eval(doc)
+		if len(values) == 0 {
+			return "", false
+		}
+		return jsonValueString(values[0]), true
+	}
+}
+
+// collectSteps runs the steps in order with a new cookie jar, the run stops at the first failed step.
+func (hc *HTTPCheck) collectSteps() (map[string]int64, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	client := *hc.httpClient
+	client.Jar = jar
+
+	vars := make(map[string]string, len(hc.Variables))
+	for k, v := range hc.Variables {
+		vars[k] = v
+	}
+
+	mx := make(map[string]int64)
+	for _, s := range hc.steps {
+		mx["step_"+s.name+"_time"] = 0
+		mx["step_"+s.name+"_passed"] = 0
+	}
+
+	var m metrics
+	start := time.Now()
+	for _, s := range hc.steps {
+		stepStart := time.Now()
+		m.Status = hc.runStep(&client, s, vars)
+		mx["step_"+s.name+"_time"] = time.Since(stepStart).Microseconds()
+		if !m.Status.Success {
+			break
+		}
+		mx["step_"+s.name+"_passed"] = 1
+	}
+	mx["flow_time"] = time.Since(start).Microseconds()
+
+	if hc.metrics.Status != m.Status {
+		m.InState = hc.UpdateEvery
+	} else {
+		m.InState = hc.metrics.InState + hc.UpdateEvery
+	}
+	hc.metrics = m
+
+	for k, v := range stm.ToMap(m.Status) {
+		mx[k] = v
+	}
+	mx["in_state"] = int64(m.InState)
+
+	return mx, nil
+}
+
+func (hc *HTTPCheck) runStep(client *http.Client, s *step, vars map[string]string) status {
+	req, err := s.newRequest(vars)
+	if err != nil {
+		hc.Warningf("step '%s': error on creating HTTP request : %v", s.name, err)
+		return status{ParseAddressError: true}
+	}
+
+	resp, err := client.Do(req)
+	defer closeBody(resp)
+	if err != nil {
+		hc.Warningf("step '%s': %v", s.name, err)
+		var st status
+		setErrStatus(&st, err)
+		return st
+	}
+
+	body, st := hc.checkResponse(s.checker, resp)
+	if !st.Success {
+		hc.Debugf("step '%s': check failed (status code %d)", s.name, resp.StatusCode)
+		return st
+	}
+
+	for _, e := range s.extracts {
+		v, ok := e.extract(resp, body)
+		if !ok {
+			hc.Debugf("step '%s': extract '%s': no value", s.name, e.name)
+			return status{BadContent: true}
+		}
+		vars[e.name] = v
+	}
+	return st
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCheck_Init_Steps(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success": {
+			config: Config{
+				Variables: map[string]string{"user": "netdata"},
+				Steps: []StepConfig{
+					{Name: "login", Request: web.Request{URL: "http://127.0.0.1/login?user=${user}"},
+						Extract: []ExtractConfig{{Name: "token", JSONPath: "$.token"}}},
+					{Request: web.Request{URL: "http://127.0.0.1/cart", Headers: map[string]string{"Authorization": "Bearer ${token}"}}},
+				},
+			},
+		},
+		"fail when both 'url' and 'steps' are set": {
+			wantFail: true,
+			config: Config{
+				HTTP:  web.HTTP{Request: web.Request{URL: testURL}},
+				Steps: []StepConfig{{Request: web.Request{URL: testURL}}},
+			},
+		},
+		"fail when step 'url' not set": {
+			wantFail: true,
+			config:   Config{Steps: []StepConfig{{Name: "login"}}},
+		},
+		"fail when step is duplicated": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Name: "login", Request: web.Request{URL: testURL}},
+				{Name: "login", Request: web.Request{URL: testURL}},
+			}},
+		},
+		"fail when variable is not defined": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Request: web.Request{URL: testURL, Body: `{"token": "${token}"}`}},
+			}},
+		},
+		"fail when variable is extracted by a later step": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Request: web.Request{URL: testURL + "/${id}"}},
+				{Request: web.Request{URL: testURL}, Extract: []ExtractConfig{{Name: "id", Regexp: `id=(\d+)`}}},
+			}},
+		},
+		"fail when extract has several sources": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Request: web.Request{URL: testURL}, Extract: []ExtractConfig{{Name: "id", Regexp: `\d+`, Header: "X-Id"}}},
+			}},
+		},
+		"fail when extract regexp is invalid": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Request: web.Request{URL: testURL}, Extract: []ExtractConfig{{Name: "id", Regexp: `(`}}},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.URL = test.config.URL
+			job.Steps = test.config.Steps
+			job.Variables = test.config.Variables
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestHTTPCheck_Collect_Steps(t *testing.T) {
+	srv := newShopServer()
+	defer srv.Close()
+
+	tests := map[string]struct {
+		password   string
+		wantStatus status
+		wantPassed map[string]int64
+	}{
+		"all steps pass": {
+			password:   "secret",
+			wantStatus: status{Success: true},
+			wantPassed: map[string]int64{"login": 1, "cart": 1, "checkout": 1},
+		},
+		"login fails": {
+			password:   "wrong",
+			wantStatus: status{BadStatusCode: true},
+			wantPassed: map[string]int64{"login": 0, "cart": 0, "checkout": 0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.UpdateEvery = 5
+			job.Variables = map[string]string{"password": test.password}
+			job.Steps = []StepConfig{
+				{
+					Name:    "login",
+					Request: web.Request{URL: srv.URL + "/login", Method: "POST", Body: `{"user": "netdata", "password": "${password}"}`},
+					Extract: []ExtractConfig{{Name: "token", JSONPath: "$.token"}},
+				},
+				{
+					Name:      "cart",
+					Request:   web.Request{URL: srv.URL + "/cart", Headers: map[string]string{"Authorization": "Bearer ${token}"}},
+					JSONMatch: []JSONMatchConfig{{Path: "$.items[*].id"}},
+					Extract:   []ExtractConfig{{Name: "item", JSONPath: "$.items[0].id"}},
+				},
+				{
+					Name:          "checkout",
+					Request:       web.Request{URL: srv.URL + "/checkout/${item}", Method: "POST"},
+					ResponseMatch: `order \d+ placed`,
+					Extract:       []ExtractConfig{{Name: "order", Regexp: `order (\d+)`}},
+				},
+			}
+			require.True(t, job.Init())
+
+			for i := 1; i <= 2; i++ {
+				mx := job.Collect()
+				require.NotNil(t, mx)
+
+				for k, v := range stm.ToMap(test.wantStatus) {
+					assert.Equalf(t, v, mx[k], "status '%s'", k)
+				}
+				for name, v := range test.wantPassed {
+					assert.Equalf(t, v, mx["step_"+name+"_passed"], "step '%s'", name)
+				}
+				assert.Equal(t, int64(job.UpdateEvery*i), mx["in_state"])
+				assert.Greater(t, mx["step_login_time"], int64(0))
+				assert.GreaterOrEqual(t, mx["flow_time"], mx["step_login_time"])
+				ensureCollectedHasAllChartsDimsVarsIDs(t, job, mx)
+			}
+		})
+	}
+}
+
+// newShopServer returns a server with a login, cart and checkout flow.
+// The session cookie set on login is required by the other endpoints, the cart also requires the login token.
+func newShopServer() *httptest.Server {
+	mux := http.NewServeMux()
+	hasSession := func(r *http.Request) bool {
+		c, err := r.Cookie("session")
+		return err == nil && c.Value == "s1"
+	}
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var creds struct{ User, Password string }
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&creds) != nil || creds.Password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		_, _ = w.Write([]byte(`{"token": "t1"}`))
+	})
+	mux.HandleFunc("/cart", func(w http.ResponseWriter, r *http.Request) {
+		if !hasSession(r) || r.Header.Get("Authorization") != "Bearer t1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [{"id": 42}]}`))
+	})
+	mux.HandleFunc("/checkout/42", func(w http.ResponseWriter, r *http.Request) {
+		if !hasSession(r) || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = fmt.Fprint(w, "order 1001 placed")
+	})
+	return httptest.NewServer(mux)
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpcheck
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTrace measures the request phases. The hooks are called for every request of the redirects chain,
+// the phases durations are summed. Dial attempts can run in parallel (RFC 6555), hence the lock.
+type requestTrace struct {
+	mux sync.Mutex
+	now func() time.Time
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	gotConn      time.Time
+	firstByte    time.Time
+
+	phases phases
+}
+
+func newRequestTrace(now func() time.Time) *requestTrace {
+	return &requestTrace{now: now}
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.dnsStart = t.now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.phases.DNSLookup += since(t.dnsStart, t.now())
+		},
+		ConnectStart: func(string, string) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = t.now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			if err == nil && !t.connectStart.IsZero() {
+				t.phases.Connect += since(t.connectStart, t.now())
+				t.connectStart = time.Time{}
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.tlsStart = t.now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.phases.TLSHandshake += since(t.tlsStart, t.now())
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.gotConn = t.now()
+		},
+		GotFirstResponseByte: func() {
+			t.mux.Lock()
+			defer t.mux.Unlock()
+			t.firstByte = t.now()
+			t.phases.FirstByte += since(t.gotConn, t.firstByte)
+		},
+	}
+}
+
+// done is called when the last response body is read, the transfer phase is of the last response only.
+func (t *requestTrace) done() phases {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.phases.Transfer = since(t.firstByte, t.now())
+	return t.phases
+}
+
+// since returns the duration in microseconds, zero if the start is not set.
+func since(start, now time.Time) int64 {
+	if start.IsZero() {
+		return 0
+	}
+	return now.Sub(start).Microseconds()
+}
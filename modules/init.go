@@ -47,6 +47,7 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/phpfpm"
 	_ "github.com/netdata/go.d.plugin/modules/pihole"
 	_ "github.com/netdata/go.d.plugin/modules/pika"
+	_ "github.com/netdata/go.d.plugin/modules/ping"
 	_ "github.com/netdata/go.d.plugin/modules/portcheck"
 	_ "github.com/netdata/go.d.plugin/modules/postgres"
 	_ "github.com/netdata/go.d.plugin/modules/powerdns"
@@ -57,6 +58,7 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/redis"
 	_ "github.com/netdata/go.d.plugin/modules/scaleio"
 	_ "github.com/netdata/go.d.plugin/modules/snmp"
+	_ "github.com/netdata/go.d.plugin/modules/snmp_traps"
 	_ "github.com/netdata/go.d.plugin/modules/solr"
 	_ "github.com/netdata/go.d.plugin/modules/springboot2"
 	_ "github.com/netdata/go.d.plugin/modules/squidlog"
@@ -4,6 +4,8 @@ package mongo
 
 import "time"
 
+//go:generate go run ../../cmd/stmgen -type=serverStatus
+
 const (
 	mongos = "mongos"
 )
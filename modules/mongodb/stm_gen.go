@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Code generated by stmgen. DO NOT EDIT.
+
+package mongo
+
+// WriteTo writes the 'stm' tagged fields of serverStatus into rv, it is the generated equivalent of stm.ToMap.
+func (s *serverStatus) WriteTo(rv map[string]int64) {
+	s.stmWriteTo(rv, "")
+}
+
+func (s *serverStatus) stmWriteTo(rv map[string]int64, key string) {
+	p := key
+	if p != "" {
+		p += "_"
+	}
+	if s.Opcounters.Insert != nil {
+		rv[p+"operations_insert"] = int64(*s.Opcounters.Insert)
+	}
+	if s.Opcounters.Query != nil {
+		rv[p+"operations_query"] = int64(*s.Opcounters.Query)
+	}
+	if s.Opcounters.Update != nil {
+		rv[p+"operations_update"] = int64(*s.Opcounters.Update)
+	}
+	if s.Opcounters.Delete != nil {
+		rv[p+"operations_delete"] = int64(*s.Opcounters.Delete)
+	}
+	if s.Opcounters.Getmore != nil {
+		rv[p+"operations_getmore"] = int64(*s.Opcounters.Getmore)
+	}
+	if s.Opcounters.Command != nil {
+		rv[p+"operations_command"] = int64(*s.Opcounters.Command)
+	}
+	if s.OpLatencies != nil {
+		if s.OpLatencies.Reads != nil {
+			if s.OpLatencies.Reads.Latency != nil {
+				rv[p+"operations_latency_read"] = int64(*s.OpLatencies.Reads.Latency)
+			}
+		}
+		if s.OpLatencies.Writes != nil {
+			if s.OpLatencies.Writes.Latency != nil {
+				rv[p+"operations_latency_write"] = int64(*s.OpLatencies.Writes.Latency)
+			}
+		}
+		if s.OpLatencies.Commands != nil {
+			if s.OpLatencies.Commands.Latency != nil {
+				rv[p+"operations_latency_command"] = int64(*s.OpLatencies.Commands.Latency)
+			}
+		}
+	}
+	if s.Connections.Current != nil {
+		rv[p+"connections_current"] = int64(*s.Connections.Current)
+	}
+	if s.Connections.Available != nil {
+		rv[p+"connections_available"] = int64(*s.Connections.Available)
+	}
+	if s.Connections.TotalCreated != nil {
+		rv[p+"connections_total_created"] = int64(*s.Connections.TotalCreated)
+	}
+	if s.Connections.Active != nil {
+		rv[p+"connections_active"] = int64(*s.Connections.Active)
+	}
+	if s.Connections.Threaded != nil {
+		rv[p+"connections_threaded"] = int64(*s.Connections.Threaded)
+	}
+	if s.Connections.ExhaustIsMaster != nil {
+		rv[p+"connections_exhaustIsMaster"] = int64(*s.Connections.ExhaustIsMaster)
+	}
+	if s.Connections.ExhaustHello != nil {
+		rv[p+"connections_exhaustHello"] = int64(*s.Connections.ExhaustHello)
+	}
+	if s.Connections.AwaitingTopologyChanges != nil {
+		rv[p+"connections_awaitingTopologyChanges"] = int64(*s.Connections.AwaitingTopologyChanges)
+	}
+	if s.Network.BytesIn != nil {
+		rv[p+"network_bytes_in"] = int64(*s.Network.BytesIn)
+	}
+	if s.Network.BytesOut != nil {
+		rv[p+"network_bytes_out"] = int64(*s.Network.BytesOut)
+	}
+	if s.Network.NumRequests != nil {
+		rv[p+"network_requests"] = int64(*s.Network.NumRequests)
+	}
+	if s.ExtraInfo.PageFaults != nil {
+		rv[p+"extra_info_page_faults"] = int64(*s.ExtraInfo.PageFaults)
+	}
+	if s.Asserts.Regular != nil {
+		rv[p+"asserts_regular"] = int64(*s.Asserts.Regular)
+	}
+	if s.Asserts.Warning != nil {
+		rv[p+"asserts_warning"] = int64(*s.Asserts.Warning)
+	}
+	if s.Asserts.Msg != nil {
+		rv[p+"asserts_msg"] = int64(*s.Asserts.Msg)
+	}
+	if s.Asserts.User != nil {
+		rv[p+"asserts_user"] = int64(*s.Asserts.User)
+	}
+	if s.Asserts.Tripwire != nil {
+		rv[p+"asserts_tripwire"] = int64(*s.Asserts.Tripwire)
+	}
+	if s.Asserts.Rollovers != nil {
+		rv[p+"asserts_rollovers"] = int64(*s.Asserts.Rollovers)
+	}
+	if s.Transactions != nil {
+		if s.Transactions.CurrentActive != nil {
+			rv[p+"transactions_active"] = int64(*s.Transactions.CurrentActive)
+		}
+		if s.Transactions.CurrentInactive != nil {
+			rv[p+"transactions_inactive"] = int64(*s.Transactions.CurrentInactive)
+		}
+		if s.Transactions.CurrentOpen != nil {
+			rv[p+"transactions_open"] = int64(*s.Transactions.CurrentOpen)
+		}
+		if s.Transactions.CurrentPrepared != nil {
+			rv[p+"transactions_prepared"] = int64(*s.Transactions.CurrentPrepared)
+		}
+		if s.Transactions.CommitTypes != nil {
+			rv[p+"transactions_commit_types_no_shards_initiated"] = int64(s.Transactions.CommitTypes.NoShards.Initiated)
+			rv[p+"transactions_commit_types_no_shards_successful"] = int64(s.Transactions.CommitTypes.NoShards.Successful)
+			rv[p+"transactions_commit_types_single_shard_initiated"] = int64(s.Transactions.CommitTypes.SingleShard.Initiated)
+			rv[p+"transactions_commit_types_single_shard_successful"] = int64(s.Transactions.CommitTypes.SingleShard.Successful)
+			rv[p+"transactions_commit_types_single_write_shard_initiated"] = int64(s.Transactions.CommitTypes.SingleWriteShard.Initiated)
+			rv[p+"transactions_commit_types_single_write_shard_successful"] = int64(s.Transactions.CommitTypes.SingleWriteShard.Successful)
+			rv[p+"transactions_commit_types_two_phase_initiated"] = int64(s.Transactions.CommitTypes.TwoPhaseCommit.Initiated)
+			rv[p+"transactions_commit_types_two_phase_successful"] = int64(s.Transactions.CommitTypes.TwoPhaseCommit.Successful)
+		}
+	}
+	if s.GlobalLock != nil {
+		if s.GlobalLock.ActiveClients != nil {
+			if s.GlobalLock.ActiveClients.Readers != nil {
+				rv[p+"glock_active_clients_readers"] = int64(*s.GlobalLock.ActiveClients.Readers)
+			}
+			if s.GlobalLock.ActiveClients.Writers != nil {
+				rv[p+"glock_active_clients_writers"] = int64(*s.GlobalLock.ActiveClients.Writers)
+			}
+		}
+		if s.GlobalLock.CurrentQueue != nil {
+			if s.GlobalLock.CurrentQueue.Readers != nil {
+				rv[p+"glock_current_queue_readers"] = int64(*s.GlobalLock.CurrentQueue.Readers)
+			}
+			if s.GlobalLock.CurrentQueue.Writers != nil {
+				rv[p+"glock_current_queue_writers"] = int64(*s.GlobalLock.CurrentQueue.Writers)
+			}
+		}
+	}
+	if s.Tcmalloc != nil {
+		if s.Tcmalloc.Generic != nil {
+			if s.Tcmalloc.Generic.CurrentAllocatedBytes != nil {
+				rv[p+"tcmalloc_generic_current_allocated"] = int64(*s.Tcmalloc.Generic.CurrentAllocatedBytes)
+			}
+			if s.Tcmalloc.Generic.HeapSize != nil {
+				rv[p+"tcmalloc_generic_heap_size"] = int64(*s.Tcmalloc.Generic.HeapSize)
+			}
+		}
+		if s.Tcmalloc.Tcmalloc != nil {
+			if s.Tcmalloc.Tcmalloc.PageheapFreeBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_free"] = int64(*s.Tcmalloc.Tcmalloc.PageheapFreeBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.PageheapUnmappedBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_unmapped"] = int64(*s.Tcmalloc.Tcmalloc.PageheapUnmappedBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.MaxTotalThreadCacheBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_max_total_thread_cache"] = int64(*s.Tcmalloc.Tcmalloc.MaxTotalThreadCacheBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.TotalFreeBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_total_free"] = int64(*s.Tcmalloc.Tcmalloc.TotalFreeBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.PageheapCommittedBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_committed"] = int64(*s.Tcmalloc.Tcmalloc.PageheapCommittedBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.PageheapTotalCommitBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_total_commit"] = int64(*s.Tcmalloc.Tcmalloc.PageheapTotalCommitBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.PageheapTotalDecommitBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_total_decommit"] = int64(*s.Tcmalloc.Tcmalloc.PageheapTotalDecommitBytes)
+			}
+			if s.Tcmalloc.Tcmalloc.PageheapTotalReserveBytes != nil {
+				rv[p+"tcmalloc_tcmalloc_pageheap_total_reserve"] = int64(*s.Tcmalloc.Tcmalloc.PageheapTotalReserveBytes)
+			}
+		}
+	}
+	if s.Locks != nil {
+		if s.Locks.Global != nil {
+			if s.Locks.Global.AcquireCount.R != nil {
+				rv[p+"locks_global_read"] = int64(*s.Locks.Global.AcquireCount.R)
+			}
+			if s.Locks.Global.AcquireCount.W != nil {
+				rv[p+"locks_global_write"] = int64(*s.Locks.Global.AcquireCount.W)
+			}
+		}
+		if s.Locks.Database != nil {
+			if s.Locks.Database.AcquireCount.R != nil {
+				rv[p+"locks_database_read"] = int64(*s.Locks.Database.AcquireCount.R)
+			}
+			if s.Locks.Database.AcquireCount.W != nil {
+				rv[p+"locks_database_write"] = int64(*s.Locks.Database.AcquireCount.W)
+			}
+		}
+		if s.Locks.Collection != nil {
+			if s.Locks.Collection.AcquireCount.R != nil {
+				rv[p+"locks_collection_read"] = int64(*s.Locks.Collection.AcquireCount.R)
+			}
+			if s.Locks.Collection.AcquireCount.W != nil {
+				rv[p+"locks_collection_write"] = int64(*s.Locks.Collection.AcquireCount.W)
+			}
+		}
+	}
+	if s.FlowControl != nil {
+		if s.FlowControl.TargetRateLimit != nil {
+			rv[p+"flow_target_rate_limit"] = int64(*s.FlowControl.TargetRateLimit)
+		}
+		if s.FlowControl.TimeAcquiringMicros != nil {
+			rv[p+"flow_time_acquiring_micros"] = int64(*s.FlowControl.TimeAcquiringMicros)
+		}
+	}
+	if s.WiredTiger != nil {
+		if s.WiredTiger.BlockManager != nil {
+			rv[p+"wiredtiger_block_manager_read"] = int64(s.WiredTiger.BlockManager.BytesRead)
+			rv[p+"wiredtiger_block_manager_read_via_memory"] = int64(s.WiredTiger.BlockManager.BytesReadViaMemoryMapAPI)
+			rv[p+"wiredtiger_block_manager_read_via_system_api"] = int64(s.WiredTiger.BlockManager.BytesReadViaSystemCallAPI)
+			rv[p+"wiredtiger_block_manager_written"] = int64(s.WiredTiger.BlockManager.BytesWritten)
+			rv[p+"wiredtiger_block_manager_written_for_checkpoint"] = int64(s.WiredTiger.BlockManager.BytesWrittenForCheckpoint)
+			rv[p+"wiredtiger_block_manager_written_via_memory"] = int64(s.WiredTiger.BlockManager.BytesWrittenViaMemoryMapAPI)
+			rv[p+"wiredtiger_block_manager_written_via_system_api"] = int64(s.WiredTiger.BlockManager.BytesWrittenViaSystemCallAPI)
+		}
+		if s.WiredTiger.Cache != nil {
+			rv[p+"wiredtiger_cache_alloccated"] = int64(s.WiredTiger.Cache.BytesAllocatedForUpdates)
+			rv[p+"wiredtiger_cache_read"] = int64(s.WiredTiger.Cache.BytesReadIntoCache)
+			rv[p+"wiredtiger_cache_write"] = int64(s.WiredTiger.Cache.BytesWrittenFromCache)
+		}
+		if s.WiredTiger.Capacity != nil {
+			rv[p+"wiredtiger_capacity_wait_capacity"] = int64(s.WiredTiger.Capacity.TimeWaitingDueToTotalCapacityUsecs)
+			rv[p+"wiredtiger_capacity_wait_checkpoint"] = int64(s.WiredTiger.Capacity.TimeWaitingDuringCheckpointUsecs)
+			rv[p+"wiredtiger_capacity_wait_eviction"] = int64(s.WiredTiger.Capacity.TimeWaitingDuringEvictionUsecs)
+			rv[p+"wiredtiger_capacity_wait_logging"] = int64(s.WiredTiger.Capacity.TimeWaitingDuringLoggingUsecs)
+			rv[p+"wiredtiger_capacity_wait_read"] = int64(s.WiredTiger.Capacity.TimeWaitingDuringReadUsecs)
+		}
+		if s.WiredTiger.Connection != nil {
+			rv[p+"wiredtiger_connection_allocations"] = int64(s.WiredTiger.Connection.MemoryAllocations)
+			rv[p+"wiredtiger_connection_frees"] = int64(s.WiredTiger.Connection.MemoryFrees)
+			rv[p+"wiredtiger_connection_reallocations"] = int64(s.WiredTiger.Connection.MemoryReAllocations)
+		}
+		if s.WiredTiger.Cursor != nil {
+			rv[p+"wiredtiger_cursor_count"] = int64(s.WiredTiger.Cursor.CachedCursorCount)
+			rv[p+"wiredtiger_cursor_bulk"] = int64(s.WiredTiger.Cursor.CursorBulkLoadedCursorInsertCalls)
+			rv[p+"wiredtiger_cursor_close"] = int64(s.WiredTiger.Cursor.CursorCloseCallsThatResultInCache)
+			rv[p+"wiredtiger_cursor_create"] = int64(s.WiredTiger.Cursor.CursorCreateCalls)
+			rv[p+"wiredtiger_cursor_insert"] = int64(s.WiredTiger.Cursor.CursorInsertCalls)
+			rv[p+"wiredtiger_cursor_modify"] = int64(s.WiredTiger.Cursor.CursorModifyCalls)
+			rv[p+"wiredtiger_cursor_next"] = int64(s.WiredTiger.Cursor.CursorNextCalls)
+			rv[p+"wiredtiger_cursor_restarted"] = int64(s.WiredTiger.Cursor.CursorOperationRestarted)
+			rv[p+"wiredtiger_cursor_prev"] = int64(s.WiredTiger.Cursor.CursorPrevCalls)
+			rv[p+"wiredtiger_cursor_remove"] = int64(s.WiredTiger.Cursor.CursorRemoveCalls)
+			rv[p+"wiredtiger_cursor_reserve"] = int64(s.WiredTiger.Cursor.CursorReserveCalls)
+			rv[p+"wiredtiger_cursor_reset"] = int64(s.WiredTiger.Cursor.CursorResetCalls)
+			rv[p+"wiredtiger_cursor_search"] = int64(s.WiredTiger.Cursor.CursorSearchCalls)
+			rv[p+"wiredtiger_cursor_search_history"] = int64(s.WiredTiger.Cursor.CursorSearchHistoryStoreCalls)
+			rv[p+"wiredtiger_cursor_search_near"] = int64(s.WiredTiger.Cursor.CursorSearchNearCalls)
+			rv[p+"wiredtiger_cursor_sweep_buckets"] = int64(s.WiredTiger.Cursor.CursorSweepBuckets)
+			rv[p+"wiredtiger_cursor_sweep_cursors"] = int64(s.WiredTiger.Cursor.CursorSweepCursorsClosed)
+			rv[p+"wiredtiger_cursor_sweep_examined"] = int64(s.WiredTiger.Cursor.CursorSweepCursorsExamined)
+			rv[p+"wiredtiger_cursor_sweeps"] = int64(s.WiredTiger.Cursor.CursorSweeps)
+			rv[p+"wiredtiger_cursor_truncate"] = int64(s.WiredTiger.Cursor.CursorTruncateCalls)
+			rv[p+"wiredtiger_cursor_update"] = int64(s.WiredTiger.Cursor.CursorUpdateCalls)
+			rv[p+"wiredtiger_cursor_update_value"] = int64(s.WiredTiger.Cursor.CursorUpdateValueSizeChange)
+		}
+		if s.WiredTiger.Lock != nil {
+			rv[p+"wiredtiger_lock_checkpoint_acquisitions"] = int64(s.WiredTiger.Lock.CheckpointLockAcquisitions)
+			rv[p+"wiredtiger_lock_read_acquisitions"] = int64(s.WiredTiger.Lock.DhandleReadLockAcquisitions)
+			rv[p+"wiredtiger_lock_write_acquisitions"] = int64(s.WiredTiger.Lock.DhandleWriteLockAcquisitions)
+			rv[p+"wiredtiger_lock_durable_timestamp_queue_read_acquisitions"] = int64(s.WiredTiger.Lock.DurableTimestampQueueReadLockAcquisitions)
+			rv[p+"wiredtiger_lock_durable_timestamp_queue_write_acquisitions"] = int64(s.WiredTiger.Lock.DurableTimestampQueueWriteLockAcquisitions)
+			rv[p+"wiredtiger_lock_metadata_acquisitions"] = int64(s.WiredTiger.Lock.MetadataLockAcquisitions)
+			rv[p+"wiredtiger_lock_read_timestamp_queue_read_acquisitions"] = int64(s.WiredTiger.Lock.ReadTimestampQueueReadLockAcquisitions)
+			rv[p+"wiredtiger_lock_read_timestamp_queue_write_acquisitions"] = int64(s.WiredTiger.Lock.ReadTimestampQueueWriteLockAcquisitions)
+			rv[p+"wiredtiger_lock_schema_acquisitions"] = int64(s.WiredTiger.Lock.SchemaLockAcquisitions)
+			rv[p+"wiredtiger_lock_table_read_acquisitions"] = int64(s.WiredTiger.Lock.TableReadLockAcquisitions)
+			rv[p+"wiredtiger_lock_table_write_acquisitions"] = int64(s.WiredTiger.Lock.TableWriteLockAcquisitions)
+			rv[p+"wiredtiger_lock_txn_global_read_acquisitions"] = int64(s.WiredTiger.Lock.TxnGlobalReadLockAcquisitions)
+			rv[p+"wiredtiger_lock_checkpoint_wait_time"] = int64(s.WiredTiger.Lock.CheckpointLockApplicationThreadWaitTimeUsecs)
+			rv[p+"wiredtiger_lock_checkpoint_internal_thread_wait_time"] = int64(s.WiredTiger.Lock.CheckpointLockInternalThreadWaitTimeUsecs)
+			rv[p+"wiredtiger_lock_application_thread_time_waiting"] = int64(s.WiredTiger.Lock.DhandleLockApplicationThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_internal_thread_time_waiting"] = int64(s.WiredTiger.Lock.DhandleLockInternalThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_durable_timestamp_queue_application_thread_time_waiting"] = int64(s.WiredTiger.Lock.DurableTimestampQueueLockApplicationThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_durable_timestamp_queue_internal_thread_time_waiting"] = int64(s.WiredTiger.Lock.DurableTimestampQueueLockInternalThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_metadata_application_thread_wait_time"] = int64(s.WiredTiger.Lock.MetadataLockApplicationThreadWaitTimeUsecs)
+			rv[p+"wiredtiger_lock_metadata_internal_thread_wait_time"] = int64(s.WiredTiger.Lock.MetadataLockInternalThreadWaitTimeUsecs)
+			rv[p+"wiredtiger_lock_read_timestamp_queue_application_thread_time_waiting"] = int64(s.WiredTiger.Lock.ReadTimestampQueueLockApplicationThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_read_timestamp_queue_internal_thread_time_waiting"] = int64(s.WiredTiger.Lock.ReadTimestampQueueLockInternalThreadTimeWaitingUsecs)
+			rv[p+"wiredtiger_lock_schema_application_thread_wait_time"] = int64(s.WiredTiger.Lock.SchemaLockApplicationThreadWaitTimeUsecs)
+			rv[p+"wiredtiger_lock_schema_internal_thread_wait_time"] = int64(s.WiredTiger.Lock.SchemaLockInternalThreadWaitTimeUsecs)
+		}
+		if s.WiredTiger.Log != nil {
+			rv[p+"wiredtiger_log_flush"] = int64(s.WiredTiger.Log.LogFlushOperations)
+			rv[p+"wiredtiger_log_force_write"] = int64(s.WiredTiger.Log.LogForceWriteOperations)
+			rv[p+"wiredtiger_log_write_skip"] = int64(s.WiredTiger.Log.LogForceWriteOperationsSkipped)
+			rv[p+"wiredtiger_log_scan"] = int64(s.WiredTiger.Log.LogScanOperations)
+			rv[p+"wiredtiger_log_sync"] = int64(s.WiredTiger.Log.LogSyncOperations)
+			rv[p+"wiredtiger_log_sync_dir"] = int64(s.WiredTiger.Log.LogSyncDirOperations)
+			rv[p+"wiredtiger_log_write"] = int64(s.WiredTiger.Log.LogWriteOperations)
+			rv[p+"wiredtiger_log_payload"] = int64(s.WiredTiger.Log.LogBytesOfPayloadData)
+			rv[p+"wiredtiger_log_written"] = int64(s.WiredTiger.Log.LogBytesWritten)
+			rv[p+"wiredtiger_log_consolidated"] = int64(s.WiredTiger.Log.LoggingBytesConsolidated)
+			rv[p+"wiredtiger_log_buffer_size"] = int64(s.WiredTiger.Log.TotalLogBufferSize)
+		}
+		if s.WiredTiger.Transaction != nil {
+			rv[p+"wiredtiger_transaction_prepare"] = int64(s.WiredTiger.Transaction.PreparedTransactions)
+			rv[p+"wiredtiger_transaction_query"] = int64(s.WiredTiger.Transaction.QueryTimestampCalls)
+			rv[p+"wiredtiger_transaction_rollback"] = int64(s.WiredTiger.Transaction.RollbackToStableCalls)
+			rv[p+"wiredtiger_transaction_set_timestamp"] = int64(s.WiredTiger.Transaction.SetTimestampCalls)
+			rv[p+"wiredtiger_transaction_begin"] = int64(s.WiredTiger.Transaction.TransactionBegins)
+			rv[p+"wiredtiger_transaction_sync"] = int64(s.WiredTiger.Transaction.TransactionSyncCalls)
+			rv[p+"wiredtiger_transaction_committed"] = int64(s.WiredTiger.Transaction.TransactionsCommitted)
+			rv[p+"wiredtiger_transaction_rolled_back"] = int64(s.WiredTiger.Transaction.TransactionsRolledBack)
+		}
+	}
+}
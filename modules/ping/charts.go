@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+// hostCharts are the charts of every host, the placeholder is the host name.
+var hostCharts = module.Charts{
+	{
+		ID:    "host_{{.host}}_rtt",
+		Title: "Ping Round-Trip Time",
+		Units: "ms",
+		Fam:   "{{.host}}",
+		Ctx:   "ping.host_rtt",
+		Type:  module.Area,
+		Dims: module.Dims{
+			{ID: "host_{{.host}}_min_rtt", Name: "min", Div: 1000},
+			{ID: "host_{{.host}}_max_rtt", Name: "max", Div: 1000},
+			{ID: "host_{{.host}}_avg_rtt", Name: "avg", Div: 1000},
+		},
+	},
+	{
+		ID:    "host_{{.host}}_std_dev_rtt",
+		Title: "Ping Round-Trip Time Standard Deviation",
+		Units: "ms",
+		Fam:   "{{.host}}",
+		Ctx:   "ping.host_std_dev_rtt",
+		Dims: module.Dims{
+			{ID: "host_{{.host}}_std_dev_rtt", Name: "std_dev", Div: 1000},
+		},
+	},
+	{
+		ID:    "host_{{.host}}_jitter",
+		Title: "Ping Jitter",
+		Units: "ms",
+		Fam:   "{{.host}}",
+		Ctx:   "ping.host_jitter",
+		Dims: module.Dims{
+			{ID: "host_{{.host}}_jitter", Name: "jitter", Div: 1000},
+		},
+	},
+	{
+		ID:    "host_{{.host}}_packet_loss",
+		Title: "Ping Packet Loss",
+		Units: "percentage",
+		Fam:   "{{.host}}",
+		Ctx:   "ping.host_packet_loss",
+		Dims: module.Dims{
+			{ID: "host_{{.host}}_packet_loss", Name: "loss", Div: 1000},
+		},
+	},
+	{
+		ID:    "host_{{.host}}_packets",
+		Title: "Ping Packets Transferred",
+		Units: "packets",
+		Fam:   "{{.host}}",
+		Ctx:   "ping.host_packets",
+		Dims: module.Dims{
+			{ID: "host_{{.host}}_packets_recv", Name: "received"},
+			{ID: "host_{{.host}}_packets_sent", Name: "sent"},
+		},
+	},
+}
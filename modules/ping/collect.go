@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type lookupIPFunc func(ctx context.Context, network, host string) ([]net.IP, error)
+
+type host struct {
+	name  string
+	ip    net.IP // the last resolved address
+	stats *stats // nil if the host wasn't pinged
+}
+
+// stats are the results of pinging a host, the times are in microseconds.
+type stats struct {
+	sent, recv int
+	min        int64
+	max        int64
+	avg        int64
+	stdDev     int64
+	jitter     int64
+}
+
+func (p *Ping) collect() (map[string]int64, error) {
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, p.maxPings)
+
+	for _, h := range p.hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(h *host) {
+			defer func() { <-sem; wg.Done() }()
+			p.pingHost(h)
+		}(h)
+	}
+	wg.Wait()
+
+	mx := make(map[string]int64)
+
+	for _, h := range p.hosts {
+		st := h.stats
+		if st == nil {
+			continue
+		}
+		px := "host_" + h.name + "_"
+		mx[px+"packets_sent"] = int64(st.sent)
+		mx[px+"packets_recv"] = int64(st.recv)
+		if st.sent > 0 {
+			mx[px+"packet_loss"] = int64(st.sent-st.recv) * 100 * 1000 / int64(st.sent)
+		}
+		if st.recv > 0 {
+			mx[px+"min_rtt"] = st.min
+			mx[px+"max_rtt"] = st.max
+			mx[px+"avg_rtt"] = st.avg
+			mx[px+"std_dev_rtt"] = st.stdDev
+			mx[px+"jitter"] = st.jitter
+		}
+	}
+
+	return mx, nil
+}
+
+// pingHost resolves the host every time, so the changes of DNS records are picked up.
+func (p *Ping) pingHost(h *host) {
+	h.stats = nil
+
+	ip, err := p.resolve(h.name)
+	if err != nil {
+		p.Warningf("host '%s': %v", h.name, err)
+		return
+	}
+	if !ip.Equal(h.ip) {
+		p.Debugf("host '%s' resolved to %s", h.name, ip)
+		h.ip = ip
+	}
+
+	st, err := p.ping(ip)
+	if err != nil {
+		p.Warningf("host '%s' (%s): %v", h.name, ip, err)
+		return
+	}
+	h.stats = st
+}
+
+func (p *Ping) resolve(name string) (net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout.Duration)
+	defer cancel()
+
+	ips, err := p.lookupIP(ctx, p.Network, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, errors.New("no addresses found")
+	}
+	return ips[0], nil
+}
+
+// ping sends the echo requests every interval and waits for the replies until the timeout expires
+// after the last request. The request data is a random token and the send time, relative to the start.
+func (p *Ping) ping(ip net.IP) (*stats, error) {
+	conn, err := listenICMP(ip.To4() == nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var b [10]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	id, token := binary.BigEndian.Uint16(b[:2]), b[2:]
+
+	start := time.Now()
+	if err := conn.SetReadDeadline(start.Add(p.cycleDuration())); err != nil {
+		return nil, err
+	}
+
+	var rtts []time.Duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rtts = p.receive(conn, ip, id, token, start)
+	}()
+
+	var sent int
+	for seq := 0; seq < p.Packets; seq++ {
+		if seq > 0 {
+			time.Sleep(p.Interval.Duration)
+		}
+		data := binary.BigEndian.AppendUint64(append([]byte(nil), token...), uint64(time.Since(start)))
+		if err := conn.writeEcho(ip, id, uint16(seq), data); err != nil {
+			p.Debugf("host %s: send echo request %d: %v", ip, seq, err)
+			continue
+		}
+		sent++
+	}
+	<-done
+
+	if sent == 0 {
+		return nil, fmt.Errorf("failed to send echo requests")
+	}
+	return newStats(sent, rtts), nil
+}
+
+// receive returns the round-trip times of the replies in the sequence order.
+func (p *Ping) receive(conn *icmpConn, ip net.IP, id uint16, token []byte, start time.Time) []time.Duration {
+	buf := make([]byte, 1500)
+	replies := make([]time.Duration, p.Packets)
+	var recv int
+
+	for recv < p.Packets {
+		e, from, ok, err := conn.readEcho(buf, id)
+		if err != nil {
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				p.Debugf("host %s: read echo reply: %v", ip, err)
+			}
+			break
+		}
+		now := time.Since(start)
+		if !ok || !from.Equal(ip) || len(e.data) != len(token)+8 || !bytes.Equal(e.data[:len(token)], token) {
+			continue
+		}
+		if int(e.seq) >= p.Packets || replies[e.seq] != 0 {
+			continue
+		}
+		sentAt := time.Duration(binary.BigEndian.Uint64(e.data[len(token):]))
+		rtt := now - sentAt
+		if rtt <= 0 {
+			rtt = 1
+		}
+		replies[e.seq] = rtt
+		recv++
+	}
+
+	var rtts []time.Duration
+	for _, rtt := range replies {
+		if rtt != 0 {
+			rtts = append(rtts, rtt)
+		}
+	}
+	return rtts
+}
+
+// newStats calculates the stats of the round-trip times, the jitter is the mean difference
+// between the consecutive round-trip times.
+func newStats(sent int, rtts []time.Duration) *stats {
+	st := &stats{sent: sent, recv: len(rtts)}
+	if len(rtts) == 0 {
+		return st
+	}
+
+	var sum, jitter time.Duration
+	lo, hi := rtts[0], rtts[0]
+	for i, rtt := range rtts {
+		sum += rtt
+		if rtt < lo {
+			lo = rtt
+		}
+		if rtt > hi {
+			hi = rtt
+		}
+		if i > 0 {
+			d := rtt - rtts[i-1]
+			if d < 0 {
+				d = -d
+			}
+			jitter += d
+		}
+	}
+	avg := float64(sum) / float64(len(rtts))
+
+	var variance float64
+	for _, rtt := range rtts {
+		variance += (float64(rtt) - avg) * (float64(rtt) - avg)
+	}
+	variance /= float64(len(rtts))
+
+	st.min = lo.Microseconds()
+	st.max = hi.Microseconds()
+	st.avg = int64(avg / float64(time.Microsecond))
+	st.stdDev = int64(math.Sqrt(variance) / float64(time.Microsecond))
+	if len(rtts) > 1 {
+		st.jitter = (jitter / time.Duration(len(rtts)-1)).Microseconds()
+	}
+	return st
+}
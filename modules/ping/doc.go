@@ -0,0 +1,4 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package ping is an ICMP echo (ping) collector
+package ping
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+const (
+	icmpv4EchoReply   = 0
+	icmpv4EchoRequest = 8
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+// echo is an ICMP echo request or reply message.
+type echo struct {
+	typ  byte
+	id   uint16
+	seq  uint16
+	data []byte
+}
+
+func (e echo) marshal() []byte {
+	b := make([]byte, 8+len(e.data))
+	b[0] = e.typ
+	binary.BigEndian.PutUint16(b[4:], e.id)
+	binary.BigEndian.PutUint16(b[6:], e.seq)
+	copy(b[8:], e.data)
+	// the ICMPv6 checksum covers the IPv6 pseudo header, the kernel calculates it
+	if e.typ == icmpv4EchoRequest {
+		binary.BigEndian.PutUint16(b[2:], checksum(b))
+	}
+	return b
+}
+
+func parseEcho(b []byte) (echo, bool) {
+	if len(b) < 8 || b[1] != 0 {
+		return echo{}, false
+	}
+	return echo{
+		typ:  b[0],
+		id:   binary.BigEndian.Uint16(b[4:]),
+		seq:  binary.BigEndian.Uint16(b[6:]),
+		data: b[8:],
+	}, true
+}
+
+// checksum is the Internet checksum (RFC 1071).
+func checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// icmpConn is an ICMP socket. Datagram-oriented (unprivileged) sockets are used if the kernel allows it
+// (net.ipv4.ping_group_range), otherwise raw sockets, they require root or CAP_NET_RAW.
+type icmpConn struct {
+	net.PacketConn
+	v6  bool
+	raw bool
+}
+
+func listenICMP(v6 bool) (*icmpConn, error) {
+	conn, err := listenUnprivileged(v6)
+	if err == nil {
+		return &icmpConn{PacketConn: conn, v6: v6}, nil
+	}
+
+	network := "ip4:icmp"
+	if v6 {
+		network = "ip6:ipv6-icmp"
+	}
+	rawConn, rawErr := net.ListenPacket(network, "")
+	if rawErr != nil {
+		return nil, fmt.Errorf("cannot open ICMP socket: unprivileged: %v, raw: %v", err, rawErr)
+	}
+	return &icmpConn{PacketConn: rawConn, v6: v6, raw: true}, nil
+}
+
+func listenUnprivileged(v6 bool) (net.PacketConn, error) {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	if v6 {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer func() { _ = f.Close() }()
+
+	return net.FilePacketConn(f)
+}
+
+func (c *icmpConn) writeEcho(ip net.IP, id, seq uint16, data []byte) error {
+	e := echo{typ: icmpv4EchoRequest, id: id, seq: seq, data: data}
+	if c.v6 {
+		e.typ = icmpv6EchoRequest
+	}
+
+	var addr net.Addr = &net.UDPAddr{IP: ip}
+	if c.raw {
+		addr = &net.IPAddr{IP: ip}
+	}
+	_, err := c.WriteTo(e.marshal(), addr)
+	return err
+}
+
+// readEcho reads a message, it returns false if the message is not an echo reply.
+// The kernel replaces the id of the echo requests sent over datagram-oriented sockets with the local port,
+// the id of the reply is checked only for raw sockets.
+func (c *icmpConn) readEcho(buf []byte, id uint16) (echo, net.IP, bool, error) {
+	n, addr, err := c.ReadFrom(buf)
+	if err != nil {
+		return echo{}, nil, false, err
+	}
+
+	var from net.IP
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		from = v.IP
+	case *net.IPAddr:
+		from = v.IP
+	}
+
+	e, ok := parseEcho(buf[:n])
+	if !ok {
+		return echo{}, nil, false, nil
+	}
+	reply := byte(icmpv4EchoReply)
+	if c.v6 {
+		reply = icmpv6EchoReply
+	}
+	if e.typ != reply || (c.raw && e.id != id) {
+		return echo{}, nil, false, nil
+	}
+	return e, from, true, nil
+}
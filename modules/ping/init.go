@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maxConcurrentPings = 100
+
+func (p Ping) validateConfig() error {
+	if len(p.Hosts) == 0 {
+		return errors.New("'hosts' is required but not set")
+	}
+	switch p.Network {
+	case "ip", "ip4", "ip6":
+	default:
+		return fmt.Errorf("unknown network '%s' (ip, ip4 or ip6)", p.Network)
+	}
+	if p.Packets <= 0 || p.Packets > maxPackets {
+		return fmt.Errorf("'packets' must be between 1 and %d (%d)", maxPackets, p.Packets)
+	}
+	if p.Interval.Duration <= 0 {
+		return fmt.Errorf("'interval' must be positive (%s)", p.Interval)
+	}
+	if p.Timeout.Duration <= 0 {
+		return fmt.Errorf("'timeout' must be positive (%s)", p.Timeout)
+	}
+	return nil
+}
+
+func (p Ping) initHosts() ([]*host, error) {
+	var hosts []*host
+	seen := make(map[string]bool)
+	for _, name := range p.Hosts {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errors.New("empty host")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("host '%s' is duplicated", name)
+		}
+		seen[name] = true
+		hosts = append(hosts, &host{name: name})
+	}
+	return hosts, nil
+}
+
+func (p *Ping) initCharts() error {
+	for _, h := range p.hosts {
+		if err := p.hostTmpl.Add(p.charts, h.name, map[string]string{"host": h.name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cycleDuration returns the max time pinging of a host takes.
+func (p Ping) cycleDuration() time.Duration {
+	return time.Duration(p.Packets-1)*p.Interval.Duration + p.Timeout.Duration
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"net"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	module.Register("ping", module.Creator{
+		Defaults: module.Defaults{
+			UpdateEvery: 5,
+		},
+		Create: func() module.Module { return New() },
+	})
+}
+
+const (
+	defaultPackets  = 5
+	defaultInterval = time.Millisecond * 100
+	defaultTimeout  = time.Second
+)
+
+// maxPackets keeps the echo sequence numbers (uint16) unique within a data collection.
+const maxPackets = 1000
+
+// New creates Ping with default values.
+func New() *Ping {
+	return &Ping{
+		Config: Config{
+			Network:  "ip",
+			Packets:  defaultPackets,
+			Interval: web.Duration{Duration: defaultInterval},
+			Timeout:  web.Duration{Duration: defaultTimeout},
+		},
+		charts:   &module.Charts{},
+		hostTmpl: module.MustNewChartTemplate(hostCharts),
+		lookupIP: net.DefaultResolver.LookupIP,
+		maxPings: maxConcurrentPings,
+	}
+}
+
+type (
+	// Config is the Ping module configuration.
+	Config struct {
+		Hosts    []string     `yaml:"hosts"`
+		Network  string       `yaml:"network"`
+		Packets  int          `yaml:"packets"`
+		Interval web.Duration `yaml:"interval"`
+		Timeout  web.Duration `yaml:"timeout"`
+	}
+	Ping struct {
+		module.Base
+		Config      `yaml:",inline"`
+		UpdateEvery int `yaml:"update_every"`
+
+		charts   *module.Charts
+		hostTmpl *module.ChartTemplate
+
+		lookupIP lookupIPFunc
+		hosts    []*host
+		maxPings int
+	}
+)
+
+// Cleanup makes cleanup.
+func (Ping) Cleanup() {}
+
+// Init makes initialization.
+func (p *Ping) Init() bool {
+	if err := p.validateConfig(); err != nil {
+		p.Errorf("config validation: %v", err)
+		return false
+	}
+
+	hosts, err := p.initHosts()
+	if err != nil {
+		p.Errorf("hosts initialization: %v", err)
+		return false
+	}
+	p.hosts = hosts
+
+	if err := p.initCharts(); err != nil {
+		p.Errorf("charts initialization: %v", err)
+		return false
+	}
+
+	if d := p.cycleDuration(); p.UpdateEvery > 0 && d >= time.Duration(p.UpdateEvery)*time.Second {
+		p.Warningf("pinging a host takes up to %s, it is longer than the data collection interval (%ds)", d, p.UpdateEvery)
+	}
+
+	p.Debugf("using hosts %v", p.Hosts)
+	p.Debugf("using %d packets every %s, timeout %s", p.Packets, p.Interval, p.Timeout)
+
+	return true
+}
+
+// Check makes check.
+func (p *Ping) Check() bool {
+	return len(p.Collect()) > 0
+}
+
+// Charts returns charts.
+func (p *Ping) Charts() *module.Charts {
+	return p.charts
+}
+
+// Collect collects metrics.
+func (p *Ping) Collect() map[string]int64 {
+	mx, err := p.collect()
+	if err != nil {
+		p.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+// +build linux
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestPing_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success on default config with hosts": {
+			config: prepareConfig("127.0.0.1", "localhost"),
+		},
+		"fail when 'hosts' not set": {
+			wantFail: true,
+			config:   New().Config,
+		},
+		"fail when host is duplicated": {
+			wantFail: true,
+			config:   prepareConfig("127.0.0.1", "127.0.0.1"),
+		},
+		"fail when 'network' is unknown": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("127.0.0.1")
+				cfg.Network = "tcp"
+				return cfg
+			}(),
+		},
+		"fail when 'packets' is not positive": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("127.0.0.1")
+				cfg.Packets = 0
+				return cfg
+			}(),
+		},
+		"fail when 'packets' is over the limit": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("127.0.0.1")
+				cfg.Packets = 65537
+				return cfg
+			}(),
+		},
+		"fail when 'interval' is not positive": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("127.0.0.1")
+				cfg.Interval = web.Duration{}
+				return cfg
+			}(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ping := New()
+			ping.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, ping.Init())
+			} else {
+				assert.True(t, ping.Init())
+			}
+		})
+	}
+}
+
+func TestPing_Charts(t *testing.T) {
+	ping := New()
+	ping.Config = prepareConfig("127.0.0.1", "localhost")
+	require.True(t, ping.Init())
+
+	assert.Len(t, *ping.Charts(), len(hostCharts)*2)
+	assert.NotNil(t, ping.Charts().Get("host_localhost_rtt"))
+}
+
+func TestPing_Check(t *testing.T) {
+	skipIfNoICMP(t, false)
+
+	ping := New()
+	ping.Config = prepareConfig("127.0.0.1")
+	require.True(t, ping.Init())
+
+	assert.True(t, ping.Check())
+}
+
+func TestPing_Check_Fail(t *testing.T) {
+	ping := New()
+	ping.Config = prepareConfig("example.invalid")
+	ping.lookupIP = func(context.Context, string, string) ([]net.IP, error) {
+		return nil, errors.New("mock lookup error")
+	}
+	require.True(t, ping.Init())
+
+	assert.False(t, ping.Check())
+}
+
+func TestPing_Collect(t *testing.T) {
+	tests := map[string]struct {
+		host string
+		v6   bool
+	}{
+		"IPv4 loopback": {host: "127.0.0.1"},
+		"IPv6 loopback": {host: "::1", v6: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			skipIfNoICMP(t, test.v6)
+
+			ping := New()
+			ping.Config = prepareConfig(test.host)
+			require.True(t, ping.Init())
+
+			mx := ping.Collect()
+			require.NotNil(t, mx)
+
+			px := "host_" + test.host + "_"
+			assert.Equal(t, int64(3), mx[px+"packets_sent"])
+			assert.Equal(t, int64(3), mx[px+"packets_recv"])
+			assert.Equal(t, int64(0), mx[px+"packet_loss"])
+			assert.True(t, mx[px+"min_rtt"] <= mx[px+"avg_rtt"])
+			assert.True(t, mx[px+"avg_rtt"] <= mx[px+"max_rtt"])
+			ensureCollectedHasAllChartsDimsVarsIDs(t, ping, mx)
+		})
+	}
+}
+
+func TestPing_Collect_ResolvesEveryTime(t *testing.T) {
+	skipIfNoICMP(t, false)
+
+	var lookups int
+	var lookupErr error
+	ping := New()
+	ping.Config = prepareConfig("gateway.example.com")
+	ping.lookupIP = func(_ context.Context, network, host string) ([]net.IP, error) {
+		lookups++
+		assert.Equal(t, "ip", network)
+		assert.Equal(t, "gateway.example.com", host)
+		return []net.IP{net.ParseIP("127.0.0.1")}, lookupErr
+	}
+	require.True(t, ping.Init())
+
+	mx := ping.Collect()
+	assert.Equal(t, int64(3), mx["host_gateway.example.com_packets_recv"])
+
+	lookupErr = errors.New("mock lookup error")
+	assert.Nil(t, ping.Collect())
+	assert.Equal(t, 2, lookups)
+}
+
+func TestNewStats(t *testing.T) {
+	ms := time.Millisecond
+	tests := map[string]struct {
+		sent int
+		rtts []time.Duration
+		want stats
+	}{
+		"no replies": {
+			sent: 3,
+			want: stats{sent: 3},
+		},
+		"single reply": {
+			sent: 3,
+			rtts: []time.Duration{2 * ms},
+			want: stats{sent: 3, recv: 1, min: 2000, max: 2000, avg: 2000},
+		},
+		"all replies": {
+			sent: 4,
+			rtts: []time.Duration{1 * ms, 3 * ms, 2 * ms, 6 * ms},
+			// jitter: (2 + 1 + 4) / 3, std dev: sqrt((4 + 0 + 1 + 9) / 4)
+			want: stats{sent: 4, recv: 4, min: 1000, max: 6000, avg: 3000, stdDev: 1870, jitter: 2333},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, *newStats(test.sent, test.rtts))
+		})
+	}
+}
+
+func TestEcho(t *testing.T) {
+	e := echo{typ: icmpv4EchoRequest, id: 0x1234, seq: 7, data: []byte("netdata")}
+	b := e.marshal()
+
+	assert.Equal(t, uint16(0), checksum(b), "checksum of a message with the checksum set must be 0")
+
+	got, ok := parseEcho(b)
+	require.True(t, ok)
+	assert.Equal(t, e, got)
+
+	_, ok = parseEcho(b[:7])
+	assert.False(t, ok)
+}
+
+func prepareConfig(hosts ...string) Config {
+	cfg := New().Config
+	cfg.Hosts = hosts
+	cfg.Packets = 3
+	cfg.Interval = web.Duration{Duration: time.Millisecond * 10}
+	cfg.Timeout = web.Duration{Duration: time.Millisecond * 500}
+	return cfg
+}
+
+func skipIfNoICMP(t *testing.T, v6 bool) {
+	conn, err := listenICMP(v6)
+	if err != nil {
+		t.Skipf("ICMP sockets are not available: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, p *Ping, mx map[string]int64) {
+	for _, chart := range *p.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+	}
+}
@@ -3,7 +3,7 @@
 package portcheck
 
 import (
-	"fmt"
+	"strings"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -15,50 +15,83 @@ type (
 	Dims = module.Dims
 )
 
+// portCharts are the charts of every probe, the placeholders are the probe labels.
 var portCharts = Charts{
 	{
-		ID:    "port_%d_status",
-		Title: "TCP Check Status",
+		ID:    "port_{{.host}}_{{.type}}_{{.port}}_status",
+		Title: "Port Check Status",
 		Units: "boolean",
-		Fam:   "port %d",
+		Fam:   "{{.host}}:{{.port}}",
 		Ctx:   "portcheck.status",
 		Dims: Dims{
-			{ID: "port_%d_success", Name: "success"},
-			{ID: "port_%d_failed", Name: "failed"},
-			{ID: "port_%d_timeout", Name: "timeout"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_success", Name: "success"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_failed", Name: "failed"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_timeout", Name: "timeout"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_mismatch", Name: "mismatch"},
 		},
 	},
 	{
-		ID:    "port_%d_current_state_duration",
+		ID:    "port_{{.host}}_{{.type}}_{{.port}}_current_state_duration",
 		Title: "Current State Duration",
 		Units: "seconds",
-		Fam:   "port %d",
+		Fam:   "{{.host}}:{{.port}}",
 		Ctx:   "portcheck.state_duration",
 		Dims: Dims{
-			{ID: "port_%d_current_state_duration", Name: "time"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_current_state_duration", Name: "time"},
 		},
 	},
 	{
-		ID:    "port_%d_connection_latency",
-		Title: "TCP Connection Latency",
+		ID:    "port_{{.host}}_{{.type}}_{{.port}}_connection_latency",
+		Title: "Connection Latency",
 		Units: "ms",
-		Fam:   "port %d",
+		Fam:   "{{.host}}:{{.port}}",
 		Ctx:   "portcheck.latency",
 		Dims: Dims{
-			{ID: "port_%d_latency", Name: "time"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_latency", Name: "time", Div: 1000},
 		},
 	},
 }
 
-func newPortCharts(port int) *Charts {
-	cs := portCharts.Copy()
-	for _, chart := range *cs {
-		chart.ID = fmt.Sprintf(chart.ID, port)
-		chart.Fam = fmt.Sprintf(chart.Fam, port)
+// legacyIDPart is the part of the chart and dimension IDs that is not in the legacy IDs ("port_22_status").
+const legacyIDPart = "{{.host}}_{{.type}}_"
 
+// legacyPortCharts returns the port charts with the IDs used before the multiple hosts and probe types support.
+// They are used if only 'host' and 'ports' are set, so such jobs keep their charts.
+func legacyPortCharts() Charts {
+	charts := *portCharts.Copy()
+	for _, chart := range charts {
+		chart.ID = strings.Replace(chart.ID, legacyIDPart, "", 1)
+		chart.Fam = "port {{.port}}"
 		for _, dim := range chart.Dims {
-			dim.ID = fmt.Sprintf(dim.ID, port)
+			dim.ID = strings.Replace(dim.ID, legacyIDPart, "", 1)
 		}
 	}
-	return cs
+	return charts
+}
+
+// tlsCharts are the additional charts of the TLS probes.
+var tlsCharts = Charts{
+	{
+		ID:    "port_{{.host}}_{{.type}}_{{.port}}_tls_handshake_time",
+		Title: "TLS Handshake Time",
+		Units: "ms",
+		Fam:   "{{.host}}:{{.port}}",
+		Ctx:   "portcheck.tls_handshake_time",
+		Dims: Dims{
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_tls_handshake_time", Name: "time", Div: 1000},
+		},
+	},
+	{
+		ID:    "port_{{.host}}_{{.type}}_{{.port}}_tls_version",
+		Title: "TLS Negotiated Protocol",
+		Units: "boolean",
+		Fam:   "{{.host}}:{{.port}}",
+		Ctx:   "portcheck.tls_version",
+		Dims: Dims{
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_tls_1_0", Name: "TLSv1.0"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_tls_1_1", Name: "TLSv1.1"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_tls_1_2", Name: "TLSv1.2"},
+			{ID: "port_{{.host}}_{{.type}}_{{.port}}_tls_1_3", Name: "TLSv1.3"},
+		},
+	},
 }
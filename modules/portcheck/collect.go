@@ -3,19 +3,33 @@
 package portcheck
 
 import (
-	"fmt"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 )
 
-func (pc PortCheck) collect() (map[string]int64, error) {
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "tls_1_0",
+	tls.VersionTLS11: "tls_1_1",
+	tls.VersionTLS12: "tls_1_2",
+	tls.VersionTLS13: "tls_1_3",
+}
+
+func (pc *PortCheck) collect() (map[string]int64, error) {
 	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, pc.maxChecks)
 
 	for _, p := range pc.ports {
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(p *port) {
+			defer func() { <-sem; wg.Done() }()
 			pc.checkPort(p)
-			wg.Done()
 		}(p)
 	}
 	wg.Wait()
@@ -23,20 +37,38 @@ func (pc PortCheck) collect() (map[string]int64, error) {
 	mx := make(map[string]int64)
 
 	for _, p := range pc.ports {
-		mx[fmt.Sprintf("port_%d_current_state_duration", p.number)] = int64(p.inState)
-		mx[fmt.Sprintf("port_%d_latency", p.number)] = int64(p.latency)
-		mx[fmt.Sprintf("port_%d_%s", p.number, success)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, timeout)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, failed)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, p.state)] = 1
+		px := "port_" + p.key + "_"
+		mx[px+"current_state_duration"] = int64(p.inState)
+		mx[px+"latency"] = p.latency
+		mx[px+string(success)] = 0
+		mx[px+string(timeout)] = 0
+		mx[px+string(failed)] = 0
+		mx[px+string(mismatch)] = 0
+		mx[px+string(p.state)] = 1
+
+		if p.typ == probeTLS {
+			mx[px+"tls_handshake_time"] = p.handshake
+			for v, id := range tlsVersions {
+				mx[px+id] = 0
+				if v == p.tlsVersion {
+					mx[px+id] = 1
+				}
+			}
+		}
 	}
 
 	return mx, nil
 }
 
-func (pc PortCheck) checkPort(p *port) {
+func (pc *PortCheck) checkPort(p *port) {
+	network := "tcp"
+	if p.typ == probeUDP {
+		network = "udp"
+	}
+	address := net.JoinHostPort(p.host, strconv.Itoa(p.number))
+
 	start := time.Now()
-	conn, err := pc.dial("tcp", fmt.Sprintf("%s:%d", pc.Host, p.number), pc.Timeout.Duration)
+	conn, err := pc.dial(network, address, pc.Timeout.Duration)
 	dur := time.Since(start)
 
 	defer func() {
@@ -46,19 +78,92 @@ func (pc PortCheck) checkPort(p *port) {
 	}()
 
 	if err != nil {
-		v, ok := err.(interface{ Timeout() bool })
-		if ok && v.Timeout() {
-			pc.setPortState(p, timeout)
-		} else {
-			pc.setPortState(p, failed)
+		pc.Debugf("%s %s: %v", p.typ, address, err)
+		pc.setPortState(p, errorState(err))
+		return
+	}
+
+	if p.typ == probeTCP && p.send == nil && p.expect == nil {
+		pc.setPortState(p, success)
+		p.latency = dur.Microseconds()
+		return
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(pc.Timeout.Duration))
+
+	if p.typ == probeTLS {
+		tlsConn := tls.Client(conn, p.tlsConfig)
+		start := time.Now()
+		if err := tlsConn.Handshake(); err != nil {
+			pc.Debugf("%s %s: handshake: %v", p.typ, address, err)
+			pc.setPortState(p, errorState(err))
+			return
 		}
+		p.handshake = time.Since(start).Microseconds()
+		p.tlsVersion = tlsConn.ConnectionState().Version
+		conn = tlsConn
+	}
+
+	start = time.Now()
+	st, err := exchange(conn, p)
+	if err != nil {
+		pc.Debugf("%s %s: %v", p.typ, address, err)
+	}
+	pc.setPortState(p, st)
+	if st != success {
 		return
 	}
-	pc.setPortState(p, success)
-	p.latency = durationToMs(dur)
+
+	// a UDP "connection" establishes nothing, the latency is the request/reply round trip time
+	if p.typ == probeUDP {
+		dur = time.Since(start)
+	}
+	p.latency = dur.Microseconds()
 }
 
-func (pc PortCheck) setPortState(p *port, s state) {
+// exchange sends the payload and reads the reply if it is expected.
+// A UDP probe always waits for a reply, there is no other way to tell whether the port is open.
+func exchange(conn net.Conn, p *port) (state, error) {
+	if len(p.send) > 0 || p.typ == probeUDP {
+		if _, err := conn.Write(p.send); err != nil {
+			return errorState(err), err
+		}
+	}
+
+	switch {
+	case p.typ == probeUDP:
+		buf := make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return errorState(err), err
+		}
+		if !bytes.HasPrefix(buf[:n], p.expect) {
+			return mismatch, errors.New("reply doesn't match the expected bytes")
+		}
+	case len(p.expect) > 0:
+		buf := make([]byte, len(p.expect))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return mismatch, err
+			}
+			return errorState(err), err
+		}
+		if !bytes.Equal(buf, p.expect) {
+			return mismatch, errors.New("reply doesn't match the expected bytes")
+		}
+	}
+	return success, nil
+}
+
+func errorState(err error) state {
+	var v interface{ Timeout() bool }
+	if errors.As(err, &v) && v.Timeout() {
+		return timeout
+	}
+	return failed
+}
+
+func (pc *PortCheck) setPortState(p *port, s state) {
 	changed := p.state != s
 	if changed {
 		p.inState = pc.UpdateEvery
@@ -67,7 +172,3 @@ func (pc PortCheck) setPortState(p *port, s state) {
 		p.inState += pc.UpdateEvery
 	}
 }
-
-func durationToMs(duration time.Duration) int {
-	return int(duration) / (int(time.Millisecond) / int(time.Nanosecond))
-}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package portcheck
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/iprange"
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+)
+
+const (
+	// maxRangeHosts limits the number of hosts of a single IP range, so a typo doesn't start a network scan.
+	maxRangeHosts       = 1024
+	maxConcurrentChecks = 100
+)
+
+func (pc PortCheck) validateConfig() error {
+	if pc.Host == "" && len(pc.Hosts) == 0 {
+		return errors.New("'host' or 'hosts' is required but not set")
+	}
+	if len(pc.Ports) == 0 && len(pc.Probes) == 0 {
+		return errors.New("'ports' or 'probes' is required but not set")
+	}
+	return nil
+}
+
+// initHosts returns the hosts, the IP ranges are expanded into addresses.
+func (pc PortCheck) initHosts() ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+	add := func(host string) {
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	for _, v := range append([]string{pc.Host}, pc.Hosts...) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !isIPRange(v) {
+			add(v)
+			continue
+		}
+
+		r, err := iprange.ParseRange(v)
+		if err != nil {
+			return nil, err
+		}
+		size := r.Size()
+		if size.Cmp(big.NewInt(maxRangeHosts)) > 0 {
+			return nil, fmt.Errorf("ip range (%s) has %s addresses, the max is %d", v, size, maxRangeHosts)
+		}
+		ip := r.Start()
+		for i := int64(0); i < size.Int64(); i++ {
+			add(ip.String())
+			ip = cidr.Inc(ip)
+		}
+	}
+	return hosts, nil
+}
+
+// isIPRange reports whether the host is an IP range ("192.0.2.0/24", "192.0.2.1-192.0.2.10"),
+// not an address or a DNS name.
+func isIPRange(host string) bool {
+	if strings.Contains(host, "/") {
+		return true
+	}
+	i := strings.Index(host, "-")
+	return i > 0 && net.ParseIP(host[:i]) != nil
+}
+
+// initPorts returns the probes of every host, 'ports' are plain TCP probes.
+func (pc PortCheck) initPorts(hosts []string) ([]*port, error) {
+	probes := make([]ProbeConfig, 0, len(pc.Ports)+len(pc.Probes))
+	for _, p := range pc.Ports {
+		probes = append(probes, ProbeConfig{Port: p})
+	}
+	probes = append(probes, pc.Probes...)
+
+	var ports []*port
+	seen := make(map[string]bool)
+	for _, cfg := range probes {
+		tmpl, err := newPort(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("probe '%s/%d': %v", cfg.Type, cfg.Port, err)
+		}
+		for _, host := range hosts {
+			p := *tmpl
+			p.host = host
+			p.key = fmt.Sprintf("%s_%s_%d", host, p.typ, p.number)
+			if pc.legacyIDs {
+				p.key = strconv.Itoa(p.number)
+			}
+			if seen[p.key] {
+				return nil, fmt.Errorf("probe '%s/%d' of host '%s' is duplicated", p.typ, p.number, host)
+			}
+			seen[p.key] = true
+			if p.tlsConfig != nil && p.tlsConfig.ServerName == "" && net.ParseIP(host) == nil {
+				p.tlsConfig = p.tlsConfig.Clone()
+				p.tlsConfig.ServerName = host
+			}
+			ports = append(ports, &p)
+		}
+	}
+	return ports, nil
+}
+
+func newPort(cfg ProbeConfig) (*port, error) {
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("invalid port number (%d)", cfg.Port)
+	}
+
+	p := &port{number: cfg.Port, typ: probeType(cfg.Type)}
+	switch p.typ {
+	case "":
+		p.typ = probeTCP
+	case probeTCP, probeUDP, probeTLS:
+	default:
+		return nil, fmt.Errorf("unknown probe type '%s' (tcp, udp or tls)", cfg.Type)
+	}
+
+	var err error
+	if p.send, err = payload(cfg.Send, cfg.SendHex); err != nil {
+		return nil, fmt.Errorf("'send': %v", err)
+	}
+	if p.expect, err = payload(cfg.Expect, cfg.ExpectHex); err != nil {
+		return nil, fmt.Errorf("'expect': %v", err)
+	}
+
+	if p.typ == probeTLS {
+		tlsConfig, err := tlscfg.NewTLSConfig(cfg.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		p.tlsConfig = tlsConfig
+	}
+	return p, nil
+}
+
+// payload returns the text or the hex encoded bytes, only one of them can be set.
+func payload(text, hexText string) ([]byte, error) {
+	if text != "" && hexText != "" {
+		return nil, errors.New("both the text and the hex form are set")
+	}
+	if hexText != "" {
+		return hex.DecodeString(strings.Join(strings.Fields(hexText), ""))
+	}
+	if text != "" {
+		return []byte(text), nil
+	}
+	return nil, nil
+}
+
+func (pc *PortCheck) initCharts() error {
+	for _, p := range pc.ports {
+		labels := map[string]string{
+			"host": p.host,
+			"port": fmt.Sprint(p.number),
+			"type": string(p.typ),
+		}
+		if err := pc.portTmpl.Add(pc.charts, p.key, labels); err != nil {
+			return err
+		}
+		if p.typ == probeTLS {
+			if err := pc.tlsTmpl.Add(pc.charts, p.key, labels); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
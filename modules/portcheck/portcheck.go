@@ -3,10 +3,11 @@
 package portcheck
 
 import (
+	"crypto/tls"
 	"net"
-	"sort"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -32,41 +33,85 @@ func New() *PortCheck {
 	}
 
 	return &PortCheck{
-		Config: config,
-		dial:   net.DialTimeout,
+		Config:    config,
+		dial:      net.DialTimeout,
+		charts:    &module.Charts{},
+		portTmpl:  module.MustNewChartTemplate(portCharts),
+		tlsTmpl:   module.MustNewChartTemplate(tlsCharts),
+		maxChecks: maxConcurrentChecks,
 	}
 }
 
-// Config is the Portcheck module configuration file.
-type Config struct {
-	Host    string       `yaml:"host"`
-	Ports   []int        `yaml:"ports"`
-	Timeout web.Duration `yaml:"timeout"`
-}
+type (
+	// Config is the Portcheck module configuration file.
+	Config struct {
+		Host    string        `yaml:"host"`
+		Hosts   []string      `yaml:"hosts"`
+		Ports   []int         `yaml:"ports"`
+		Probes  []ProbeConfig `yaml:"probes"`
+		Timeout web.Duration  `yaml:"timeout"`
+	}
+	// ProbeConfig is a port check of every host. The reply is matched against the expected bytes if they are set.
+	ProbeConfig struct {
+		Port             int    `yaml:"port"`
+		Type             string `yaml:"type"`
+		Send             string `yaml:"send"`
+		SendHex          string `yaml:"send_hex"`
+		Expect           string `yaml:"expect"`
+		ExpectHex        string `yaml:"expect_hex"`
+		tlscfg.TLSConfig `yaml:",inline"`
+	}
+)
 
 type dialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
 
 type state string
 
 const (
-	success state = "success"
-	timeout state = "timeout"
-	failed  state = "failed"
+	success  state = "success"
+	timeout  state = "timeout"
+	failed   state = "failed"
+	mismatch state = "mismatch"
+)
+
+type probeType string
+
+const (
+	probeTCP probeType = "tcp"
+	probeUDP probeType = "udp"
+	probeTLS probeType = "tls"
 )
 
 type port struct {
-	number  int
-	state   state
-	inState int
-	latency int
+	key       string
+	host      string
+	number    int
+	typ       probeType
+	send      []byte
+	expect    []byte
+	tlsConfig *tls.Config
+
+	state      state
+	inState    int
+	latency    int64 // microseconds
+	handshake  int64 // microseconds
+	tlsVersion uint16
 }
 
 type PortCheck struct {
 	module.Base
 	Config      `yaml:",inline"`
 	UpdateEvery int `yaml:"update_every"`
-	dial        dialFunc
-	ports       []*port
+
+	charts   *module.Charts
+	portTmpl *module.ChartTemplate
+	tlsTmpl  *module.ChartTemplate
+
+	dial      dialFunc
+	ports     []*port
+	maxChecks int
+	// legacyIDs is set if only 'host' and 'ports' are configured, the port key is the port number then
+	legacyIDs bool
 }
 
 // Cleanup makes cleanup.
@@ -74,25 +119,37 @@ func (PortCheck) Cleanup() {}
 
 // Init makes initialization.
 func (pc *PortCheck) Init() bool {
-	if pc.Host == "" {
-		pc.Error("host parameter is not set")
+	if err := pc.validateConfig(); err != nil {
+		pc.Errorf("config validation: %v", err)
 		return false
 	}
 
-	if len(pc.Ports) == 0 {
-		pc.Error("ports parameter is not set")
+	hosts, err := pc.initHosts()
+	if err != nil {
+		pc.Errorf("hosts initialization: %v", err)
 		return false
 	}
 
-	sort.Ints(pc.Ports)
+	pc.legacyIDs = len(pc.Hosts) == 0 && len(pc.Probes) == 0 && len(hosts) == 1
+	if pc.legacyIDs {
+		pc.portTmpl = module.MustNewChartTemplate(legacyPortCharts())
+	}
+
+	ports, err := pc.initPorts(hosts)
+	if err != nil {
+		pc.Errorf("probes initialization: %v", err)
+		return false
+	}
+	pc.ports = ports
 
-	for _, p := range pc.Ports {
-		pc.ports = append(pc.ports, &port{number: p})
+	if err := pc.initCharts(); err != nil {
+		pc.Errorf("charts initialization: %v", err)
+		return false
 	}
 
-	pc.Debugf("using host %s", pc.Host)
-	pc.Debugf("using ports %v", pc.Ports)
-	pc.Debugf("using TCP connection timeout: %s", pc.Timeout)
+	pc.Debugf("using hosts %v", hosts)
+	pc.Debugf("using %d probes", len(pc.ports))
+	pc.Debugf("using connection timeout: %s", pc.Timeout)
 
 	return true
 }
@@ -101,14 +158,8 @@ func (pc *PortCheck) Init() bool {
 func (PortCheck) Check() bool { return true }
 
 // Charts creates charts.
-func (pc PortCheck) Charts() *Charts {
-	charts := &Charts{}
-
-	for _, port := range pc.Ports {
-		_ = charts.Add(*newPortCharts(port)...)
-	}
-
-	return charts
+func (pc *PortCheck) Charts() *Charts {
+	return pc.charts
 }
 
 // Collect collects metrics.
@@ -4,12 +4,18 @@ package portcheck
 
 import (
 	"errors"
+	"io"
+	"log"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +45,84 @@ func TestPortCheck_InitNG(t *testing.T) {
 	assert.True(t, job.Init())
 }
 
+func TestPortCheck_Init_HostsAndProbes(t *testing.T) {
+	tests := map[string]struct {
+		config    Config
+		wantFail  bool
+		wantPorts []string
+	}{
+		"hosts and CIDR": {
+			config: Config{
+				Hosts: []string{"192.0.2.0/30", "example.com", "192.0.2.1"},
+				Ports: []int{22},
+			},
+			wantPorts: []string{"192.0.2.1_tcp_22", "192.0.2.2_tcp_22", "example.com_tcp_22"},
+		},
+		"IP range and probes": {
+			config: Config{
+				Hosts: []string{"192.0.2.1-192.0.2.2"},
+				Probes: []ProbeConfig{
+					{Port: 53, Type: "udp", SendHex: "00 01"},
+					{Port: 443, Type: "tls"},
+				},
+			},
+			wantPorts: []string{"192.0.2.1_udp_53", "192.0.2.2_udp_53", "192.0.2.1_tls_443", "192.0.2.2_tls_443"},
+		},
+		"fail when IP range is too big": {
+			wantFail: true,
+			config:   Config{Hosts: []string{"10.0.0.0/8"}, Ports: []int{22}},
+		},
+		"fail when IP range is invalid": {
+			wantFail: true,
+			config:   Config{Hosts: []string{"192.0.2.0/33"}, Ports: []int{22}},
+		},
+		"fail when probe type is unknown": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Probes: []ProbeConfig{{Port: 22, Type: "sctp"}}},
+		},
+		"fail when probe port is invalid": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Probes: []ProbeConfig{{Port: 65536}}},
+		},
+		"fail when both 'send' and 'send_hex' are set": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Probes: []ProbeConfig{{Port: 22, Send: "a", SendHex: "61"}}},
+		},
+		"fail when 'expect_hex' is invalid": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Probes: []ProbeConfig{{Port: 22, ExpectHex: "zz"}}},
+		},
+		"fail when probe is duplicated": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Ports: []int{22}, Probes: []ProbeConfig{{Port: 22, Expect: "SSH-2.0"}}},
+		},
+		"fail when TLS config is invalid": {
+			wantFail: true,
+			config: Config{Host: "127.0.0.1", Probes: []ProbeConfig{
+				{Port: 443, Type: "tls", TLSConfig: tlscfg.TLSConfig{TLSCA: "testdata/not_exists.pem"}},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+				return
+			}
+			require.True(t, job.Init())
+			var keys []string
+			for _, p := range job.ports {
+				keys = append(keys, p.key)
+			}
+			assert.Equal(t, test.wantPorts, keys)
+		})
+	}
+}
+
 func TestPortCheck_Check(t *testing.T) {
 	assert.True(t, New().Check())
 }
@@ -49,8 +133,39 @@ func TestPortCheck_Cleanup(t *testing.T) {
 
 func TestPortCheck_Charts(t *testing.T) {
 	job := New()
+	job.Hosts = []string{"192.0.2.1", "192.0.2.2"}
 	job.Ports = []int{1, 2}
-	assert.Len(t, *job.Charts(), len(portCharts)*len(job.Ports))
+	job.Probes = []ProbeConfig{{Port: 443, Type: "tls"}}
+	require.True(t, job.Init())
+
+	assert.Len(t, *job.Charts(), len(portCharts)*6+len(tlsCharts)*2)
+
+	chart := job.Charts().Get("port_192.0.2.2_tls_443_tls_version")
+	require.NotNil(t, chart)
+	assert.Equal(t, "192.0.2.2:443", chart.Fam)
+	assert.Contains(t, chart.Labels, module.Label{Key: "type", Value: "tls"})
+}
+
+func TestPortCheck_Charts_LegacyIDs(t *testing.T) {
+	job := New()
+	job.Host = "192.0.2.1"
+	job.Ports = []int{22}
+	require.True(t, job.Init())
+
+	for _, id := range []string{"port_22_status", "port_22_current_state_duration", "port_22_connection_latency"} {
+		chart := job.Charts().Get(id)
+		require.NotNilf(t, chart, "chart '%s'", id)
+		assert.Equal(t, "port 22", chart.Fam)
+	}
+	assert.True(t, job.Charts().Get("port_22_status").HasDim("port_22_success"))
+
+	// any other configuration uses the host and the probe type in the IDs
+	job = New()
+	job.Host = "192.0.2.1"
+	job.Probes = []ProbeConfig{{Port: 22}}
+	require.True(t, job.Init())
+
+	assert.NotNil(t, job.Charts().Get("port_192.0.2.1_tcp_22_status"))
 }
 
 func TestPortCheck_Collect(t *testing.T) {
@@ -75,11 +190,13 @@ func TestPortCheck_Collect(t *testing.T) {
 		"port_39001_current_state_duration": int64(job.UpdateEvery),
 		"port_39001_failed":                 0,
 		"port_39001_latency":                0,
+		"port_39001_mismatch":               0,
 		"port_39001_success":                1,
 		"port_39001_timeout":                0,
 		"port_39002_current_state_duration": int64(job.UpdateEvery),
 		"port_39002_failed":                 0,
 		"port_39002_latency":                0,
+		"port_39002_mismatch":               0,
 		"port_39002_success":                1,
 		"port_39002_timeout":                0,
 	}
@@ -92,11 +209,13 @@ func TestPortCheck_Collect(t *testing.T) {
 		"port_39001_current_state_duration": int64(job.UpdateEvery) * 2,
 		"port_39001_failed":                 0,
 		"port_39001_latency":                0,
+		"port_39001_mismatch":               0,
 		"port_39001_success":                1,
 		"port_39001_timeout":                0,
 		"port_39002_current_state_duration": int64(job.UpdateEvery) * 2,
 		"port_39002_failed":                 0,
 		"port_39002_latency":                0,
+		"port_39002_mismatch":               0,
 		"port_39002_success":                1,
 		"port_39002_timeout":                0,
 	}
@@ -111,11 +230,13 @@ func TestPortCheck_Collect(t *testing.T) {
 		"port_39001_current_state_duration": int64(job.UpdateEvery),
 		"port_39001_failed":                 1,
 		"port_39001_latency":                0,
+		"port_39001_mismatch":               0,
 		"port_39001_success":                0,
 		"port_39001_timeout":                0,
 		"port_39002_current_state_duration": int64(job.UpdateEvery),
 		"port_39002_failed":                 1,
 		"port_39002_latency":                0,
+		"port_39002_mismatch":               0,
 		"port_39002_success":                0,
 		"port_39002_timeout":                0,
 	}
@@ -130,11 +251,13 @@ func TestPortCheck_Collect(t *testing.T) {
 		"port_39001_current_state_duration": int64(job.UpdateEvery),
 		"port_39001_failed":                 0,
 		"port_39001_latency":                0,
+		"port_39001_mismatch":               0,
 		"port_39001_success":                0,
 		"port_39001_timeout":                1,
 		"port_39002_current_state_duration": int64(job.UpdateEvery),
 		"port_39002_failed":                 0,
 		"port_39002_latency":                0,
+		"port_39002_mismatch":               0,
 		"port_39002_success":                0,
 		"port_39002_timeout":                1,
 	}
@@ -144,6 +267,143 @@ func TestPortCheck_Collect(t *testing.T) {
 	assert.Equal(t, expected, collected)
 }
 
+func TestPortCheck_Collect_Probes(t *testing.T) {
+	banner := newTCPBannerServer(t, "SSH-2.0-OpenSSH_8.9\r\n")
+	silent := newTCPBannerServer(t, "")
+	echo := newUDPEchoServer(t)
+	closedUDP := freeUDPPort(t)
+	tlsSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+	tlsPort := tlsSrv.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := map[string]struct {
+		probe     ProbeConfig
+		wantState state
+	}{
+		"banner matches": {
+			probe:     ProbeConfig{Port: banner, Expect: "SSH-2.0"},
+			wantState: success,
+		},
+		"banner doesn't match": {
+			probe:     ProbeConfig{Port: banner, Expect: "220 "},
+			wantState: mismatch,
+		},
+		"no banner": {
+			probe:     ProbeConfig{Port: silent, Expect: "SSH-2.0"},
+			wantState: timeout,
+		},
+		"udp reply matches": {
+			probe:     ProbeConfig{Port: echo, Type: "udp", SendHex: "ca fe", ExpectHex: "cafe"},
+			wantState: success,
+		},
+		"udp reply doesn't match": {
+			probe:     ProbeConfig{Port: echo, Type: "udp", Send: "ping", Expect: "pong"},
+			wantState: mismatch,
+		},
+		"udp port closed": {
+			probe:     ProbeConfig{Port: closedUDP, Type: "udp", Send: "ping"},
+			wantState: failed,
+		},
+		"tls handshake": {
+			probe:     ProbeConfig{Port: tlsPort, Type: "tls", TLSConfig: tlscfg.TLSConfig{InsecureSkipVerify: true}},
+			wantState: success,
+		},
+		"tls handshake fails on certificate verification": {
+			probe:     ProbeConfig{Port: tlsPort, Type: "tls"},
+			wantState: failed,
+		},
+		"tls handshake to a plain TCP server": {
+			probe:     ProbeConfig{Port: banner, Type: "tls", TLSConfig: tlscfg.TLSConfig{InsecureSkipVerify: true}},
+			wantState: failed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Host = "127.0.0.1"
+			job.Probes = []ProbeConfig{test.probe}
+			job.Timeout.Duration = time.Millisecond * 300
+			job.UpdateEvery = 1
+			require.True(t, job.Init())
+
+			mx := job.Collect()
+			require.NotNil(t, mx)
+
+			px := "port_" + job.ports[0].key + "_"
+			assert.Equal(t, int64(1), mx[px+string(test.wantState)], "state '%s'", job.ports[0].state)
+			assert.Equal(t, int64(1), mx[px+"current_state_duration"])
+
+			if test.probe.Type == "tls" && test.wantState == success {
+				assert.Equal(t, int64(1), mx[px+"tls_1_3"])
+				assert.Greater(t, mx[px+"tls_handshake_time"], int64(0))
+			}
+			ensureCollectedHasAllChartsDimsVarsIDs(t, job, mx)
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, pc *PortCheck, mx map[string]int64) {
+	for _, chart := range *pc.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+	}
+}
+
+// newTCPBannerServer starts a TCP server that writes the banner on every connection and returns its port.
+func newTCPBannerServer(t *testing.T, banner string) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = conn.Write([]byte(banner))
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+				_, _ = conn.Read(make([]byte, 1024))
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// newUDPEchoServer starts a UDP server that echoes datagrams and returns its port.
+func newUDPEchoServer(t *testing.T) int {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func freeUDPPort(t *testing.T) int {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	port, _ := strconv.Atoi(strings.TrimPrefix(conn.LocalAddr().String(), "127.0.0.1:"))
+	return port
+}
+
 func testDial(err error) dialFunc {
 	return func(_, _ string, _ time.Duration) (net.Conn, error) { return &net.TCPConn{}, err }
 }
@@ -3,6 +3,9 @@
 package snmp
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/gosnmp/gosnmp"
 )
 
@@ -13,6 +16,8 @@ func (s *SNMP) collect() (map[string]int64, error) {
 		return nil, err
 	}
 
+	s.collectTables(collected)
+
 	return collected, nil
 }
 
@@ -34,22 +39,43 @@ func (s *SNMP) collectOIDs(collected map[string]int64) error {
 				continue
 			}
 
-			switch v := resp.Variables[i]; v.Type {
-			case gosnmp.Boolean,
-				gosnmp.Counter32,
-				gosnmp.Counter64,
-				gosnmp.Gauge32,
-				gosnmp.TimeTicks,
-				gosnmp.Uinteger32,
-				gosnmp.OpaqueFloat,
-				gosnmp.OpaqueDouble,
-				gosnmp.Integer:
-				collected[oid] = gosnmp.ToBigInt(v.Value).Int64()
-			default:
-				s.Debugf("skipping OID '%s' (unsupported type '%s')", oid, v.Type)
+			if v, ok := pduInt(resp.Variables[i]); ok {
+				collected[oid] = v
+			} else {
+				s.Debugf("skipping OID '%s' (unsupported type '%s')", oid, resp.Variables[i].Type)
 			}
 		}
 	}
 
 	return nil
 }
+
+func pduInt(pdu gosnmp.SnmpPDU) (int64, bool) {
+	switch pdu.Type {
+	case gosnmp.Boolean,
+		gosnmp.Counter32,
+		gosnmp.Counter64,
+		gosnmp.Gauge32,
+		gosnmp.TimeTicks,
+		gosnmp.Uinteger32,
+		gosnmp.OpaqueFloat,
+		gosnmp.OpaqueDouble,
+		gosnmp.Integer:
+		return gosnmp.ToBigInt(pdu.Value).Int64(), true
+	default:
+		return 0, false
+	}
+}
+
+func pduString(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return strings.TrimSpace(string(v))
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -13,21 +13,8 @@ import (
 var newSNMPClient = gosnmp.NewHandler
 
 func (s SNMP) validateConfig() error {
-	if len(s.ChartsInput) == 0 {
-		return errors.New("'charts' are required but not set")
-	}
-
 	if s.Options.Version == gosnmp.Version3.String() {
-		if s.User.Name == "" {
-			return errors.New("'user.name' is required when using SNMPv3 but not set")
-		}
-		if _, err := parseSNMPv3SecurityLevel(s.User.SecurityLevel); err != nil {
-			return err
-		}
-		if _, err := parseSNMPv3AuthProtocol(s.User.AuthProto); err != nil {
-			return err
-		}
-		if _, err := parseSNMPv3PrivProtocol(s.User.PrivProto); err != nil {
+		if _, _, err := s.User.SecurityParameters(); err != nil {
 			return err
 		}
 	}
@@ -81,14 +68,12 @@ func (s SNMP) initSNMPClient() (gosnmp.Handler, error) {
 	case gosnmp.Version3:
 		client.SetVersion(gosnmp.Version3)
 		client.SetSecurityModel(gosnmp.UserSecurityModel)
-		client.SetMsgFlags(safeParseSNMPv3SecurityLevel(s.User.SecurityLevel))
-		client.SetSecurityParameters(&gosnmp.UsmSecurityParameters{
-			UserName:                 s.User.Name,
-			AuthenticationProtocol:   safeParseSNMPv3AuthProtocol(s.User.AuthProto),
-			AuthenticationPassphrase: s.User.AuthKey,
-			PrivacyProtocol:          safeParseSNMPv3PrivProtocol(s.User.PrivProto),
-			PrivacyPassphrase:        s.User.PrivKey,
-		})
+		msgFlags, params, err := s.User.SecurityParameters()
+		if err != nil {
+			return nil, err
+		}
+		client.SetMsgFlags(msgFlags)
+		client.SetSecurityParameters(params)
 	default:
 		return nil, fmt.Errorf("invalid SNMP version: %s", s.Options.Version)
 	}
@@ -105,6 +90,63 @@ func (s SNMP) initOIDs() (oids []string) {
 	return oids
 }
 
+// autoProfiles reports whether the metrics are not configured and the profiles are selected by the device.
+func (s SNMP) autoProfiles() bool {
+	return len(s.ChartsInput) == 0 && len(s.TablesInput) == 0 && !s.Interfaces && len(s.Profiles) == 0
+}
+
+// setupMetrics creates the charts and the tables of the profiles and the job configuration,
+// the job charts and tables override the profiles ones with the same id.
+func (s *SNMP) setupMetrics(profiles []string) error {
+	cfg, err := s.profiles.resolve(profiles)
+	if err != nil {
+		return err
+	}
+	cfg.merge(s.ChartsInput, s.TablesInput, s.Interfaces)
+
+	charts, err := newCharts(cfg.charts)
+	if err != nil {
+		return fmt.Errorf("population of charts failed: %v", err)
+	}
+	tables, err := newTables(cfg.tableConfigs())
+	if err != nil {
+		return fmt.Errorf("population of tables failed: %v", err)
+	}
+
+	s.charts = charts
+	s.oids = s.initOIDs()
+	s.tables = tables
+	return nil
+}
+
+// SecurityParameters returns the SNMPv3 message flags and the User-based Security Model parameters of the user.
+// It returns an error if the name is not set or any of the level, auth and priv protocols is invalid.
+func (u User) SecurityParameters() (gosnmp.SnmpV3MsgFlags, *gosnmp.UsmSecurityParameters, error) {
+	if u.Name == "" {
+		return 0, nil, errors.New("'user.name' is required when using SNMPv3 but not set")
+	}
+	msgFlags, err := parseSNMPv3SecurityLevel(u.SecurityLevel)
+	if err != nil {
+		return 0, nil, err
+	}
+	authProto, err := parseSNMPv3AuthProtocol(u.AuthProto)
+	if err != nil {
+		return 0, nil, err
+	}
+	privProto, err := parseSNMPv3PrivProtocol(u.PrivProto)
+	if err != nil {
+		return 0, nil, err
+	}
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:                 u.Name,
+		AuthenticationProtocol:   authProto,
+		AuthenticationPassphrase: u.AuthKey,
+		PrivacyProtocol:          privProto,
+		PrivacyPassphrase:        u.PrivKey,
+	}
+	return msgFlags, params, nil
+}
+
 func parseSNMPVersion(version string) (gosnmp.SnmpVersion, error) {
 	switch version {
 	case "0", "1":
@@ -118,11 +160,6 @@ func parseSNMPVersion(version string) (gosnmp.SnmpVersion, error) {
 	}
 }
 
-func safeParseSNMPv3SecurityLevel(level string) gosnmp.SnmpV3MsgFlags {
-	v, _ := parseSNMPv3SecurityLevel(level)
-	return v
-}
-
 func parseSNMPv3SecurityLevel(level string) (gosnmp.SnmpV3MsgFlags, error) {
 	switch level {
 	case "1", "none", "noAuthNoPriv", "":
@@ -136,11 +173,6 @@ func parseSNMPv3SecurityLevel(level string) (gosnmp.SnmpV3MsgFlags, error) {
 	}
 }
 
-func safeParseSNMPv3AuthProtocol(protocol string) gosnmp.SnmpV3AuthProtocol {
-	v, _ := parseSNMPv3AuthProtocol(protocol)
-	return v
-}
-
 func parseSNMPv3AuthProtocol(protocol string) (gosnmp.SnmpV3AuthProtocol, error) {
 	switch protocol {
 	case "1", "none", "noAuth", "":
@@ -162,11 +194,6 @@ func parseSNMPv3AuthProtocol(protocol string) (gosnmp.SnmpV3AuthProtocol, error)
 	}
 }
 
-func safeParseSNMPv3PrivProtocol(protocol string) gosnmp.SnmpV3PrivProtocol {
-	v, _ := parseSNMPv3PrivProtocol(protocol)
-	return v
-}
-
 func parseSNMPv3PrivProtocol(protocol string) (gosnmp.SnmpV3PrivProtocol, error) {
 	switch protocol {
 	case "1", "none", "noPriv", "":
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+// IF-MIB ifTable and ifXTable columns.
+const (
+	oidIfDescr          = "1.3.6.1.2.1.2.2.1.2"
+	oidIfOperStatus     = "1.3.6.1.2.1.2.2.1.8"
+	oidIfInDiscards     = "1.3.6.1.2.1.2.2.1.13"
+	oidIfInErrors       = "1.3.6.1.2.1.2.2.1.14"
+	oidIfOutDiscards    = "1.3.6.1.2.1.2.2.1.19"
+	oidIfOutErrors      = "1.3.6.1.2.1.2.2.1.20"
+	oidIfHCInOctets     = "1.3.6.1.2.1.31.1.1.1.6"
+	oidIfHCInUcastPkts  = "1.3.6.1.2.1.31.1.1.1.7"
+	oidIfHCOutOctets    = "1.3.6.1.2.1.31.1.1.1.10"
+	oidIfHCOutUcastPkts = "1.3.6.1.2.1.31.1.1.1.11"
+	oidIfAlias          = "1.3.6.1.2.1.31.1.1.1.18"
+)
+
+// interfacesTable is the built-in interface traffic profile ('interfaces: yes').
+// Every interface found in the ifTable gets its own charts, named after ifDescr.
+var interfacesTable = TableConfig{
+	ID:       "if",
+	NameOID:  oidIfDescr,
+	AliasOID: oidIfAlias,
+	Charts: []ChartConfig{
+		{
+			ID:       "traffic",
+			Title:    "Interface Traffic",
+			Units:    "kilobits/s",
+			Type:     module.Area.String(),
+			Priority: 1000,
+			Dimensions: []DimensionConfig{
+				{OID: oidIfHCInOctets, Name: "received", Algorithm: module.Incremental.String(), Multiplier: 8, Divisor: 1000},
+				{OID: oidIfHCOutOctets, Name: "sent", Algorithm: module.Incremental.String(), Multiplier: -8, Divisor: 1000},
+			},
+		},
+		{
+			ID:       "packets",
+			Title:    "Interface Unicast Packets",
+			Units:    "packets/s",
+			Priority: 1001,
+			Dimensions: []DimensionConfig{
+				{OID: oidIfHCInUcastPkts, Name: "received", Algorithm: module.Incremental.String()},
+				{OID: oidIfHCOutUcastPkts, Name: "sent", Algorithm: module.Incremental.String(), Multiplier: -1},
+			},
+		},
+		{
+			ID:       "errors",
+			Title:    "Interface Errors",
+			Units:    "errors/s",
+			Priority: 1002,
+			Dimensions: []DimensionConfig{
+				{OID: oidIfInErrors, Name: "inbound", Algorithm: module.Incremental.String()},
+				{OID: oidIfOutErrors, Name: "outbound", Algorithm: module.Incremental.String(), Multiplier: -1},
+			},
+		},
+		{
+			ID:       "discards",
+			Title:    "Interface Discards",
+			Units:    "discards/s",
+			Priority: 1003,
+			Dimensions: []DimensionConfig{
+				{OID: oidIfInDiscards, Name: "inbound", Algorithm: module.Incremental.String()},
+				{OID: oidIfOutDiscards, Name: "outbound", Algorithm: module.Incremental.String(), Multiplier: -1},
+			},
+		},
+		{
+			ID:       "operstatus",
+			Title:    "Interface Operational Status",
+			Units:    "status",
+			Priority: 1004,
+			Dimensions: []DimensionConfig{
+				{OID: oidIfOperStatus, Name: "status"},
+			},
+		},
+	},
+}
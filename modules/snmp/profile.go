@@ -0,0 +1,387 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/logger"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	oidSysObjectID = "1.3.6.1.2.1.1.2.0"
+)
+
+//go:embed profiles/*.yaml
+var bundledProfiles embed.FS
+
+type (
+	// Profile is a set of metrics of a device kind (vendor, model, OS).
+	// A profile can extend other profiles, its charts and tables override the extended ones with the same id.
+	Profile struct {
+		Name       string          `yaml:"-"`
+		Extends    []string        `yaml:"extends"`
+		Selector   ProfileSelector `yaml:"selector"`
+		Charts     []ChartConfig   `yaml:"charts"`
+		Tables     []TableConfig   `yaml:"tables"`
+		Interfaces bool            `yaml:"interfaces"`
+
+		sysObjectID matcher.Matcher
+		sysDescr    matcher.Matcher
+		// err is why the profile is invalid, an invalid profile is never selected and can't be resolved
+		err error
+	}
+	// ProfileSelector selects the profile for a device, the values are pkg/matcher expressions.
+	// The profile is selected if all the set expressions match. A profile without a selector
+	// is never selected automatically, it can only be extended or set in the job configuration.
+	ProfileSelector struct {
+		SysObjectID string `yaml:"sys_object_id"`
+		SysDescr    string `yaml:"sys_descr"`
+	}
+
+	profiles map[string]*Profile
+
+	// metricsConfig is the result of merging profiles and the job configuration.
+	metricsConfig struct {
+		charts     []ChartConfig
+		tables     []TableConfig
+		interfaces bool
+	}
+)
+
+// loadProfiles loads the bundled profiles and the profiles from the directory.
+// A profile from the directory overrides the bundled profile with the same name.
+// An invalid profile from the directory is skipped with a warning, it fails only the jobs that set it explicitly.
+func loadProfiles(dir string, log *logger.Logger) (profiles, error) {
+	ps := make(profiles)
+
+	if err := ps.loadFS(bundledProfiles, "profiles", nil); err != nil {
+		return nil, fmt.Errorf("bundled profiles: %v", err)
+	}
+	for _, name := range ps.names() {
+		if _, err := ps.resolve([]string{name}); err != nil {
+			return nil, fmt.Errorf("bundled profiles: %v", err)
+		}
+	}
+
+	if dir == "" {
+		return ps, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return ps, nil
+	}
+	bundled := make(profiles, len(ps))
+	for name, p := range ps {
+		bundled[name] = p
+	}
+	skip := func(err error) {
+		log.Warningf("profiles dir '%s': %v, skipping the profile", dir, err)
+	}
+	if err := ps.loadFS(os.DirFS(dir), ".", skip); err != nil {
+		return nil, fmt.Errorf("profiles dir '%s': %v", dir, err)
+	}
+
+	// the directory profiles go first, an invalid override is replaced by the bundled profile before the bundled
+	// profiles that extend it are checked. A skipped profile may break the profiles that extend it, so repeat
+	// until nothing is skipped.
+	ps.skipInvalid(func(name string) bool { return ps[name] != bundled[name] }, bundled, skip)
+	ps.skipInvalid(func(string) bool { return true }, bundled, skip)
+	return ps, nil
+}
+
+// skipInvalid resolves the filtered profiles until none is invalid. An invalid profile is replaced by the bundled
+// one with the same name, if there is one, otherwise it is marked invalid.
+func (ps profiles) skipInvalid(filter func(name string) bool, bundled profiles, skip func(err error)) {
+	for skipped := true; skipped; {
+		skipped = false
+		for _, name := range ps.names() {
+			if ps[name].err != nil || !filter(name) {
+				continue
+			}
+			if _, err := ps.resolve([]string{name}); err != nil {
+				skip(err)
+				if p, ok := bundled[name]; ok && p != ps[name] {
+					ps[name] = p
+				} else {
+					ps[name].err = err
+				}
+				skipped = true
+			}
+		}
+	}
+}
+
+// loadFS loads the profiles from the directory of the file system. If skip is set, the error of a profile that can't
+// be parsed is passed to it instead of failing: the profile doesn't override the one with the same name,
+// a new one is kept invalid.
+func (ps profiles) loadFS(fsys fs.FS, dir string, skip func(err error)) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		bs, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(e.Name(), ext)
+		p, err := parseProfile(name, bs)
+		if err != nil {
+			if skip == nil {
+				return err
+			}
+			skip(err)
+			if _, ok := ps[name]; !ok {
+				ps[name] = &Profile{Name: name, err: err}
+			}
+			continue
+		}
+		ps[p.Name] = p
+	}
+	return nil
+}
+
+func parseProfile(name string, bs []byte) (*Profile, error) {
+	p := &Profile{Name: name}
+	if err := yaml.Unmarshal(bs, p); err != nil {
+		return nil, fmt.Errorf("profile '%s': %v", name, err)
+	}
+	var err error
+	if p.Selector.SysObjectID != "" {
+		if p.sysObjectID, err = matcher.Parse(p.Selector.SysObjectID); err != nil {
+			return nil, fmt.Errorf("profile '%s': selector 'sys_object_id': %v", name, err)
+		}
+	}
+	if p.Selector.SysDescr != "" {
+		if p.sysDescr, err = matcher.Parse(p.Selector.SysDescr); err != nil {
+			return nil, fmt.Errorf("profile '%s': selector 'sys_descr': %v", name, err)
+		}
+	}
+	return p, nil
+}
+
+// match reports whether the profile is for the device.
+func (p *Profile) match(sysObjectID, sysDescr string) bool {
+	if p.err != nil || p.sysObjectID == nil && p.sysDescr == nil {
+		return false
+	}
+	return (p.sysObjectID == nil || p.sysObjectID.MatchString(sysObjectID)) &&
+		(p.sysDescr == nil || p.sysDescr.MatchString(sysDescr))
+}
+
+func (ps profiles) names() []string {
+	names := make([]string, 0, len(ps))
+	for name := range ps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// match returns the names of the profiles for the device. The profiles that another matched profile extends
+// are dropped, they are resolved as a part of it. The rest are ordered from the least specific (the fewest
+// extended profiles) to the most specific one, so the charts of the most specific profile are merged last.
+func (ps profiles) match(sysObjectID, sysDescr string) []string {
+	var matched []string
+	for _, name := range ps.names() {
+		if ps[name].match(sysObjectID, sysDescr) {
+			matched = append(matched, name)
+		}
+	}
+
+	extended := make(map[string]bool)
+	for _, name := range matched {
+		for _, ext := range ps.extended(name) {
+			extended[ext] = true
+		}
+	}
+	var names []string
+	for _, name := range matched {
+		if !extended[name] {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(ps.extended(names[i])) < len(ps.extended(names[j]))
+	})
+	return names
+}
+
+// extended returns the names of the profiles the profile extends, directly or through other profiles.
+func (ps profiles) extended(name string) []string {
+	seen := map[string]bool{name: true}
+	var names []string
+	var walk func(name string)
+	walk = func(name string) {
+		p, ok := ps[name]
+		if !ok {
+			return
+		}
+		for _, ext := range p.Extends {
+			if !seen[ext] {
+				seen[ext] = true
+				names = append(names, ext)
+				walk(ext)
+			}
+		}
+	}
+	walk(name)
+	return names
+}
+
+// resolve merges the profiles (including the extended ones) in order.
+func (ps profiles) resolve(names []string) (*metricsConfig, error) {
+	cfg := &metricsConfig{}
+	for _, name := range names {
+		if err := ps.resolveInto(cfg, name, nil); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := newCharts(cfg.charts); err != nil {
+		return nil, fmt.Errorf("profiles %v: %v", names, err)
+	}
+	if _, err := newTables(cfg.tableConfigs()); err != nil {
+		return nil, fmt.Errorf("profiles %v: %v", names, err)
+	}
+	return cfg, nil
+}
+
+// resolveInto merges the profile, after the profiles it extends, into the config.
+// The chain is the names of the profiles that extend the profile, it is used to detect cycles.
+func (ps profiles) resolveInto(cfg *metricsConfig, name string, chain []string) error {
+	chain = append(chain[:len(chain):len(chain)], name)
+	for _, v := range chain[:len(chain)-1] {
+		if v == name {
+			return fmt.Errorf("profile '%s': extends cycle %s", name, strings.Join(chain, " -> "))
+		}
+	}
+	p, ok := ps[name]
+	if !ok {
+		if len(chain) > 1 {
+			return fmt.Errorf("profile '%s': extends unknown profile '%s'", chain[len(chain)-2], name)
+		}
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	if p.err != nil {
+		if len(chain) > 1 {
+			return fmt.Errorf("profile '%s': extends invalid profile '%s'", chain[len(chain)-2], name)
+		}
+		return fmt.Errorf("invalid profile '%s': %v", name, p.err)
+	}
+	for _, ext := range p.Extends {
+		if err := ps.resolveInto(cfg, ext, chain); err != nil {
+			return err
+		}
+	}
+	cfg.merge(p.Charts, p.Tables, p.Interfaces)
+	return nil
+}
+
+// merge adds the charts and the tables, they override the existing ones with the same id.
+func (c *metricsConfig) merge(charts []ChartConfig, tables []TableConfig, interfaces bool) {
+	c.charts = mergeCharts(c.charts, charts)
+	c.tables = mergeTables(c.tables, tables)
+	c.interfaces = c.interfaces || interfaces
+}
+
+func (c *metricsConfig) tableConfigs() []TableConfig {
+	if !c.interfaces {
+		return c.tables
+	}
+	return mergeTables([]TableConfig{interfacesTable}, c.tables)
+}
+
+func mergeCharts(charts, overrides []ChartConfig) []ChartConfig {
+	merged := append([]ChartConfig(nil), charts...)
+	for _, o := range overrides {
+		if i := indexChart(merged, o.ID); i != -1 {
+			merged[i] = o
+		} else {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// mergeTables merges the tables by id, the table charts are merged by id.
+func mergeTables(tables, overrides []TableConfig) []TableConfig {
+	merged := append([]TableConfig(nil), tables...)
+	for _, o := range overrides {
+		i := indexTable(merged, o.ID)
+		if i == -1 {
+			merged = append(merged, o)
+			continue
+		}
+		t := merged[i]
+		if o.NameOID != "" {
+			t.NameOID = o.NameOID
+		}
+		if o.AliasOID != "" {
+			t.AliasOID = o.AliasOID
+		}
+		t.Charts = mergeCharts(t.Charts, o.Charts)
+		merged[i] = t
+	}
+	return merged
+}
+
+func indexChart(charts []ChartConfig, id string) int {
+	for i, c := range charts {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexTable(tables []TableConfig, id string) int {
+	for i, t := range tables {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectProfiles returns the names of the profiles matching the device sysObjectID and sysDescr.
+func (s *SNMP) selectProfiles() ([]string, error) {
+	resp, err := s.snmpClient.Get([]string{oidSysObjectID, oidSysDescr})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get sysObjectID and sysDescr: %v", err)
+	}
+
+	var sysObjectID, sysDescr string
+	for _, v := range resp.Variables {
+		switch strings.TrimPrefix(v.Name, ".") {
+		case oidSysObjectID:
+			sysObjectID = strings.TrimPrefix(pduString(v), ".")
+		case oidSysDescr:
+			sysDescr = pduString(v)
+		}
+	}
+	if sysObjectID == "" && sysDescr == "" {
+		return nil, errors.New("device returned neither sysObjectID nor sysDescr")
+	}
+	s.Debugf("sysObjectID '%s', sysDescr '%s'", sysObjectID, sysDescr)
+
+	names := s.profiles.match(sysObjectID, sysDescr)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no profile matches the device (sysObjectID '%s', sysDescr '%s')", sysObjectID, sysDescr)
+	}
+	return names, nil
+}
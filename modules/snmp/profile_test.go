@@ -0,0 +1,416 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gosnmp/gosnmp"
+	snmpmock "github.com/gosnmp/gosnmp/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfiles_Bundled(t *testing.T) {
+	ps, err := loadProfiles("", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"apc-ups", "cisco", "generic", "net-snmp", "printer"}, ps.names())
+}
+
+func TestLoadProfiles_Dir(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		wantNames   []string
+		wantInvalid []string
+	}{
+		"no dir": {
+			wantNames: []string{"apc-ups", "cisco", "generic", "net-snmp", "printer"},
+		},
+		"new profile": {
+			files: map[string]string{
+				"switch.yml": "extends: [generic]\nselector: {sys_object_id: '* 1.3.6.1.4.1.99999.*'}",
+				"README.md":  "not a profile",
+			},
+			wantNames: []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "switch"},
+		},
+		"skip invalid yaml": {
+			files:       map[string]string{"switch.yaml": "charts: {"},
+			wantNames:   []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "switch"},
+			wantInvalid: []string{"switch"},
+		},
+		"skip invalid selector": {
+			files:       map[string]string{"switch.yaml": "selector: {sys_descr: '~ ('}"},
+			wantNames:   []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "switch"},
+			wantInvalid: []string{"switch"},
+		},
+		"skip unknown extended profile": {
+			files:       map[string]string{"switch.yaml": "extends: [unknown]"},
+			wantNames:   []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "switch"},
+			wantInvalid: []string{"switch"},
+		},
+		"skip extends cycle": {
+			files: map[string]string{
+				"a.yaml": "extends: [b]",
+				"b.yaml": "extends: [a]",
+			},
+			wantNames:   []string{"a", "apc-ups", "b", "cisco", "generic", "net-snmp", "printer"},
+			wantInvalid: []string{"a", "b"},
+		},
+		"skip invalid chart": {
+			files: map[string]string{
+				"switch.yaml": "charts: [{id: 'a', dimensions: [{oid: '1.1'}, {oid: '1.1'}]}]",
+			},
+			wantNames:   []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "switch"},
+			wantInvalid: []string{"switch"},
+		},
+		"skip profile extending invalid one": {
+			files: map[string]string{
+				"router.yaml": "extends: [switch]",
+				"switch.yaml": "charts: [{id: 'a', dimensions: [{oid: '1.1'}, {oid: '1.1'}]}]",
+			},
+			wantNames:   []string{"apc-ups", "cisco", "generic", "net-snmp", "printer", "router", "switch"},
+			wantInvalid: []string{"router", "switch"},
+		},
+		"keep bundled profile if override is invalid": {
+			files: map[string]string{
+				"generic.yaml": "charts: [{id: 'a', dimensions: [{oid: '1.1'}, {oid: '1.1'}]}]",
+				"cisco.yaml":   "charts: {",
+			},
+			wantNames: []string{"apc-ups", "cisco", "generic", "net-snmp", "printer"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "snmp.profiles")
+			if test.files != nil {
+				require.NoError(t, os.Mkdir(dir, 0755))
+			}
+			for file, content := range test.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+			}
+
+			ps, err := loadProfiles(dir, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantNames, ps.names())
+			var invalid []string
+			for _, name := range ps.names() {
+				if ps[name].err != nil {
+					invalid = append(invalid, name)
+				}
+			}
+			assert.Equal(t, test.wantInvalid, invalid)
+			for _, name := range ps.names() {
+				if ps[name].err == nil {
+					_, err := ps.resolve([]string{name})
+					assert.NoErrorf(t, err, "profile '%s'", name)
+				}
+			}
+		})
+	}
+}
+
+func TestProfiles_match(t *testing.T) {
+	ps, err := loadProfiles("", nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		sysObjectID string
+		sysDescr    string
+		want        []string
+	}{
+		"unknown device": {
+			sysObjectID: "1.3.6.1.4.1.99999.1",
+			want:        []string{"generic"},
+		},
+		"cisco": {
+			sysObjectID: "1.3.6.1.4.1.9.1.1208",
+			sysDescr:    "Cisco IOS Software",
+			want:        []string{"cisco"},
+		},
+		"net-snmp": {
+			sysObjectID: "1.3.6.1.4.1.8072.3.2.10",
+			sysDescr:    "Linux host 5.15.0",
+			want:        []string{"net-snmp"},
+		},
+		"printer": {
+			sysObjectID: "1.3.6.1.4.1.11.2.3.9.1",
+			sysDescr:    "HP ETHERNET MULTI-ENVIRONMENT,ROM none,JETDIRECT,JD153,EEPROM JSI24090012, HP LaserJet",
+			want:        []string{"printer"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, ps.match(test.sysObjectID, test.sysDescr))
+		})
+	}
+}
+
+func TestProfiles_match_OverridesExtended(t *testing.T) {
+	ps, err := loadProfiles("", nil)
+	require.NoError(t, err)
+	// sorts before "generic", which matches every device
+	acme, err := parseProfile("acme", []byte(`
+extends: [generic]
+selector:
+  sys_object_id: "* 1.3.6.1.4.1.99999.*"
+charts:
+  - id: uptime
+    title: ACME Uptime
+    dimensions: [{name: uptime, oid: 1.3.6.1.2.1.1.3.0}]
+`))
+	require.NoError(t, err)
+	// doesn't extend "generic", but overrides its chart
+	acmeLite, err := parseProfile("acme-lite", []byte(`
+selector:
+  sys_descr: "* ACME Lite*"
+charts:
+  - id: uptime
+    title: ACME Lite Uptime
+    dimensions: [{name: uptime, oid: 1.3.6.1.2.1.1.3.0}]
+`))
+	require.NoError(t, err)
+	ps[acme.Name], ps[acmeLite.Name] = acme, acmeLite
+
+	tests := map[string]struct {
+		sysDescr  string
+		wantNames []string
+		wantTitle string
+	}{
+		"extends generic": {
+			wantNames: []string{"acme"},
+			wantTitle: "ACME Uptime",
+		},
+		"doesn't extend generic": {
+			sysDescr:  "ACME Lite 2.0",
+			wantNames: []string{"acme-lite", "acme"},
+			wantTitle: "ACME Uptime",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			names := ps.match("1.3.6.1.4.1.99999.1", test.sysDescr)
+			assert.Equal(t, test.wantNames, names)
+
+			cfg, err := ps.resolve(names)
+			require.NoError(t, err)
+			var title string
+			for _, chart := range cfg.charts {
+				if chart.ID == "uptime" {
+					title = chart.Title
+				}
+			}
+			assert.Equal(t, test.wantTitle, title)
+		})
+	}
+}
+
+func TestProfiles_resolve(t *testing.T) {
+	ps := make(profiles)
+	base, err := parseProfile("base", []byte(`
+charts:
+  - id: uptime
+    dimensions: [{name: uptime, oid: 1.3.6.1.2.1.1.3.0}]
+  - id: load
+    dimensions: [{name: load1, oid: 1.3.6.1.4.1.2021.10.1.5.1}]
+tables:
+  - id: if
+    name_oid: 1.3.6.1.2.1.2.2.1.2
+    charts:
+      - id: traffic
+        dimensions: [{name: in, oid: 1.3.6.1.2.1.2.2.1.10}]
+`))
+	require.NoError(t, err)
+	device, err := parseProfile("device", []byte(`
+extends: [base]
+charts:
+  - id: load
+    units: load
+    dimensions: [{name: load5, oid: 1.3.6.1.4.1.2021.10.1.5.2}]
+  - id: temperature
+    dimensions: [{name: cpu, oid: 1.3.6.1.4.1.99999.1}]
+tables:
+  - id: if
+    name_oid: 1.3.6.1.2.1.31.1.1.1.1
+    charts:
+      - id: errors
+        dimensions: [{name: in, oid: 1.3.6.1.2.1.2.2.1.14}]
+`))
+	require.NoError(t, err)
+	ps[base.Name], ps[device.Name] = base, device
+
+	cfg, err := ps.resolve([]string{"device"})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.charts, 3)
+	assert.Equal(t, "uptime", cfg.charts[0].ID)
+	assert.Equal(t, "load", cfg.charts[1].ID)
+	assert.Equal(t, "load", cfg.charts[1].Units)
+	assert.Equal(t, "load5", cfg.charts[1].Dimensions[0].Name)
+	assert.Equal(t, "temperature", cfg.charts[2].ID)
+
+	require.Len(t, cfg.tables, 1)
+	assert.Equal(t, "1.3.6.1.2.1.31.1.1.1.1", cfg.tables[0].NameOID)
+	require.Len(t, cfg.tables[0].Charts, 2)
+	assert.Equal(t, "traffic", cfg.tables[0].Charts[0].ID)
+	assert.Equal(t, "errors", cfg.tables[0].Charts[1].ID)
+	assert.False(t, cfg.interfaces)
+
+	_, err = ps.resolve([]string{"unknown"})
+	assert.Error(t, err)
+}
+
+func TestSNMP_Init_Profiles(t *testing.T) {
+	mockSNMP, cleanup := mockInit(t)
+	defer cleanup()
+
+	newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+	defaultMockExpects(mockSNMP)
+
+	snmp := New()
+	snmp.Config = prepareV2Config()
+	snmp.Profiles = []string{"apc-ups"}
+	require.True(t, snmp.Init())
+
+	for _, id := range []string{"uptime", "battery_capacity", "voltage", "test_chart1"} {
+		assert.NotNilf(t, snmp.Charts().Get(id), "chart '%s'", id)
+	}
+	require.Len(t, snmp.tables, 1)
+	assert.Equal(t, "if", snmp.tables[0].cfg.ID)
+
+	snmp = New()
+	snmp.Config = prepareV2Config()
+	snmp.Profiles = []string{"unknown"}
+	assert.False(t, snmp.Init())
+}
+
+func TestSNMP_Init_InvalidProfileInDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "switch.yaml"), []byte("charts: {"), 0644))
+
+	mockSNMP, cleanup := mockInit(t)
+	defer cleanup()
+
+	newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+	defaultMockExpects(mockSNMP)
+
+	// the invalid profile is skipped
+	snmp := New()
+	snmp.Config = prepareV2Config()
+	snmp.ProfilesDir = dir
+	snmp.Profiles = []string{"apc-ups"}
+	assert.True(t, snmp.Init())
+
+	// the job that sets it explicitly fails
+	snmp = New()
+	snmp.Config = prepareV2Config()
+	snmp.ProfilesDir = dir
+	snmp.Profiles = []string{"switch"}
+	assert.False(t, snmp.Init())
+}
+
+func TestSNMP_Check_Profiles(t *testing.T) {
+	tests := map[string]struct {
+		prepareMock func(m *snmpmock.MockHandler)
+		wantFail    bool
+		wantCharts  []string
+	}{
+		"cisco device": {
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get([]string{oidSysObjectID, oidSysDescr}).Return(&gosnmp.SnmpPacket{
+					Variables: []gosnmp.SnmpPDU{
+						{Name: "." + oidSysObjectID, Value: ".1.3.6.1.4.1.9.1.1208", Type: gosnmp.ObjectIdentifier},
+						{Name: "." + oidSysDescr, Value: []byte("Cisco IOS Software"), Type: gosnmp.OctetString},
+					},
+				}, nil)
+				m.EXPECT().Get([]string{"1.3.6.1.2.1.1.3.0"}).Return(&gosnmp.SnmpPacket{
+					Variables: []gosnmp.SnmpPDU{{Value: uint32(100), Type: gosnmp.TimeTicks}},
+				}, nil)
+				m.EXPECT().Walk(gomock.Any(), gomock.Any()).AnyTimes()
+			},
+			wantCharts: []string{"uptime"},
+		},
+		"no sysObjectID and sysDescr": {
+			wantFail: true,
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get(gomock.Any()).Return(&gosnmp.SnmpPacket{
+					Variables: []gosnmp.SnmpPDU{
+						{Name: "." + oidSysObjectID, Type: gosnmp.NoSuchObject},
+						{Name: "." + oidSysDescr, Type: gosnmp.NoSuchObject},
+					},
+				}, nil)
+			},
+		},
+		"Get fails": {
+			wantFail: true,
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get(gomock.Any()).Return(nil, errors.New("mock Get() error"))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSNMP, cleanup := mockInit(t)
+			defer cleanup()
+
+			newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+			defaultMockExpects(mockSNMP)
+			test.prepareMock(mockSNMP)
+
+			snmp := New()
+			snmp.Config = prepareV2Config()
+			snmp.ChartsInput = nil
+			require.True(t, snmp.Init())
+
+			if test.wantFail {
+				assert.False(t, snmp.Check())
+				return
+			}
+			require.True(t, snmp.Check())
+			for _, id := range test.wantCharts {
+				assert.NotNilf(t, snmp.Charts().Get(id), "chart '%s'", id)
+			}
+		})
+	}
+}
+
+func TestSNMP_Check_ProfilesAgent(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.set(oidSysObjectID, gosnmp.ObjectIdentifier, ".1.3.6.1.4.1.8072.3.2.10")
+	agent.set(oidSysDescr, gosnmp.OctetString, []byte("Linux host 5.15.0"))
+	agent.set("1.3.6.1.2.1.1.3.0", gosnmp.TimeTicks, uint32(360000))
+	agent.set("1.3.6.1.4.1.2021.10.1.5.1", gosnmp.Integer, 150)
+	agent.set("1.3.6.1.4.1.2021.9.1.2.1", gosnmp.OctetString, []byte("/"))
+	agent.set("1.3.6.1.4.1.2021.9.1.7.1", gosnmp.Integer, 1000)
+	agent.set("1.3.6.1.4.1.2021.9.1.8.1", gosnmp.Integer, 3000)
+	agent.setIfRow(1, "eth0", "", 100, 200)
+
+	newSNMPClient = gosnmp.NewHandler
+	snmp := New()
+	snmp.Config = prepareV2Config()
+	snmp.ChartsInput = nil
+	snmp.Options.Port = agent.port()
+	require.True(t, snmp.Init())
+	defer snmp.Cleanup()
+
+	require.True(t, snmp.Check())
+
+	for _, id := range []string{"uptime", "cpu", "load", "memory", "disk_usage_1", "if_traffic_1"} {
+		assert.NotNilf(t, snmp.Charts().Get(id), "chart '%s'", id)
+	}
+
+	mx := snmp.Collect()
+	assert.Equal(t, int64(360000), mx["1.3.6.1.2.1.1.3.0"])
+	assert.Equal(t, int64(150), mx["1.3.6.1.4.1.2021.10.1.5.1"])
+	assert.Equal(t, int64(3000), mx["1.3.6.1.4.1.2021.9.1.8.1"])
+	assert.Equal(t, int64(100), mx[oidIfHCInOctets+".1"])
+}
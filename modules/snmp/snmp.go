@@ -4,6 +4,8 @@ package snmp
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -45,10 +47,19 @@ func New() *SNMP {
 				Version: defaultVersion.String(),
 				MaxOIDs: defaultMaxOIDs,
 			},
+			ProfilesDir: defaultProfilesDir(),
 		},
 	}
 }
 
+// defaultProfilesDir is the user profiles directory in the Netdata config directory.
+func defaultProfilesDir() string {
+	if dir := os.Getenv("NETDATA_USER_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "go.d", "snmp.profiles")
+	}
+	return ""
+}
+
 type (
 	Config struct {
 		UpdateEvery int           `yaml:"update_every"`
@@ -57,6 +68,11 @@ type (
 		User        User          `yaml:"user"`
 		Options     Options       `yaml:"options"`
 		ChartsInput []ChartConfig `yaml:"charts"`
+		TablesInput []TableConfig `yaml:"tables"`
+		Interfaces  bool          `yaml:"interfaces"`
+		Profiles    []string      `yaml:"profiles"`
+		ProfilesDir string        `yaml:"profiles_dir"`
+		CreateVnode bool          `yaml:"create_vnode"`
 	}
 	User struct {
 		Name          string `yaml:"name"`
@@ -83,6 +99,12 @@ type (
 		IndexRange []int             `yaml:"multiply_range"`
 		Dimensions []DimensionConfig `yaml:"dimensions"`
 	}
+	TableConfig struct {
+		ID       string        `yaml:"id"`
+		NameOID  string        `yaml:"name_oid"`
+		AliasOID string        `yaml:"alias_oid"`
+		Charts   []ChartConfig `yaml:"charts"`
+	}
 	DimensionConfig struct {
 		OID        string `yaml:"oid"`
 		Name       string `yaml:"name"`
@@ -99,6 +121,8 @@ type SNMP struct {
 	charts     *module.Charts
 	snmpClient gosnmp.Handler
 	oids       []string
+	tables     []*table
+	profiles   profiles
 }
 
 func (s *SNMP) Init() bool {
@@ -123,19 +147,39 @@ func (s *SNMP) Init() bool {
 	}
 	s.snmpClient = snmpClient
 
-	charts, err := newCharts(s.ChartsInput)
+	profiles, err := loadProfiles(s.ProfilesDir, s.Logger)
 	if err != nil {
-		s.Errorf("Population of charts failed: %v", err)
+		s.Errorf("profiles loading: %v", err)
 		return false
 	}
-	s.charts = charts
+	s.profiles = profiles
+
+	if s.autoProfiles() {
+		s.Info("no metrics configured, the profiles will be selected by the device sysObjectID and sysDescr")
+		return true
+	}
 
-	s.oids = s.initOIDs()
+	if err := s.setupMetrics(s.Profiles); err != nil {
+		s.Error(err)
+		return false
+	}
 
 	return true
 }
 
 func (s *SNMP) Check() bool {
+	if s.autoProfiles() {
+		names, err := s.selectProfiles()
+		if err != nil {
+			s.Error(err)
+			return false
+		}
+		s.Infof("selected profiles: %s", strings.Join(names, ", "))
+		if err := s.setupMetrics(names); err != nil {
+			s.Error(err)
+			return false
+		}
+	}
 	return len(s.Collect()) > 0
 }
 
@@ -25,14 +25,14 @@ func TestSNMP_Init(t *testing.T) {
 		prepareSNMP func() *SNMP
 		wantFail    bool
 	}{
-		"fail with default config": {
-			wantFail: true,
+		"success with default config (profiles auto-selection)": {
+			wantFail: false,
 			prepareSNMP: func() *SNMP {
 				return New()
 			},
 		},
-		"fail when 'charts' not set": {
-			wantFail: true,
+		"success when 'charts' not set (profiles auto-selection)": {
+			wantFail: false,
 			prepareSNMP: func() *SNMP {
 				snmp := New()
 				snmp.Config = prepareV2Config()
@@ -503,3 +503,73 @@ func prepareV1Config() Config {
 		},
 	}
 }
+
+func TestSNMP_VirtualNode(t *testing.T) {
+	tests := map[string]struct {
+		createVnode bool
+		prepareMock func(m *snmpmock.MockHandler)
+		wantVnode   *module.VirtualNode
+	}{
+		"disabled": {
+			prepareMock: func(m *snmpmock.MockHandler) {},
+		},
+		"sysName": {
+			createVnode: true,
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get([]string{oidSysName, oidSysDescr}).Return(&gosnmp.SnmpPacket{
+					Variables: []gosnmp.SnmpPDU{
+						{Name: "." + oidSysName, Value: []byte("router"), Type: gosnmp.OctetString},
+						{Name: "." + oidSysDescr, Value: []byte("Router OS "), Type: gosnmp.OctetString},
+					},
+				}, nil).Times(1)
+			},
+			wantVnode: &module.VirtualNode{
+				Hostname: "router",
+				Labels:   map[string]string{"_vnode_type": "snmp", "address": defaultHostname, "sys_descr": "Router OS"},
+			},
+		},
+		"no sysName": {
+			createVnode: true,
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get(gomock.Any()).Return(&gosnmp.SnmpPacket{
+					Variables: []gosnmp.SnmpPDU{
+						{Name: "." + oidSysName, Type: gosnmp.NoSuchObject},
+						{Name: "." + oidSysDescr, Type: gosnmp.NoSuchObject},
+					},
+				}, nil).Times(1)
+			},
+			wantVnode: &module.VirtualNode{
+				Hostname: defaultHostname,
+				Labels:   map[string]string{"_vnode_type": "snmp", "address": defaultHostname},
+			},
+		},
+		"Get fails": {
+			createVnode: true,
+			prepareMock: func(m *snmpmock.MockHandler) {
+				m.EXPECT().Get(gomock.Any()).Return(nil, errors.New("mock Get() error")).Times(1)
+			},
+			wantVnode: &module.VirtualNode{
+				Hostname: defaultHostname,
+				Labels:   map[string]string{"_vnode_type": "snmp", "address": defaultHostname},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSNMP, cleanup := mockInit(t)
+			defer cleanup()
+
+			newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+			defaultMockExpects(mockSNMP)
+			test.prepareMock(mockSNMP)
+
+			snmp := New()
+			snmp.Config = prepareV2Config()
+			snmp.CreateVnode = test.createVnode
+			require.True(t, snmp.Init())
+
+			assert.Equal(t, test.wantVnode, snmp.VirtualNode())
+		})
+	}
+}
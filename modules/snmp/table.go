@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// table is a walked SNMP table, every row gets its own set of charts.
+type table struct {
+	cfg     TableConfig
+	columns []string
+	charts  *module.ChartTemplate
+	names   map[string]string // row index => row name
+}
+
+func newTables(configs []TableConfig) ([]*table, error) {
+	var tables []*table
+	seen := make(map[string]bool)
+	for _, cfg := range configs {
+		if cfg.ID == "" {
+			return nil, errors.New("table 'id' not set")
+		}
+		if seen[cfg.ID] {
+			return nil, fmt.Errorf("table '%s' is duplicated", cfg.ID)
+		}
+		seen[cfg.ID] = true
+
+		tbl, err := newTable(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': %v", cfg.ID, err)
+		}
+		tables = append(tables, tbl)
+	}
+	return tables, nil
+}
+
+func newTable(cfg TableConfig) (*table, error) {
+	if len(cfg.Charts) == 0 {
+		return nil, errors.New("'charts' are required but not set")
+	}
+
+	cfg.NameOID = strings.Trim(cfg.NameOID, ".")
+	cfg.AliasOID = strings.Trim(cfg.AliasOID, ".")
+
+	charts, err := newTableCharts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := module.NewChartTemplate(*charts)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl := &table{cfg: cfg, charts: tmpl, names: make(map[string]string)}
+
+	seen := make(map[string]bool)
+	addColumn := func(oid string) {
+		if oid != "" && !seen[oid] {
+			seen[oid] = true
+			tbl.columns = append(tbl.columns, oid)
+		}
+	}
+	addColumn(cfg.NameOID)
+	addColumn(cfg.AliasOID)
+	for _, chart := range cfg.Charts {
+		for _, dim := range chart.Dimensions {
+			addColumn(strings.Trim(dim.OID, "."))
+		}
+	}
+
+	return tbl, nil
+}
+
+// newTableCharts returns the row charts, the placeholders are filled with the row labels.
+func newTableCharts(cfg TableConfig) (*module.Charts, error) {
+	charts := &module.Charts{}
+	for _, chartCfg := range cfg.Charts {
+		chart, err := newChart(chartCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		chart.ID = fmt.Sprintf("%s_%s_{{.index}}", cfg.ID, chartCfg.ID)
+		chart.Ctx = fmt.Sprintf("snmp.%s_%s", cfg.ID, chartCfg.ID)
+		chart.Title = fmt.Sprintf("%s {{.name}}", chart.Title)
+		if chart.Fam == "" {
+			chart.Fam = "{{.name}}"
+		}
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf("%s.{{.index}}", strings.TrimSuffix(dim.ID, "."))
+		}
+		if err := charts.Add(chart); err != nil {
+			return nil, err
+		}
+	}
+	return charts, nil
+}
+
+// collectTables walks the tables, adds charts for the new rows and removes charts of the gone ones.
+// A table that fails to be walked is skipped for this cycle, its rows are kept.
+func (s *SNMP) collectTables(collected map[string]int64) {
+	for _, tbl := range s.tables {
+		mx := make(map[string]int64)
+		if err := s.collectTable(tbl, mx); err != nil {
+			s.Warning(err)
+			continue
+		}
+		for k, v := range mx {
+			collected[k] = v
+		}
+	}
+}
+
+func (s *SNMP) collectTable(tbl *table, collected map[string]int64) error {
+	names := make(map[string]string)
+	aliases := make(map[string]string)
+	seen := make(map[string]bool)
+
+	for _, column := range tbl.columns {
+		prefix := column + "."
+		err := s.walk(column, func(pdu gosnmp.SnmpPDU) error {
+			index := strings.TrimPrefix(strings.TrimPrefix(pdu.Name, "."), prefix)
+			switch column {
+			case tbl.cfg.NameOID:
+				names[index] = pduString(pdu)
+				seen[index] = true
+				return nil
+			case tbl.cfg.AliasOID:
+				aliases[index] = pduString(pdu)
+				return nil
+			}
+			if v, ok := pduInt(pdu); ok {
+				collected[prefix+index] = v
+				if tbl.cfg.NameOID == "" {
+					seen[index] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot walk table '%s' column '%s': %v", tbl.cfg.ID, column, err)
+		}
+	}
+
+	for index := range seen {
+		name := names[index]
+		if name == "" {
+			name = index
+		}
+		if tbl.charts.Has(index) {
+			if tbl.names[index] == name {
+				continue
+			}
+			// the row is reused by another entity (e.g. an interface was replaced)
+			tbl.charts.Remove(index)
+		}
+
+		labels := map[string]string{"index": index, "name": name}
+		if tbl.cfg.AliasOID != "" {
+			labels["alias"] = aliases[index]
+		}
+		s.Debugf("table '%s': adding row '%s' (%s)", tbl.cfg.ID, index, name)
+		if err := tbl.charts.Add(s.charts, index, labels); err != nil {
+			s.Warning(err)
+			continue
+		}
+		tbl.names[index] = name
+	}
+
+	for _, index := range tbl.charts.Keys() {
+		if !seen[index] {
+			s.Debugf("table '%s': removing row '%s' (%s)", tbl.cfg.ID, index, tbl.names[index])
+			tbl.charts.Remove(index)
+			delete(tbl.names, index)
+		}
+	}
+
+	return nil
+}
+
+// walk retrieves the subtree using GETBULK, SNMPv1 doesn't support it and uses GETNEXT.
+func (s *SNMP) walk(oid string, walkFn gosnmp.WalkFunc) error {
+	if s.snmpClient.Version() == gosnmp.Version1 {
+		return s.snmpClient.Walk(oid, walkFn)
+	}
+	return s.snmpClient.BulkWalk(oid, walkFn)
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gosnmp/gosnmp"
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSNMP_Init_Tables(t *testing.T) {
+	tests := map[string]struct {
+		prepareConfig func() Config
+		wantFail      bool
+	}{
+		"success with 'interfaces' only": {
+			prepareConfig: func() Config {
+				cfg := prepareV2Config()
+				cfg.ChartsInput = nil
+				cfg.Interfaces = true
+				return cfg
+			},
+		},
+		"success with 'tables' only": {
+			prepareConfig: func() Config {
+				cfg := prepareV2Config()
+				cfg.ChartsInput = nil
+				cfg.TablesInput = []TableConfig{interfacesTable}
+				return cfg
+			},
+		},
+		"fail when table 'id' not set": {
+			wantFail: true,
+			prepareConfig: func() Config {
+				cfg := prepareV2Config()
+				cfg.TablesInput = []TableConfig{{Charts: interfacesTable.Charts}}
+				return cfg
+			},
+		},
+		"fail when table 'charts' not set": {
+			wantFail: true,
+			prepareConfig: func() Config {
+				cfg := prepareV2Config()
+				cfg.TablesInput = []TableConfig{{ID: "if"}}
+				return cfg
+			},
+		},
+		"success when table overrides the built-in one": {
+			prepareConfig: func() Config {
+				cfg := prepareV2Config()
+				cfg.Interfaces = true
+				cfg.TablesInput = []TableConfig{interfacesTable}
+				return cfg
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockSNMP, cleanup := mockInit(t)
+			defer cleanup()
+
+			newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+			defaultMockExpects(mockSNMP)
+
+			snmp := New()
+			snmp.Config = test.prepareConfig()
+
+			if test.wantFail {
+				assert.False(t, snmp.Init())
+			} else {
+				assert.True(t, snmp.Init())
+			}
+		})
+	}
+}
+
+func TestSNMP_Collect_Tables(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.setIfRow(1, "eth0", "uplink", 100, 200)
+	agent.setIfRow(2, "eth1", "", 300, 400)
+
+	for _, version := range []string{"1", "2c"} {
+		t.Run("SNMPv"+version, func(t *testing.T) {
+			newSNMPClient = gosnmp.NewHandler
+
+			snmp := New()
+			snmp.Config = prepareV2Config()
+			snmp.ChartsInput = nil
+			snmp.Interfaces = true
+			snmp.Options.Version = version
+			snmp.Options.Port = agent.port()
+			require.True(t, snmp.Init())
+			defer snmp.Cleanup()
+
+			mx := snmp.Collect()
+
+			assert.Equal(t, int64(100), mx[oidIfHCInOctets+".1"])
+			assert.Equal(t, int64(200), mx[oidIfHCOutOctets+".1"])
+			assert.Equal(t, int64(300), mx[oidIfHCInOctets+".2"])
+			assert.Equal(t, int64(400), mx[oidIfHCOutOctets+".2"])
+			assert.Equal(t, int64(1), mx[oidIfOperStatus+".2"])
+
+			chart := snmp.Charts().Get("if_traffic_1")
+			require.NotNil(t, chart)
+			assert.Equal(t, "Interface Traffic eth0", chart.Title)
+			assert.Equal(t, "eth0", chart.Fam)
+			assert.Equal(t, "snmp.if_traffic", chart.Ctx)
+			assert.Equal(t, oidIfHCInOctets+".1", chart.Dims[0].ID)
+			assert.Contains(t, chart.Labels, module.Label{Key: "alias", Value: "uplink"})
+			assert.NotNil(t, snmp.Charts().Get("if_operstatus_2"))
+			assert.Len(t, *snmp.Charts(), len(interfacesTable.Charts)*2)
+		})
+	}
+}
+
+func TestSNMP_Collect_TableRowsChange(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.setIfRow(1, "eth0", "", 1, 1)
+	agent.setIfRow(2, "eth1", "", 2, 2)
+
+	newSNMPClient = gosnmp.NewHandler
+	snmp := New()
+	snmp.Config = prepareV2Config()
+	snmp.ChartsInput = nil
+	snmp.Interfaces = true
+	snmp.Options.Port = agent.port()
+	require.True(t, snmp.Init())
+	defer snmp.Cleanup()
+
+	require.NotNil(t, snmp.Collect())
+	assert.Equal(t, []string{"1", "2"}, snmp.tables[0].charts.Keys())
+
+	agent.deleteIfRow(1)
+	agent.setIfRow(3, "eth2", "", 3, 3)
+	require.NotNil(t, snmp.Collect())
+	assert.Equal(t, []string{"2", "3"}, snmp.tables[0].charts.Keys())
+	assert.True(t, snmp.Charts().Get("if_traffic_1").Obsolete)
+	assert.False(t, snmp.Charts().Get("if_traffic_3").Obsolete)
+
+	// the row index is reused by another interface
+	agent.setIfRow(2, "vlan10", "", 2, 2)
+	require.NotNil(t, snmp.Collect())
+	var titles []string
+	for _, chart := range *snmp.Charts() {
+		if chart.ID == "if_traffic_2" && !chart.Obsolete {
+			titles = append(titles, chart.Title)
+		}
+	}
+	assert.Equal(t, []string{"Interface Traffic vlan10"}, titles)
+}
+
+func TestSNMP_Collect_TableWalkFails(t *testing.T) {
+	mockSNMP, cleanup := mockInit(t)
+	defer cleanup()
+
+	newSNMPClient = func() gosnmp.Handler { return mockSNMP }
+	mockSNMP.EXPECT().Version().Return(gosnmp.Version2c).AnyTimes()
+	defaultMockExpects(mockSNMP)
+
+	snmp := New()
+	snmp.Config = prepareConfigWithIndexRange(prepareV2Config, 0, 1)
+	snmp.Interfaces = true
+	require.True(t, snmp.Init())
+
+	mockSNMP.EXPECT().Get(gomock.Any()).Return(&gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{
+			{Value: 10, Type: gosnmp.Counter32},
+			{Value: 20, Type: gosnmp.Counter64},
+			{Value: 30, Type: gosnmp.Gauge32},
+			{Value: 40, Type: gosnmp.Gauge32},
+		},
+	}, nil)
+	mockSNMP.EXPECT().BulkWalk(oidIfDescr, gomock.Any()).Return(errors.New("mock BulkWalk() error"))
+
+	// the table is skipped, the scalar OIDs are collected
+	assert.Equal(t, map[string]int64{
+		"1.3.6.1.2.1.2.2.1.10.0": 10,
+		"1.3.6.1.2.1.2.2.1.16.0": 20,
+		"1.3.6.1.2.1.2.2.1.10.1": 30,
+		"1.3.6.1.2.1.2.2.1.16.1": 40,
+	}, snmp.Collect())
+	assert.Len(t, *snmp.Charts(), 2)
+}
+
+// testAgent is a minimal SNMPv1/v2c agent, it answers GET, GETNEXT and GETBULK requests.
+type testAgent struct {
+	t    *testing.T
+	conn net.PacketConn
+
+	mux  sync.Mutex
+	pdus map[string]gosnmp.SnmpPDU
+}
+
+func newTestAgent(t *testing.T) *testAgent {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	a := &testAgent{t: t, conn: conn, pdus: make(map[string]gosnmp.SnmpPDU)}
+	go a.serve()
+	t.Cleanup(func() { _ = conn.Close() })
+	return a
+}
+
+func (a *testAgent) port() int {
+	return a.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (a *testAgent) set(oid string, typ gosnmp.Asn1BER, value interface{}) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.pdus[oid] = gosnmp.SnmpPDU{Name: "." + oid, Type: typ, Value: value}
+}
+
+func (a *testAgent) setIfRow(index int, descr, alias string, in, out uint64) {
+	idx := "." + strconv.Itoa(index)
+	a.set(oidIfDescr+idx, gosnmp.OctetString, []byte(descr))
+	a.set(oidIfOperStatus+idx, gosnmp.Integer, 1)
+	a.set(oidIfInDiscards+idx, gosnmp.Counter32, uint32(0))
+	a.set(oidIfInErrors+idx, gosnmp.Counter32, uint32(0))
+	a.set(oidIfOutDiscards+idx, gosnmp.Counter32, uint32(0))
+	a.set(oidIfOutErrors+idx, gosnmp.Counter32, uint32(0))
+	a.set(oidIfHCInOctets+idx, gosnmp.Counter64, in)
+	a.set(oidIfHCInUcastPkts+idx, gosnmp.Counter64, in)
+	a.set(oidIfHCOutOctets+idx, gosnmp.Counter64, out)
+	a.set(oidIfHCOutUcastPkts+idx, gosnmp.Counter64, out)
+	a.set(oidIfAlias+idx, gosnmp.OctetString, []byte(alias))
+}
+
+func (a *testAgent) deleteIfRow(index int) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	suffix := "." + strconv.Itoa(index)
+	for oid := range a.pdus {
+		if strings.HasSuffix(oid, suffix) {
+			delete(a.pdus, oid)
+		}
+	}
+}
+
+func (a *testAgent) serve() {
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Logger: gosnmp.NewLogger(nil)}
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil {
+			a.t.Logf("test agent: decode request: %v", err)
+			continue
+		}
+		resp := &gosnmp.SnmpPacket{
+			Version:   req.Version,
+			Community: req.Community,
+			PDUType:   gosnmp.GetResponse,
+			RequestID: req.RequestID,
+			Variables: a.respond(req),
+		}
+		b, err := resp.MarshalMsg()
+		if err != nil {
+			a.t.Logf("test agent: encode response: %v", err)
+			continue
+		}
+		_, _ = a.conn.WriteTo(b, addr)
+	}
+}
+
+func (a *testAgent) respond(req *gosnmp.SnmpPacket) []gosnmp.SnmpPDU {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	var oids []string
+	for oid := range a.pdus {
+		oids = append(oids, oid)
+	}
+	sort.Slice(oids, func(i, j int) bool { return oidLess(oids[i], oids[j]) })
+
+	next := func(name string) gosnmp.SnmpPDU {
+		name = strings.TrimPrefix(name, ".")
+		i := sort.Search(len(oids), func(i int) bool { return oidLess(name, oids[i]) })
+		if i == len(oids) {
+			return gosnmp.SnmpPDU{Name: "." + name, Type: gosnmp.EndOfMibView}
+		}
+		return a.pdus[oids[i]]
+	}
+
+	var vars []gosnmp.SnmpPDU
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		for _, v := range req.Variables {
+			pdu, ok := a.pdus[strings.TrimPrefix(v.Name, ".")]
+			if !ok {
+				pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+			}
+			vars = append(vars, pdu)
+		}
+	case gosnmp.GetNextRequest:
+		for _, v := range req.Variables {
+			vars = append(vars, next(v.Name))
+		}
+	case gosnmp.GetBulkRequest:
+		names := make([]string, len(req.Variables))
+		for i, v := range req.Variables {
+			names[i] = v.Name
+		}
+		for i := 0; i < int(req.NonRepeaters) && i < len(names); i++ {
+			vars = append(vars, next(names[i]))
+		}
+		// gosnmp does not decode max-repetitions of requests (int vs uint32)
+		maxRepetitions := int(req.MaxRepetitions)
+		if maxRepetitions == 0 {
+			maxRepetitions = 10
+		}
+		for r := 0; r < maxRepetitions; r++ {
+			for i := int(req.NonRepeaters); i < len(names); i++ {
+				pdu := next(names[i])
+				names[i] = pdu.Name
+				vars = append(vars, pdu)
+			}
+		}
+	}
+	return vars
+}
+
+func oidLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
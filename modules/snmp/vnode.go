@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp
+
+import (
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	oidSysDescr = "1.3.6.1.2.1.1.1.0"
+	oidSysName  = "1.3.6.1.2.1.1.5.0"
+)
+
+// VirtualNode returns the device virtual node if 'create_vnode' is enabled.
+// The node hostname is the device sysName, or the configured hostname if sysName is not available.
+func (s *SNMP) VirtualNode() *module.VirtualNode {
+	if !s.CreateVnode {
+		return nil
+	}
+
+	vnode := &module.VirtualNode{
+		Hostname: s.Hostname,
+		Labels: map[string]string{
+			"_vnode_type": "snmp",
+			"address":     s.Hostname,
+		},
+	}
+
+	resp, err := s.snmpClient.Get([]string{oidSysName, oidSysDescr})
+	if err != nil {
+		s.Warningf("cannot get sysName, using hostname for the virtual node: %v", err)
+		return vnode
+	}
+	for _, v := range resp.Variables {
+		if v.Type != gosnmp.OctetString {
+			continue
+		}
+		value := strings.TrimSpace(string(v.Value.([]byte)))
+		if value == "" {
+			continue
+		}
+		switch strings.TrimPrefix(v.Name, ".") {
+		case oidSysName:
+			vnode.Hostname = value
+		case oidSysDescr:
+			vnode.Labels["sys_descr"] = value
+		}
+	}
+	return vnode
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp_traps
+
+import (
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+const (
+	prioTraps = module.Priority + iota
+	prioTrapsByVersion
+	prioMappedTraps
+	prioTrapTypesLastSeen
+	prioSourceTraps
+)
+
+var baseCharts = module.Charts{
+	trapsChart.Copy(),
+	trapsByVersionChart.Copy(),
+}
+
+var (
+	trapsChart = module.Chart{
+		ID:       "traps",
+		Title:    "Received traps",
+		Units:    "traps/s",
+		Fam:      "traps",
+		Ctx:      "snmp_traps.traps",
+		Priority: prioTraps,
+		Dims: module.Dims{
+			{ID: "traps", Name: "traps", Algo: module.Incremental},
+			{ID: "informs", Name: "informs", Algo: module.Incremental},
+			{ID: "rejected", Name: "rejected", Algo: module.Incremental},
+			{ID: "untracked", Name: "untracked", Algo: module.Incremental},
+		},
+	}
+	trapsByVersionChart = module.Chart{
+		ID:       "traps_by_version",
+		Title:    "Received traps by SNMP version",
+		Units:    "traps/s",
+		Fam:      "traps",
+		Ctx:      "snmp_traps.traps_by_version",
+		Type:     module.Stacked,
+		Priority: prioTrapsByVersion,
+		Dims: module.Dims{
+			{ID: "version_1", Name: "v1", Algo: module.Incremental},
+			{ID: "version_2c", Name: "v2c", Algo: module.Incremental},
+			{ID: "version_3", Name: "v3", Algo: module.Incremental},
+		},
+	}
+	mappedTrapsChart = module.Chart{
+		ID:       "mapped_traps",
+		Title:    "Mapped traps",
+		Units:    "traps/s",
+		Fam:      "traps",
+		Ctx:      "snmp_traps.mapped_traps",
+		Priority: prioMappedTraps,
+	}
+	trapTypesLastSeenChart = module.Chart{
+		ID:       "trap_types_last_seen",
+		Title:    "Time since a trap type was last seen",
+		Units:    "seconds",
+		Fam:      "trap types",
+		Ctx:      "snmp_traps.trap_types_last_seen",
+		Priority: prioTrapTypesLastSeen,
+	}
+)
+
+var (
+	sourceChartsTmpl = module.Charts{
+		sourceTrapsChartTmpl.Copy(),
+	}
+
+	sourceTrapsChartTmpl = module.Chart{
+		ID:       "source_{{.source}}_traps",
+		Title:    "Received traps by trap OID",
+		Units:    "traps/s",
+		Fam:      "sources",
+		Ctx:      "snmp_traps.source_traps",
+		Type:     module.Stacked,
+		Priority: prioSourceTraps,
+	}
+)
+
+func (s *SNMPTraps) addMappedChart() {
+	if len(s.mappings) == 0 {
+		return
+	}
+	chart := mappedTrapsChart.Copy()
+	for _, m := range s.mappings {
+		chart.Dims = append(chart.Dims, &module.Dim{ID: "mapped_" + m.name, Name: m.name, Algo: module.Incremental})
+	}
+	chart.Dims = append(chart.Dims, &module.Dim{ID: "mapped_unmatched", Name: "unmatched", Algo: module.Incremental})
+	if err := s.charts.Add(chart); err != nil {
+		s.Warning(err)
+	}
+}
+
+func (s *SNMPTraps) addSourceCharts(source string) {
+	labels := map[string]string{"source": source}
+	if err := s.sourceCharts.Add(s.charts, source, labels); err != nil {
+		s.Warning(err)
+	}
+}
+
+func (s *SNMPTraps) removeSourceCharts(source string) {
+	s.sourceCharts.Remove(source)
+}
+
+func (s *SNMPTraps) addSourceTrapDim(source, oid string) {
+	chart := s.charts.Get("source_" + source + "_traps")
+	if chart == nil {
+		return
+	}
+	dim := &module.Dim{ID: sourceTrapDimID(source, oid), Name: oid, Algo: module.Incremental}
+	if err := chart.AddDim(dim); err != nil {
+		s.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
+
+func (s *SNMPTraps) addTrapTypeDim(oid string) {
+	chart := s.charts.Get(trapTypesLastSeenChart.ID)
+	if chart == nil {
+		chart = trapTypesLastSeenChart.Copy()
+		if err := s.charts.Add(chart); err != nil {
+			s.Warning(err)
+			return
+		}
+	}
+	if err := chart.AddDim(&module.Dim{ID: "last_seen_" + oid, Name: oid}); err != nil {
+		s.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
+
+func (s *SNMPTraps) removeSourceTrapDim(source, oid string) {
+	chart := s.charts.Get("source_" + source + "_traps")
+	if chart == nil {
+		return
+	}
+	if err := chart.MarkDimRemove(sourceTrapDimID(source, oid), true); err != nil {
+		s.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
+
+func (s *SNMPTraps) removeTrapTypeDim(oid string) {
+	chart := s.charts.Get(trapTypesLastSeenChart.ID)
+	if chart == nil {
+		return
+	}
+	if err := chart.MarkDimRemove("last_seen_"+oid, true); err != nil {
+		s.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
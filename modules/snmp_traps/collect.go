@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp_traps
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	oidSnmpTrapOID     = "1.3.6.1.6.3.1.1.4.1.0"
+	oidSnmpTrapsPrefix = "1.3.6.1.6.3.1.1.5"
+)
+
+type trapStats struct {
+	traps      int64
+	informs    int64
+	rejected   int64
+	untracked  int64
+	versions   map[gosnmp.SnmpVersion]int64
+	mapped     map[string]int64
+	sources    map[string]map[string]int64 // source => trap OID => traps
+	sourceSeen map[string]time.Time        // source => time
+	lastSeen   map[string]time.Time        // trap OID => time
+}
+
+func newTrapStats() *trapStats {
+	return &trapStats{
+		versions:   make(map[gosnmp.SnmpVersion]int64),
+		mapped:     make(map[string]int64),
+		sources:    make(map[string]map[string]int64),
+		sourceSeen: make(map[string]time.Time),
+		lastSeen:   make(map[string]time.Time),
+	}
+}
+
+// handleTrap is called by the trap listener for every received trap and inform.
+func (s *SNMPTraps) handleTrap(p *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if p.Version != gosnmp.Version3 && s.Community != "" && p.Community != s.Community {
+		s.stats.rejected++
+		return
+	}
+
+	if p.PDUType == gosnmp.InformRequest {
+		s.stats.informs++
+	} else {
+		s.stats.traps++
+	}
+	s.stats.versions[p.Version]++
+
+	oid := trapOID(p)
+	source := addr.IP.String()
+
+	if s.isTracked(source, oid) {
+		if s.stats.sources[source] == nil {
+			s.stats.sources[source] = make(map[string]int64)
+		}
+		s.stats.sources[source][oid]++
+		now := s.now()
+		s.stats.sourceSeen[source] = now
+		s.stats.lastSeen[oid] = now
+	} else {
+		s.stats.untracked++
+	}
+
+	if len(s.mappings) > 0 {
+		name := "unmatched"
+		for _, m := range s.mappings {
+			if m.oid.MatchString(oid) {
+				name = m.name
+				break
+			}
+		}
+		s.stats.mapped[name]++
+	}
+}
+
+func (s *SNMPTraps) collect() (map[string]int64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+	s.expireIdle(now)
+
+	mx := map[string]int64{
+		"traps":      s.stats.traps,
+		"informs":    s.stats.informs,
+		"rejected":   s.stats.rejected,
+		"untracked":  s.stats.untracked,
+		"version_1":  s.stats.versions[gosnmp.Version1],
+		"version_2c": s.stats.versions[gosnmp.Version2c],
+		"version_3":  s.stats.versions[gosnmp.Version3],
+	}
+
+	if len(s.mappings) > 0 {
+		for _, m := range s.mappings {
+			mx["mapped_"+m.name] = s.stats.mapped[m.name]
+		}
+		mx["mapped_unmatched"] = s.stats.mapped["unmatched"]
+	}
+
+	for source, oids := range s.stats.sources {
+		if !s.sourceCharts.Has(source) {
+			s.addSourceCharts(source)
+		}
+		for oid, n := range oids {
+			id := sourceTrapDimID(source, oid)
+			if !s.seenDims[id] {
+				s.seenDims[id] = true
+				s.addSourceTrapDim(source, oid)
+			}
+			mx[id] = n
+		}
+	}
+
+	for oid, t := range s.stats.lastSeen {
+		id := "last_seen_" + oid
+		if !s.seenDims[id] {
+			s.seenDims[id] = true
+			s.addTrapTypeDim(oid)
+		}
+		mx[id] = int64(now.Sub(t).Seconds())
+	}
+
+	return mx, nil
+}
+
+// isTracked reports whether the trap is counted per source and trap OID. New sources and trap OIDs are not tracked
+// once the limits are reached, otherwise anyone able to send traps could grow the number of charts without limit.
+func (s *SNMPTraps) isTracked(source, oid string) bool {
+	if _, ok := s.stats.sources[source]; !ok && s.MaxSources > 0 && len(s.stats.sources) >= s.MaxSources {
+		return false
+	}
+	if _, ok := s.stats.lastSeen[oid]; !ok && s.MaxTrapTypes > 0 && len(s.stats.lastSeen) >= s.MaxTrapTypes {
+		return false
+	}
+	return true
+}
+
+// expireIdle removes the sources and trap OIDs that have not been seen for 'expire_after', freeing room for new ones.
+func (s *SNMPTraps) expireIdle(now time.Time) {
+	if s.ExpireAfter.Duration <= 0 {
+		return
+	}
+
+	for source, t := range s.stats.sourceSeen {
+		if now.Sub(t) < s.ExpireAfter.Duration {
+			continue
+		}
+		s.Debugf("source '%s' has not sent traps for %s, removing it", source, s.ExpireAfter.Duration)
+		s.removeSourceCharts(source)
+		for oid := range s.stats.sources[source] {
+			delete(s.seenDims, sourceTrapDimID(source, oid))
+		}
+		delete(s.stats.sources, source)
+		delete(s.stats.sourceSeen, source)
+	}
+
+	for oid, t := range s.stats.lastSeen {
+		if now.Sub(t) < s.ExpireAfter.Duration {
+			continue
+		}
+		s.Debugf("trap '%s' has not been seen for %s, removing it", oid, s.ExpireAfter.Duration)
+		s.removeTrapTypeDim(oid)
+		delete(s.seenDims, "last_seen_"+oid)
+		delete(s.stats.lastSeen, oid)
+
+		for source, oids := range s.stats.sources {
+			if _, ok := oids[oid]; !ok {
+				continue
+			}
+			s.removeSourceTrapDim(source, oid)
+			delete(s.seenDims, sourceTrapDimID(source, oid))
+			delete(oids, oid)
+		}
+	}
+}
+
+func sourceTrapDimID(source, oid string) string {
+	return "source_" + source + "_oid_" + oid
+}
+
+// trapOID returns the trap OID, SNMPv1 traps are translated as per RFC 3584 section 3.1.
+func trapOID(p *gosnmp.SnmpPacket) string {
+	if p.PDUType == gosnmp.Trap {
+		if p.GenericTrap >= 0 && p.GenericTrap < 6 {
+			return fmt.Sprintf("%s.%d", oidSnmpTrapsPrefix, p.GenericTrap+1)
+		}
+		return fmt.Sprintf("%s.0.%d", strings.Trim(p.Enterprise, "."), p.SpecificTrap)
+	}
+	for _, v := range p.Variables {
+		if strings.TrimPrefix(v.Name, ".") != oidSnmpTrapOID {
+			continue
+		}
+		if oid, ok := v.Value.(string); ok && oid != "" {
+			return strings.TrimPrefix(oid, ".")
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp_traps
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/netdata/go.d.plugin/logger"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const listenTimeout = time.Second * 5
+
+func (s *SNMPTraps) validateConfig() error {
+	if s.Address == "" {
+		return errors.New("'address' is required but not set")
+	}
+	return nil
+}
+
+func (s *SNMPTraps) initMappings() ([]trapMapping, error) {
+	var mappings []trapMapping
+	seen := make(map[string]bool)
+	for i, cfg := range s.Traps {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("trap mapping #%d: 'name' is required but not set", i+1)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("trap mapping '%s' is duplicated", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		if cfg.OID == "" {
+			return nil, fmt.Errorf("trap mapping '%s': 'oid' is required but not set", cfg.Name)
+		}
+		m, err := matcher.Parse(cfg.OID)
+		if err != nil {
+			return nil, fmt.Errorf("trap mapping '%s': %v", cfg.Name, err)
+		}
+		mappings = append(mappings, trapMapping{name: cfg.Name, oid: m})
+	}
+	return mappings, nil
+}
+
+// initListenerParams returns the trap decoding parameters. SNMPv1/v2c traps are always accepted,
+// SNMPv3 traps only if the user is set.
+func (s *SNMPTraps) initListenerParams() (*gosnmp.GoSNMP, error) {
+	params := &gosnmp.GoSNMP{
+		Version:   gosnmp.Version2c,
+		Community: s.Community,
+		Logger:    gosnmp.NewLogger(listenerLogger{s.Logger}),
+		// keeps SNMPv3 traps decoding from dereferencing nil parameters when no user is set
+		SecurityParameters: &gosnmp.UsmSecurityParameters{},
+	}
+
+	if s.User.Name == "" {
+		return params, nil
+	}
+
+	msgFlags, usm, err := s.User.SecurityParameters()
+	if err != nil {
+		return nil, err
+	}
+	params.Version = gosnmp.Version3
+	params.SecurityModel = gosnmp.UserSecurityModel
+	params.MsgFlags = msgFlags
+	params.SecurityParameters = usm
+	return params, nil
+}
+
+func (s *SNMPTraps) startListener(params *gosnmp.GoSNMP) error {
+	tl := gosnmp.NewTrapListener()
+	tl.Params = params
+	tl.OnNewTrap = s.handleTrap
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- tl.Listen(s.Address) }()
+
+	select {
+	case <-tl.Listening():
+		s.listener = tl
+		return nil
+	case err := <-errCh:
+		return err
+	case <-time.After(listenTimeout):
+		tl.Close()
+		return fmt.Errorf("timeout waiting for listening on %s", s.Address)
+	}
+}
+
+// listenerLogger passes the trap listener messages (decoding and authentication errors) to the debug log.
+type listenerLogger struct{ *logger.Logger }
+
+func (l listenerLogger) Print(v ...interface{}) {
+	l.Debug(v...)
+}
+
+func (l listenerLogger) Printf(format string, v ...interface{}) {
+	l.Debugf(format, v...)
+}
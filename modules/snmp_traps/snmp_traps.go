@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp_traps
+
+import (
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/modules/snmp"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func init() {
+	module.Register("snmp_traps", module.Creator{
+		Defaults: module.Defaults{
+			Disabled: true, // listens on the privileged port 162 by default
+		},
+		Create: func() module.Module { return New() },
+	})
+}
+
+const (
+	defaultMaxSources   = 50
+	defaultMaxTrapTypes = 100
+	defaultExpireAfter  = time.Hour * 24
+)
+
+func New() *SNMPTraps {
+	return &SNMPTraps{
+		Config: Config{
+			Address:      "0.0.0.0:162",
+			MaxSources:   defaultMaxSources,
+			MaxTrapTypes: defaultMaxTrapTypes,
+			ExpireAfter:  web.Duration{Duration: defaultExpireAfter},
+		},
+		charts:       baseCharts.Copy(),
+		sourceCharts: module.MustNewChartTemplate(sourceChartsTmpl),
+		stats:        newTrapStats(),
+		now:          time.Now,
+		seenDims:     make(map[string]bool),
+	}
+}
+
+type (
+	Config struct {
+		UpdateEvery  int          `yaml:"update_every"`
+		Address      string       `yaml:"address"`
+		Community    string       `yaml:"community"`
+		User         snmp.User    `yaml:"user"`
+		Traps        []TrapConfig `yaml:"traps"`
+		MaxSources   int          `yaml:"max_sources"`
+		MaxTrapTypes int          `yaml:"max_trap_types"`
+		ExpireAfter  web.Duration `yaml:"expire_after"`
+	}
+	// TrapConfig maps the traps whose OID matches the pkg/matcher expression to a dimension.
+	TrapConfig struct {
+		Name string `yaml:"name"`
+		OID  string `yaml:"oid"`
+	}
+)
+
+type (
+	SNMPTraps struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		charts       *module.Charts
+		sourceCharts *module.ChartTemplate
+
+		listener *gosnmp.TrapListener
+		mappings []trapMapping
+
+		mux   sync.Mutex
+		stats *trapStats
+		now   func() time.Time
+
+		seenDims map[string]bool
+	}
+	trapMapping struct {
+		name string
+		oid  matcher.Matcher
+	}
+)
+
+func (s *SNMPTraps) Init() bool {
+	if err := s.validateConfig(); err != nil {
+		s.Errorf("config validation: %v", err)
+		return false
+	}
+
+	mappings, err := s.initMappings()
+	if err != nil {
+		s.Errorf("traps mapping initialization: %v", err)
+		return false
+	}
+	s.mappings = mappings
+	s.addMappedChart()
+
+	params, err := s.initListenerParams()
+	if err != nil {
+		s.Errorf("trap listener initialization: %v", err)
+		return false
+	}
+
+	if err := s.startListener(params); err != nil {
+		s.Errorf("trap listener: %v", err)
+		return false
+	}
+	s.Infof("listening for traps on %s", s.Address)
+
+	return true
+}
+
+func (s *SNMPTraps) Check() bool {
+	return len(s.Collect()) > 0
+}
+
+func (s *SNMPTraps) Charts() *module.Charts {
+	return s.charts
+}
+
+func (s *SNMPTraps) Collect() map[string]int64 {
+	mx, err := s.collect()
+	if err != nil {
+		s.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
+
+func (s *SNMPTraps) Cleanup() {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+}
@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package snmp_traps
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/modules/snmp"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestSNMPTraps_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success on v1/v2c config": {
+			config: Config{Address: freeUDPAddr(t), Community: "public"},
+		},
+		"success on v3 config": {
+			config: Config{Address: freeUDPAddr(t), User: prepareUser()},
+		},
+		"success on traps mapping": {
+			config: Config{Address: freeUDPAddr(t), Traps: []TrapConfig{{Name: "link", OID: "* 1.3.6.1.6.3.1.1.5.[34]"}}},
+		},
+		"fail when 'address' not set": {
+			wantFail: true,
+			config:   Config{},
+		},
+		"fail when 'address' is invalid": {
+			wantFail: true,
+			config:   Config{Address: "127.0.0.1:invalid"},
+		},
+		"fail when user security level is invalid": {
+			wantFail: true,
+			config: Config{Address: freeUDPAddr(t), User: func() snmp.User {
+				u := prepareUser()
+				u.SecurityLevel = "invalid"
+				return u
+			}()},
+		},
+		"fail when trap mapping 'name' not set": {
+			wantFail: true,
+			config:   Config{Address: freeUDPAddr(t), Traps: []TrapConfig{{OID: "*"}}},
+		},
+		"fail when trap mapping 'oid' is invalid": {
+			wantFail: true,
+			config:   Config{Address: freeUDPAddr(t), Traps: []TrapConfig{{Name: "link", OID: "~ ("}}},
+		},
+		"fail when trap mapping is duplicated": {
+			wantFail: true,
+			config: Config{Address: freeUDPAddr(t), Traps: []TrapConfig{
+				{Name: "link", OID: "*"},
+				{Name: "link", OID: "*"},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			traps := New()
+			traps.Config = test.config
+			defer traps.Cleanup()
+
+			if test.wantFail {
+				assert.False(t, traps.Init())
+			} else {
+				assert.True(t, traps.Init())
+			}
+		})
+	}
+}
+
+func TestSNMPTraps_Check(t *testing.T) {
+	traps := New()
+	traps.Address = freeUDPAddr(t)
+	require.True(t, traps.Init())
+	defer traps.Cleanup()
+
+	assert.True(t, traps.Check())
+}
+
+func TestSNMPTraps_Cleanup(t *testing.T) {
+	traps := New()
+	assert.NotPanics(t, traps.Cleanup)
+
+	traps.Address = freeUDPAddr(t)
+	require.True(t, traps.Init())
+	traps.Cleanup()
+
+	// the address is free again
+	conn, err := net.ListenPacket("udp", traps.Address)
+	require.NoError(t, err)
+	_ = conn.Close()
+}
+
+func TestSNMPTraps_Collect(t *testing.T) {
+	now := time.Now()
+	traps := New()
+	traps.Address = freeUDPAddr(t)
+	traps.Community = "public"
+	traps.User = prepareUser()
+	traps.Traps = []TrapConfig{
+		{Name: "link", OID: "* 1.3.6.1.6.3.1.1.5.[34]"},
+		{Name: "ups", OID: "* 1.3.6.1.4.1.318.*"},
+	}
+	traps.now = func() time.Time { return now }
+	require.True(t, traps.Init())
+	defer traps.Cleanup()
+
+	sender := newSender(t, traps.Address)
+
+	// v2c linkDown
+	sender.send(t, gosnmp.Version2c, "public", v2Trap("1.3.6.1.6.3.1.1.5.3"))
+	// v2c linkUp, twice
+	sender.send(t, gosnmp.Version2c, "public", v2Trap("1.3.6.1.6.3.1.1.5.4"))
+	sender.send(t, gosnmp.Version2c, "public", v2Trap("1.3.6.1.6.3.1.1.5.4"))
+	// v1 enterprise specific (APC UPS on battery)
+	sender.send(t, gosnmp.Version1, "public", gosnmp.SnmpTrap{
+		Enterprise:   ".1.3.6.1.4.1.318",
+		AgentAddress: "127.0.0.1",
+		GenericTrap:  6,
+		SpecificTrap: 5,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.4.1.318.2.3.10.0", Type: gosnmp.Integer, Value: 1},
+		},
+	})
+	// v2c with a wrong community
+	sender.send(t, gosnmp.Version2c, "private", v2Trap("1.3.6.1.6.3.1.1.5.3"))
+	// v3 coldStart
+	sender.send(t, gosnmp.Version3, "", v2Trap("1.3.6.1.6.3.1.1.5.1"))
+
+	var mx map[string]int64
+	require.Eventually(t, func() bool {
+		mx = traps.Collect()
+		return mx["traps"]+mx["rejected"] == 6
+	}, time.Second*5, time.Millisecond*10, "traps aren't received")
+
+	now = now.Add(time.Second * 30)
+	mx = traps.Collect()
+
+	expected := map[string]int64{
+		"traps":      5,
+		"informs":    0,
+		"rejected":   1,
+		"untracked":  0,
+		"version_1":  1,
+		"version_2c": 3,
+		"version_3":  1,
+
+		"mapped_link":      3,
+		"mapped_ups":       1,
+		"mapped_unmatched": 1,
+
+		"source_127.0.0.1_oid_1.3.6.1.6.3.1.1.5.1": 1,
+		"source_127.0.0.1_oid_1.3.6.1.6.3.1.1.5.3": 1,
+		"source_127.0.0.1_oid_1.3.6.1.6.3.1.1.5.4": 2,
+		"source_127.0.0.1_oid_1.3.6.1.4.1.318.0.5": 1,
+
+		"last_seen_1.3.6.1.6.3.1.1.5.1": 30,
+		"last_seen_1.3.6.1.6.3.1.1.5.3": 30,
+		"last_seen_1.3.6.1.6.3.1.1.5.4": 30,
+		"last_seen_1.3.6.1.4.1.318.0.5": 30,
+	}
+	assert.Equal(t, expected, mx)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, traps, mx)
+}
+
+func TestSNMPTraps_Collect_Limits(t *testing.T) {
+	traps := New()
+	traps.MaxSources = 2
+	traps.MaxTrapTypes = 2
+
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.3"), udpAddr("192.0.2.1"))
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.4"), udpAddr("192.0.2.2"))
+	// a new source over the limit
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.3"), udpAddr("192.0.2.3"))
+	// a new trap OID over the limit
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.5"), udpAddr("192.0.2.1"))
+	// known source and trap OID
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.4"), udpAddr("192.0.2.1"))
+
+	mx := traps.Collect()
+
+	expected := map[string]int64{
+		"traps":      5,
+		"informs":    0,
+		"rejected":   0,
+		"untracked":  2,
+		"version_1":  0,
+		"version_2c": 5,
+		"version_3":  0,
+
+		"source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.3": 1,
+		"source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.4": 1,
+		"source_192.0.2.2_oid_1.3.6.1.6.3.1.1.5.4": 1,
+
+		"last_seen_1.3.6.1.6.3.1.1.5.3": 0,
+		"last_seen_1.3.6.1.6.3.1.1.5.4": 0,
+	}
+	assert.Equal(t, expected, mx)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, traps, mx)
+}
+
+func TestSNMPTraps_Collect_ExpiresIdle(t *testing.T) {
+	now := time.Now()
+	traps := New()
+	traps.MaxSources = 1
+	traps.ExpireAfter = web.Duration{Duration: time.Minute}
+	traps.now = func() time.Time { return now }
+
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.3"), udpAddr("192.0.2.1"))
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.4"), udpAddr("192.0.2.1"))
+	require.NotNil(t, traps.Collect())
+
+	now = now.Add(time.Second * 30)
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.4"), udpAddr("192.0.2.1"))
+	require.NotNil(t, traps.Collect())
+
+	// linkDown is idle for a minute, the source is not
+	now = now.Add(time.Second * 30)
+	mx := traps.Collect()
+
+	assert.NotContains(t, mx, "source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.3")
+	assert.NotContains(t, mx, "last_seen_1.3.6.1.6.3.1.1.5.3")
+	assert.Equal(t, int64(2), mx["source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.4"])
+	assert.True(t, traps.Charts().Get("source_192.0.2.1_traps").GetDim("source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.3").Obsolete)
+	assert.True(t, traps.Charts().Get("trap_types_last_seen").GetDim("last_seen_1.3.6.1.6.3.1.1.5.3").Obsolete)
+
+	// the source is idle for a minute, its charts are removed and there is room for a new one
+	now = now.Add(time.Second * 30)
+	mx = traps.Collect()
+
+	assert.NotContains(t, mx, "source_192.0.2.1_oid_1.3.6.1.6.3.1.1.5.4")
+	assert.NotContains(t, mx, "last_seen_1.3.6.1.6.3.1.1.5.4")
+	assert.True(t, traps.Charts().Get("source_192.0.2.1_traps").Obsolete)
+
+	traps.handleTrap(v2Packet("1.3.6.1.6.3.1.1.5.3"), udpAddr("192.0.2.2"))
+	mx = traps.Collect()
+
+	assert.Equal(t, int64(0), mx["untracked"])
+	assert.Equal(t, int64(1), mx["source_192.0.2.2_oid_1.3.6.1.6.3.1.1.5.3"])
+}
+
+func TestTrapOID(t *testing.T) {
+	tests := map[string]struct {
+		packet *gosnmp.SnmpPacket
+		want   string
+	}{
+		"v1 generic": {
+			packet: &gosnmp.SnmpPacket{PDUType: gosnmp.Trap, SnmpTrap: gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.9", GenericTrap: 2}},
+			want:   "1.3.6.1.6.3.1.1.5.3",
+		},
+		"v1 enterprise specific": {
+			packet: &gosnmp.SnmpPacket{PDUType: gosnmp.Trap, SnmpTrap: gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.9", GenericTrap: 6, SpecificTrap: 1}},
+			want:   "1.3.6.1.4.1.9.0.1",
+		},
+		"v2c": {
+			packet: &gosnmp.SnmpPacket{PDUType: gosnmp.SNMPv2Trap, Variables: v2Trap("1.3.6.1.6.3.1.1.5.4").Variables},
+			want:   "1.3.6.1.6.3.1.1.5.4",
+		},
+		"v2c without snmpTrapOID": {
+			packet: &gosnmp.SnmpPacket{PDUType: gosnmp.SNMPv2Trap},
+			want:   "unknown",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, trapOID(test.packet))
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, s *SNMPTraps, mx map[string]int64) {
+	for _, chart := range *s.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+	}
+}
+
+func prepareUser() snmp.User {
+	return snmp.User{
+		Name:          "netdata",
+		SecurityLevel: "authPriv",
+		AuthProto:     "sha",
+		AuthKey:       "auth_passphrase",
+		PrivProto:     "aes",
+		PrivKey:       "priv_passphrase",
+	}
+}
+
+func v2Trap(oid string) gosnmp.SnmpTrap {
+	return gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: "." + oid},
+		},
+	}
+}
+
+func v2Packet(oid string) *gosnmp.SnmpPacket {
+	return &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap, Variables: v2Trap(oid).Variables}
+}
+
+func udpAddr(ip string) *net.UDPAddr {
+	return &net.UDPAddr{IP: net.ParseIP(ip), Port: 162}
+}
+
+type sender struct {
+	host string
+	port uint16
+}
+
+func newSender(t *testing.T, address string) *sender {
+	host, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return &sender{host: host, port: uint16(p)}
+}
+
+func (s *sender) send(t *testing.T, version gosnmp.SnmpVersion, community string, trap gosnmp.SnmpTrap) {
+	g := &gosnmp.GoSNMP{
+		Target:    s.host,
+		Port:      s.port,
+		Version:   version,
+		Community: community,
+		Timeout:   time.Second,
+		Retries:   0,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if version == gosnmp.Version3 {
+		user := prepareUser()
+		msgFlags, usm, err := user.SecurityParameters()
+		require.NoError(t, err)
+		usm.AuthoritativeEngineID = "\x80\x00\x1f\x88\x80\x01\x02\x03\x04"
+		usm.AuthoritativeEngineBoots = 1
+		usm.AuthoritativeEngineTime = 1
+		g.SecurityModel = gosnmp.UserSecurityModel
+		g.MsgFlags = msgFlags
+		g.SecurityParameters = usm
+	}
+	require.NoError(t, g.Connect())
+	defer func() { _ = g.Conn.Close() }()
+
+	_, err := g.SendTrap(trap)
+	require.NoError(t, err)
+}
+
+func freeUDPAddr(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	return conn.LocalAddr().String()
+}
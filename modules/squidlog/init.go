@@ -4,23 +4,66 @@ package squidlog
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
 )
 
+// validateConfig rejects backfill together with persisted offsets, the rotated files would be counted again
+// after every restart and the current file lines after the persisted offset twice.
+func (s *SquidLog) validateConfig() error {
+	if s.Backfill && s.PersistOffsets {
+		return errors.New("'backfill' and 'persist_offsets' can't be used together")
+	}
+	return nil
+}
+
 func (s *SquidLog) createLogReader() error {
 	s.Cleanup()
 	s.Debug("starting log reader creating")
 
-	reader, err := logs.Open(s.Path, s.ExcludePath, s.Logger)
+	cfg := logs.ReaderConfig{
+		Path:        s.Path,
+		ExcludePath: s.ExcludePath,
+		FollowAll:   s.FollowAllFiles,
+	}
+	if s.PersistOffsets {
+		if cfg.StateDir = logs.DefaultStateDir(); cfg.StateDir == "" {
+			s.Warning("can't persist offsets: netdata lib directory is not set")
+		}
+	}
+	// the backfill reader is opened first, it reads the current file up to its size at the open time
+	// and the log reader starts at the end of the file, so no line is read twice
+	var backfill *logs.BackfillReader
+	if s.Backfill {
+		var err error
+		backfill, err = logs.OpenBackfill(logs.BackfillConfig{
+			Path:        s.Path,
+			ExcludePath: s.ExcludePath,
+			MaxAge:      s.BackfillMaxAge.Duration,
+			MaxSize:     s.BackfillMaxSize,
+		}, s.Logger)
+		if err != nil {
+			return fmt.Errorf("creating backfill reader: %v", err)
+		}
+		s.Debugf("created backfill reader, files %v", backfill.Filenames())
+	}
+
+	reader, err := logs.OpenWithConfig(cfg, s.Logger)
 	if err != nil {
+		_ = backfill.Close()
 		return fmt.Errorf("creating log reader: %v", err)
 	}
 
 	s.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
 	s.file = reader
+	s.backfill = backfill
+	s.input = reader
+	if backfill != nil {
+		s.input = logs.FollowAfterBackfill(backfill, reader)
+	}
 	return nil
 }
 
@@ -34,7 +77,7 @@ func (s *SquidLog) createParser() error {
 	lastLine = bytes.TrimRight(lastLine, "\n")
 	s.Debugf("last line: '%s'", string(lastLine))
 
-	s.parser, err = logs.NewParser(s.Parser, s.file)
+	s.parser, err = logs.NewParser(s.Parser, s.input)
 	if err != nil {
 		return fmt.Errorf("create parser: %v", err)
 	}
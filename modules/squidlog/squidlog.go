@@ -3,7 +3,10 @@
 package squidlog
 
 import (
+	"io"
+
 	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -38,18 +41,25 @@ func New() *SquidLog {
 
 type (
 	Config struct {
-		Parser      logs.ParserConfig `yaml:",inline"`
-		Path        string            `yaml:"path"`
-		ExcludePath string            `yaml:"exclude_path"`
+		Parser          logs.ParserConfig `yaml:",inline"`
+		Path            string            `yaml:"path"`
+		ExcludePath     string            `yaml:"exclude_path"`
+		FollowAllFiles  bool              `yaml:"follow_all_files"`
+		PersistOffsets  bool              `yaml:"persist_offsets"`
+		Backfill        bool              `yaml:"backfill"`
+		BackfillMaxAge  web.Duration      `yaml:"backfill_max_age"`
+		BackfillMaxSize int64             `yaml:"backfill_max_size"`
 	}
 
 	SquidLog struct {
 		module.Base
 		Config `yaml:",inline"`
 
-		file   *logs.Reader
-		parser logs.Parser
-		line   *logLine
+		file     *logs.Reader
+		backfill *logs.BackfillReader
+		input    io.Reader
+		parser   logs.Parser
+		line     *logLine
 
 		mx     *metricsData
 		charts *module.Charts
@@ -57,6 +67,10 @@ type (
 )
 
 func (s *SquidLog) Init() bool {
+	if err := s.validateConfig(); err != nil {
+		s.Error("init failed: ", err)
+		return false
+	}
 	s.line = newEmptyLogLine()
 	s.mx = newMetricsData()
 	return true
@@ -101,4 +115,7 @@ func (s *SquidLog) Cleanup() {
 	if s.file != nil {
 		_ = s.file.Close()
 	}
+	if s.backfill != nil {
+		_ = s.backfill.Close()
+	}
 }
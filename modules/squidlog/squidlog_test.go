@@ -4,7 +4,9 @@ package squidlog
 
 import (
 	"bytes"
+	"compress/gzip"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
@@ -64,6 +66,54 @@ func TestSquid_Check_ErrorOnCreatingParserZeroKnownFields(t *testing.T) {
 	assert.False(t, squid.Check())
 }
 
+func TestSquidLog_Init_ErrorOnBackfillWithPersistOffsets(t *testing.T) {
+	squid := New()
+	squid.Backfill = true
+	squid.PersistOffsets = true
+
+	assert.False(t, squid.Init())
+}
+
+func TestSquidLog_Collect_Backfill(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	data, err := os.ReadFile("testdata/access.log")
+	require.NoError(t, err)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(data)
+	require.NoError(t, zw.Close())
+	// the rotated file is read even though the default 'exclude_path' excludes '*.gz'
+	require.NoError(t, os.WriteFile(path+".1.gz", gz.Bytes(), 0644))
+
+	squid := New()
+	defer squid.Cleanup()
+	squid.Path = path
+	squid.Backfill = true
+	require.True(t, squid.Init())
+	require.True(t, squid.Check())
+
+	mx := squid.Collect()
+
+	assert.Equal(t, int64(500*2), mx["requests"])
+	assert.Equal(t, int64(16*2), mx["unmatched"])
+
+	// new lines are followed after the backfill
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.Write(data)
+	_ = f.Close()
+	require.NoError(t, err)
+
+	mx = squid.Collect()
+
+	assert.Equal(t, int64(500*3), mx["requests"])
+}
+
 func TestSquidLog_Charts(t *testing.T) {
 	assert.Nil(t, New().Charts())
 
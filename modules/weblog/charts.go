@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/metrics"
 )
 
 type (
@@ -33,8 +34,10 @@ const (
 
 	prioReqProcTime
 	prioRespTimeHist
+	prioRespTimeQuantiles
 	prioUpsRespTime
 	prioUpsRespTimeHist
+	prioUpsRespTimeQuantiles
 
 	prioUniqIP
 
@@ -51,6 +54,7 @@ const (
 	prioReqCustomTimeField     // chart per custom time field, alphabetical order
 	prioReqCustomTimeFieldHist // histogram chart per custom time field
 	prioReqURLPattern
+	prioURLPatternMatcherCache
 	prioURLPatternStats // 3 charts per url pattern, alphabetical order
 )
 
@@ -214,6 +218,14 @@ var (
 		Ctx:      "web_log.requests_processing_time_histogram",
 		Priority: prioRespTimeHist,
 	}
+	reqProcTimeQuantiles = Chart{
+		ID:       "request_processing_time_quantiles",
+		Title:    "Request Processing Time Quantiles",
+		Units:    "milliseconds",
+		Fam:      "timings",
+		Ctx:      "web_log.request_processing_time_quantiles",
+		Priority: prioRespTimeQuantiles,
+	}
 )
 
 // Upstream
@@ -239,6 +251,14 @@ var (
 		Ctx:      "web_log.upstream_responses_time_histogram",
 		Priority: prioUpsRespTimeHist,
 	}
+	upsRespTimeQuantiles = Chart{
+		ID:       "upstream_response_time_quantiles",
+		Title:    "Upstream Response Time Quantiles",
+		Units:    "milliseconds",
+		Fam:      "timings",
+		Ctx:      "web_log.upstream_response_time_quantiles",
+		Priority: prioUpsRespTimeQuantiles,
+	}
 )
 
 // Clients
@@ -353,6 +373,19 @@ var (
 		Type:     module.Stacked,
 		Priority: prioReqURLPattern,
 	}
+	urlPatternMatcherCache = Chart{
+		ID:       "url_pattern_matcher_cache",
+		Title:    "URL Patterns Matcher Cache",
+		Units:    "events/s",
+		Fam:      "url ptn",
+		Ctx:      "web_log.url_pattern_matcher_cache",
+		Priority: prioURLPatternMatcherCache,
+		Dims: Dims{
+			{ID: "url_pattern_cache_hits", Name: "hits", Algo: module.Incremental},
+			{ID: "url_pattern_cache_misses", Name: "misses", Algo: module.Incremental},
+			{ID: "url_pattern_cache_evictions", Name: "evictions", Algo: module.Incremental},
+		},
+	}
 	reqByCustomFieldPattern = Chart{
 		ID:       "custom_field_%s_requests_by_pattern",
 		Title:    "Custom Field %s Requests By Pattern",
@@ -481,6 +514,22 @@ func newUpsRespTimeHistChart(histogram []float64) (*Chart, error) {
 	return chart, nil
 }
 
+func newQuantilesChart(tmpl Chart, prefix string, quantiles []float64) (*Chart, error) {
+	chart := tmpl.Copy()
+	for _, q := range quantiles {
+		name := metrics.QuantileName(q)
+		dim := &Dim{
+			ID:   prefix + "_" + name,
+			Name: name,
+			Div:  1000,
+		}
+		if err := chart.AddDim(dim); err != nil {
+			return nil, err
+		}
+	}
+	return chart, nil
+}
+
 func newURLPatternChart(patterns []userPattern) (*Chart, error) {
 	chart := reqByURLPattern.Copy()
 	for _, p := range patterns {
@@ -685,12 +734,12 @@ func (w *WebLog) createCharts(line *logLine) error {
 		}
 	}
 	if line.hasReqProcTime() {
-		if err := addReqProcTimeCharts(charts, w.Histogram, w.URLPatterns); err != nil {
+		if err := addReqProcTimeCharts(charts, w.Histogram, w.Quantiles, w.URLPatterns); err != nil {
 			return err
 		}
 	}
 	if line.hasUpsRespTime() {
-		if err := addUpstreamRespTimeCharts(charts, w.Histogram); err != nil {
+		if err := addUpstreamRespTimeCharts(charts, w.Histogram, w.Quantiles); err != nil {
 			return err
 		}
 	}
@@ -757,6 +806,9 @@ func addURLCharts(charts *Charts, patterns []userPattern) error {
 	if err := charts.Add(chart); err != nil {
 		return err
 	}
+	if err := charts.Add(urlPatternMatcherCache.Copy()); err != nil {
+		return err
+	}
 
 	for _, p := range patterns {
 		chart := newURLPatternRespCodesChart(p.Name)
@@ -803,7 +855,7 @@ func addBandwidthCharts(charts *Charts, patterns []userPattern) error {
 	return nil
 }
 
-func addReqProcTimeCharts(charts *Charts, histogram []float64, patterns []userPattern) error {
+func addReqProcTimeCharts(charts *Charts, histogram, quantiles []float64, patterns []userPattern) error {
 	if err := charts.Add(reqProcTime.Copy()); err != nil {
 		return err
 	}
@@ -813,24 +865,42 @@ func addReqProcTimeCharts(charts *Charts, histogram []float64, patterns []userPa
 			return err
 		}
 	}
-	if len(histogram) == 0 {
+	if len(histogram) > 0 {
+		chart, err := newReqProcTimeHistChart(histogram)
+		if err != nil {
+			return err
+		}
+		if err := charts.Add(chart); err != nil {
+			return err
+		}
+	}
+	if len(quantiles) == 0 {
 		return nil
 	}
-	chart, err := newReqProcTimeHistChart(histogram)
+	chart, err := newQuantilesChart(reqProcTimeQuantiles, "req_proc_time_quantile", quantiles)
 	if err != nil {
 		return err
 	}
 	return charts.Add(chart)
 }
 
-func addUpstreamRespTimeCharts(charts *Charts, histogram []float64) error {
+func addUpstreamRespTimeCharts(charts *Charts, histogram, quantiles []float64) error {
 	if err := charts.Add(upsRespTime.Copy()); err != nil {
 		return err
 	}
-	if len(histogram) == 0 {
+	if len(histogram) > 0 {
+		chart, err := newUpsRespTimeHistChart(histogram)
+		if err != nil {
+			return err
+		}
+		if err := charts.Add(chart); err != nil {
+			return err
+		}
+	}
+	if len(quantiles) == 0 {
 		return nil
 	}
-	chart, err := newUpsRespTimeHistChart(histogram)
+	chart, err := newQuantilesChart(upsRespTimeQuantiles, "upstream_resp_time_quantile", quantiles)
 	if err != nil {
 		return err
 	}
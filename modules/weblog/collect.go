@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/stm"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -41,10 +42,35 @@ func (w *WebLog) collect() (map[string]int64, error) {
 
 	if n > 0 || err == nil {
 		mx = stm.ToMap(w.mx)
+		w.collectURLPatternsCache(mx)
 	}
 	return mx, err
 }
 
+// collectURLPatternsCache writes the URL patterns matcher cache statistics,
+// a cache shared with other jobs counts their matches too.
+func (w *WebLog) collectURLPatternsCache(mx map[string]int64) {
+	if len(w.urlPatterns) == 0 {
+		return
+	}
+	var stats matcher.CacheStats
+	seen := make(map[matcher.Matcher]bool)
+	for _, p := range w.urlPatterns {
+		m, ok := p.Matcher.(matcher.CachedMatcher)
+		if !ok || seen[m] {
+			continue
+		}
+		seen[m] = true
+		s := m.Stats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Evictions += s.Evictions
+		stats.Expirations += s.Expirations
+		stats.Size += s.Size
+	}
+	stm.WriteValue(mx, stats, "url_pattern_cache", 1, 1)
+}
+
 func (w *WebLog) collectLogLines() (int, error) {
 	logOnce := true
 	var n int
@@ -241,6 +267,7 @@ func (w *WebLog) collectReqProcTime() {
 		return
 	}
 	w.mx.ReqProcTime.Observe(w.line.reqProcTime)
+	w.mx.ReqProcTimeQuant.Observe(w.line.reqProcTime)
 	if w.mx.ReqProcTimeHist == nil {
 		return
 	}
@@ -252,6 +279,7 @@ func (w *WebLog) collectUpsRespTime() {
 		return
 	}
 	w.mx.UpsRespTime.Observe(w.line.upsRespTime)
+	w.mx.UpsRespTimeQuant.Observe(w.line.upsRespTime)
 	if w.mx.UpsRespTimeHist == nil {
 		return
 	}
@@ -28,15 +28,21 @@ func newPattern(up userPattern) (*pattern, error) {
 	return &pattern{name: up.Name, Matcher: m}, nil
 }
 
+// createURLPatterns creates the URL patterns unless they are already created. The URL field has high cardinality,
+// so the patterns are cached, the caches are shared with other jobs that use the same patterns.
 func (w *WebLog) createURLPatterns() error {
 	if len(w.URLPatterns) == 0 {
 		w.Debug("skipping URL patterns creating, no patterns provided")
 		return nil
 	}
+	if len(w.urlPatterns) > 0 {
+		return nil
+	}
 	w.Debug("starting URL patterns creating")
 	for _, up := range w.URLPatterns {
-		p, err := newPattern(up)
+		p, err := newCachedPattern(up)
 		if err != nil {
+			w.releaseURLPatterns()
 			return fmt.Errorf("create pattern %+v: %v", up, err)
 		}
 		w.Debugf("created pattern '%s', type '%T', match '%s'", p.name, p.Matcher, up.Match)
@@ -46,6 +52,25 @@ func (w *WebLog) createURLPatterns() error {
 	return nil
 }
 
+func newCachedPattern(up userPattern) (*pattern, error) {
+	if up.Name == "" || up.Match == "" {
+		return nil, errors.New("empty 'name' or 'match'")
+	}
+
+	m, err := matcher.ParseCached(up.Match, matcher.CacheConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return &pattern{name: up.Name, Matcher: m}, nil
+}
+
+func (w *WebLog) releaseURLPatterns() {
+	for _, p := range w.urlPatterns {
+		matcher.ReleaseCached(p.Matcher)
+	}
+	w.urlPatterns = nil
+}
+
 func (w *WebLog) createCustomFields() error {
 	if len(w.CustomFields) == 0 {
 		w.Debug("skipping custom fields creating, no custom fields provided")
@@ -90,6 +115,15 @@ func (w *WebLog) createCustomTimeFields() error {
 	return nil
 }
 
+func (w *WebLog) validateQuantiles() error {
+	for _, q := range w.Quantiles {
+		if q <= 0 || q >= 1 {
+			return fmt.Errorf("invalid quantile %v, must be in the range (0, 1)", q)
+		}
+	}
+	return nil
+}
+
 func (w *WebLog) createLogLine() {
 	w.line = newEmptyLogLine()
 	for v := range w.customFields {
@@ -100,15 +134,57 @@ func (w *WebLog) createLogLine() {
 	}
 }
 
+// validateConfig rejects backfill together with persisted offsets, the rotated files would be counted again
+// after every restart and the current file lines after the persisted offset twice.
+func (w *WebLog) validateConfig() error {
+	if w.Backfill && w.PersistOffsets {
+		return errors.New("'backfill' and 'persist_offsets' can't be used together")
+	}
+	return nil
+}
+
 func (w *WebLog) createLogReader() error {
-	w.Cleanup()
+	w.closeLogReader()
 	w.Debug("starting log reader creating")
-	reader, err := logs.Open(w.Path, w.ExcludePath, w.Logger)
+	cfg := logs.ReaderConfig{
+		Path:        w.Path,
+		ExcludePath: w.ExcludePath,
+		FollowAll:   w.FollowAllFiles,
+	}
+	if w.PersistOffsets {
+		if cfg.StateDir = logs.DefaultStateDir(); cfg.StateDir == "" {
+			w.Warning("can't persist offsets: netdata lib directory is not set")
+		}
+	}
+	// the backfill reader is opened first, it reads the current file up to its size at the open time
+	// and the log reader starts at the end of the file, so no line is read twice
+	var backfill *logs.BackfillReader
+	if w.Backfill {
+		var err error
+		backfill, err = logs.OpenBackfill(logs.BackfillConfig{
+			Path:        w.Path,
+			ExcludePath: w.ExcludePath,
+			MaxAge:      w.BackfillMaxAge.Duration,
+			MaxSize:     w.BackfillMaxSize,
+		}, w.Logger)
+		if err != nil {
+			return fmt.Errorf("creating backfill reader: %v", err)
+		}
+		w.Debugf("created backfill reader, files %v", backfill.Filenames())
+	}
+
+	reader, err := logs.OpenWithConfig(cfg, w.Logger)
 	if err != nil {
+		_ = backfill.Close()
 		return fmt.Errorf("creating log reader: %v", err)
 	}
 	w.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
 	w.file = reader
+	w.backfill = backfill
+	w.input = reader
+	if backfill != nil {
+		w.input = logs.FollowAfterBackfill(backfill, reader)
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@ package weblog
 
 import (
 	"github.com/netdata/go.d.plugin/pkg/metrics"
+	"github.com/netdata/go.d.plugin/pkg/stm"
 )
 
 func newWebLogSummary() metrics.Summary {
@@ -25,6 +26,36 @@ func (s weblogSummary) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+func newWebLogQuantiles(qs []float64) weblogQuantiles {
+	if len(qs) == 0 {
+		return weblogQuantiles{}
+	}
+	return weblogQuantiles{metrics.NewQuantiles(qs)}
+}
+
+// weblogQuantiles is a no-op if quantiles are not configured.
+type weblogQuantiles struct {
+	metrics.Quantiles
+}
+
+func (q weblogQuantiles) Observe(v float64) {
+	if q.Quantiles != nil {
+		q.Quantiles.Observe(v)
+	}
+}
+
+func (q weblogQuantiles) Reset() {
+	if q.Quantiles != nil {
+		q.Quantiles.Reset()
+	}
+}
+
+func (q weblogQuantiles) WriteTo(rv map[string]int64, key string, mul, div int) {
+	if q.Quantiles != nil {
+		q.Quantiles.(stm.Value).WriteTo(rv, key, mul, div)
+	}
+}
+
 type (
 	metricsData struct {
 		Requests     metrics.Counter `stm:"requests"`
@@ -42,14 +73,16 @@ type (
 		ReqBad      metrics.Counter `stm:"req_type_bad"`
 		ReqError    metrics.Counter `stm:"req_type_error"`
 
-		UniqueIPv4      metrics.UniqueCounter `stm:"uniq_ipv4"`
-		UniqueIPv6      metrics.UniqueCounter `stm:"uniq_ipv6"`
-		BytesSent       metrics.Counter       `stm:"bytes_sent"`
-		BytesReceived   metrics.Counter       `stm:"bytes_received"`
-		ReqProcTime     metrics.Summary       `stm:"req_proc_time"`
-		ReqProcTimeHist metrics.Histogram     `stm:"req_proc_time_hist"`
-		UpsRespTime     metrics.Summary       `stm:"upstream_resp_time"`
-		UpsRespTimeHist metrics.Histogram     `stm:"upstream_resp_time_hist"`
+		UniqueIPv4       metrics.UniqueCounter `stm:"uniq_ipv4"`
+		UniqueIPv6       metrics.UniqueCounter `stm:"uniq_ipv6"`
+		BytesSent        metrics.Counter       `stm:"bytes_sent"`
+		BytesReceived    metrics.Counter       `stm:"bytes_received"`
+		ReqProcTime      metrics.Summary       `stm:"req_proc_time"`
+		ReqProcTimeHist  metrics.Histogram     `stm:"req_proc_time_hist"`
+		ReqProcTimeQuant weblogQuantiles       `stm:"req_proc_time_quantile"`
+		UpsRespTime      metrics.Summary       `stm:"upstream_resp_time"`
+		UpsRespTimeHist  metrics.Histogram     `stm:"upstream_resp_time_hist"`
+		UpsRespTimeQuant weblogQuantiles       `stm:"upstream_resp_time_quantile"`
 
 		ReqVhost          metrics.CounterVec `stm:"req_vhost"`
 		ReqPort           metrics.CounterVec `stm:"req_port"`
@@ -94,6 +127,8 @@ func newMetricsData(config Config) *metricsData {
 		ReqProcTimeHist:    metrics.NewHistogram(convHistOptionsToMicroseconds(config.Histogram)),
 		UpsRespTime:        newWebLogSummary(),
 		UpsRespTimeHist:    metrics.NewHistogram(convHistOptionsToMicroseconds(config.Histogram)),
+		ReqProcTimeQuant:   newWebLogQuantiles(config.Quantiles),
+		UpsRespTimeQuant:   newWebLogQuantiles(config.Quantiles),
 		UniqueIPv4:         metrics.NewUniqueCounter(true),
 		UniqueIPv6:         metrics.NewUniqueCounter(true),
 		ReqURLPattern:      newCounterVecFromPatterns(config.URLPatterns),
@@ -108,6 +143,8 @@ func (m *metricsData) reset() {
 	m.UniqueIPv6.Reset()
 	m.ReqProcTime.Reset()
 	m.UpsRespTime.Reset()
+	m.ReqProcTimeQuant.Reset()
+	m.UpsRespTimeQuant.Reset()
 	for _, v := range m.URLPatternStats {
 		v.ReqProcTime.Reset()
 	}
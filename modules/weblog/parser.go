@@ -5,7 +5,6 @@ package weblog
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
@@ -63,18 +62,9 @@ var (
 func cleanCSVFormat(format string) string       { return strings.Join(strings.Fields(format), " ") }
 func cleanApacheLogFormat(format string) string { return strings.ReplaceAll(format, `\`, "") }
 
-const (
-	typeAuto = "auto"
-)
-
-var (
-	reLTSV = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
-	reJSON = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
-)
-
 func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
-	if w.Parser.LogType == typeAuto {
-		w.Debugf("log_type is %s, will try format auto-detection", typeAuto)
+	if w.Parser.LogType == logs.TypeAuto {
+		w.Debugf("log_type is %s, will try format auto-detection", logs.TypeAuto)
 		if len(record) == 0 {
 			return nil, fmt.Errorf("empty line, can't auto-detect format (%s)", w.file.CurrentFilename())
 		}
@@ -92,22 +82,28 @@ func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
 		w.Debugf("config: %+v", w.Parser.RegExp)
 	case logs.TypeJSON:
 		w.Debugf("config: %+v", w.Parser.JSON)
+	case logs.TypeLogfmt:
+		w.Debugf("config: %+v", w.Parser.Logfmt)
 	}
-	return logs.NewParser(w.Parser, w.file)
+	return logs.NewParser(w.Parser, w.input)
 }
 
 func (w *WebLog) guessParser(record []byte) (logs.Parser, error) {
 	w.Debug("starting log type auto-detection")
-	if reLTSV.Match(record) {
+	switch typ := logs.DetectLogType(record); typ {
+	case logs.TypeLTSV:
 		w.Debug("log type is LTSV")
-		return logs.NewLTSVParser(w.Parser.LTSV, w.file)
-	}
-	if reJSON.Match(record) {
+		return logs.NewLTSVParser(w.Parser.LTSV, w.input)
+	case logs.TypeJSON:
 		w.Debug("log type is JSON")
-		return logs.NewJSONParser(w.Parser.JSON, w.file)
+		return logs.NewJSONParser(w.Parser.JSON, w.input)
+	case logs.TypeLogfmt:
+		w.Debug("log type is logfmt")
+		return logs.NewLogfmtParser(w.Parser.Logfmt, w.input)
+	default:
+		w.Debug("log type is CSV")
+		return w.guessCSVParser(record)
 	}
-	w.Debug("log type is CSV")
-	return w.guessCSVParser(record)
 }
 
 func (w *WebLog) guessCSVParser(record []byte) (logs.Parser, error) {
@@ -119,7 +115,7 @@ func (w *WebLog) guessCSVParser(record []byte) (logs.Parser, error) {
 		cfg.Format = format
 
 		w.Debugf("trying format: '%s'", format)
-		parser, err := logs.NewCSVParser(cfg, w.file)
+		parser, err := logs.NewCSVParser(cfg, w.input)
 		if err != nil {
 			return nil, err
 		}
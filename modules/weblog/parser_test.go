@@ -55,6 +55,14 @@ func TestWebLog_guessParser(t *testing.T) {
 				` {"host": "example.com","time": "2020-08-04T20:23:27+03:00", "upstream_response_time": "0.776", "remote_addr": "1.2.3.4"}	`,
 			},
 		},
+		{
+			name:           "guessed logfmt",
+			wantParserType: logs.TypeLogfmt,
+			inputs: []string{
+				`remote_addr=1.2.3.4 request="GET / HTTP/1.0" status=200`,
+				` host=example.com request_time=0.776 `,
+			},
+		},
 		{
 			name:    "unknown",
 			wantErr: true,
@@ -87,6 +95,8 @@ func TestWebLog_guessParser(t *testing.T) {
 						require.IsType(t, (*logs.CSVParser)(nil), p)
 					case logs.TypeJSON:
 						require.IsType(t, (*logs.JSONParser)(nil), p)
+					case logs.TypeLogfmt:
+						require.IsType(t, (*logs.LogfmtParser)(nil), p)
 					}
 				}
 			})
@@ -204,7 +214,7 @@ func TestWebLog_guessCSVParser(t *testing.T) {
 
 func prepareWebLog() *WebLog {
 	cfg := logs.ParserConfig{
-		LogType: typeAuto,
+		LogType: logs.TypeAuto,
 		CSV: logs.CSVConfig{
 			Delimiter:  " ",
 			CheckField: checkCSVFormatField,
@@ -3,7 +3,10 @@
 package weblog
 
 import (
+	"io"
+
 	"github.com/netdata/go.d.plugin/pkg/logs"
+	webpkg "github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -18,7 +21,7 @@ func init() {
 
 func New() *WebLog {
 	cfg := logs.ParserConfig{
-		LogType: typeAuto,
+		LogType: logs.TypeAuto,
 		CSV: logs.CSVConfig{
 			FieldsPerRecord:  -1,
 			Delimiter:        " ",
@@ -31,6 +34,7 @@ func New() *WebLog {
 		},
 		RegExp: logs.RegExpConfig{},
 		JSON:   logs.JSONConfig{},
+		Logfmt: logs.LogfmtConfig{},
 	}
 	return &WebLog{
 		Config: Config{
@@ -59,10 +63,16 @@ type (
 		Parser           logs.ParserConfig `yaml:",inline"`
 		Path             string            `yaml:"path"`
 		ExcludePath      string            `yaml:"exclude_path"`
+		FollowAllFiles   bool              `yaml:"follow_all_files"`
+		PersistOffsets   bool              `yaml:"persist_offsets"`
+		Backfill         bool              `yaml:"backfill"`
+		BackfillMaxAge   webpkg.Duration   `yaml:"backfill_max_age"`
+		BackfillMaxSize  int64             `yaml:"backfill_max_size"`
 		URLPatterns      []userPattern     `yaml:"url_patterns"`
 		CustomFields     []customField     `yaml:"custom_fields"`
 		CustomTimeFields []customTimeField `yaml:"custom_time_fields"`
 		Histogram        []float64         `yaml:"histogram"`
+		Quantiles        []float64         `yaml:"quantiles"`
 		GroupRespCodes   bool              `yaml:"group_response_codes"`
 	}
 
@@ -71,6 +81,8 @@ type (
 		Config `yaml:",inline"`
 
 		file             *logs.Reader
+		backfill         *logs.BackfillReader
+		input            io.Reader
 		parser           logs.Parser
 		line             *logLine
 		urlPatterns      []*pattern
@@ -83,6 +95,11 @@ type (
 )
 
 func (w *WebLog) Init() bool {
+	if err := w.validateConfig(); err != nil {
+		w.Error("init failed: ", err)
+		return false
+	}
+
 	if err := w.createURLPatterns(); err != nil {
 		w.Error("init failed: ", err)
 		return false
@@ -98,6 +115,11 @@ func (w *WebLog) Init() bool {
 		return false
 	}
 
+	if err := w.validateQuantiles(); err != nil {
+		w.Error("init failed: ", err)
+		return false
+	}
+
 	w.createLogLine()
 	w.mx = newMetricsData(w.Config)
 	return true
@@ -105,6 +127,11 @@ func (w *WebLog) Init() bool {
 
 func (w *WebLog) Check() bool {
 	// Note: these inits are here to make auto-detection retry working
+	if err := w.createURLPatterns(); err != nil {
+		w.Warning("check failed: ", err)
+		return false
+	}
+
 	if err := w.createLogReader(); err != nil {
 		w.Warning("check failed: ", err)
 		return false
@@ -139,7 +166,15 @@ func (w *WebLog) Collect() map[string]int64 {
 }
 
 func (w *WebLog) Cleanup() {
+	w.closeLogReader()
+	w.releaseURLPatterns()
+}
+
+func (w *WebLog) closeLogReader() {
 	if w.file != nil {
 		_ = w.file.Close()
 	}
+	if w.backfill != nil {
+		_ = w.backfill.Close()
+	}
 }
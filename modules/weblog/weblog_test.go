@@ -4,14 +4,17 @@ package weblog
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/metrics"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -57,6 +60,13 @@ func TestWebLog_Init_ErrorOnCreatingCustomFields(t *testing.T) {
 	assert.False(t, weblog.Init())
 }
 
+func TestWebLog_Init_ErrorOnInvalidQuantiles(t *testing.T) {
+	weblog := New()
+	weblog.Quantiles = []float64{0.5, 1.5}
+
+	assert.False(t, weblog.Init())
+}
+
 func TestWebLog_Check(t *testing.T) {
 	weblog := New()
 	defer weblog.Cleanup()
@@ -95,6 +105,54 @@ func TestWebLog_Check_ErrorOnCreatingParserEmptyLine(t *testing.T) {
 	assert.False(t, weblog.Check())
 }
 
+func TestWebLog_Init_ErrorOnBackfillWithPersistOffsets(t *testing.T) {
+	weblog := New()
+	weblog.Backfill = true
+	weblog.PersistOffsets = true
+
+	assert.False(t, weblog.Init())
+}
+
+func TestWebLog_Collect_Backfill(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	data, err := os.ReadFile("testdata/common.log")
+	require.NoError(t, err)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(data)
+	require.NoError(t, zw.Close())
+	// the rotated file is read even though the default 'exclude_path' excludes '*.gz'
+	require.NoError(t, os.WriteFile(path+".1.gz", gz.Bytes(), 0644))
+
+	weblog := New()
+	defer weblog.Cleanup()
+	weblog.Path = path
+	weblog.Backfill = true
+	require.True(t, weblog.Init())
+	require.True(t, weblog.Check())
+
+	mx := weblog.Collect()
+
+	assert.Equal(t, int64(500*2), mx["requests"])
+	assert.Equal(t, int64(44*2), mx["req_unmatched"])
+
+	// new lines are followed after the backfill
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.Write(data)
+	_ = f.Close()
+	require.NoError(t, err)
+
+	mx = weblog.Collect()
+
+	assert.Equal(t, int64(500*3), mx["requests"])
+}
+
 func TestWebLog_Charts(t *testing.T) {
 	weblog := New()
 	defer weblog.Cleanup()
@@ -109,8 +167,28 @@ func TestWebLog_Cleanup(t *testing.T) {
 	New().Cleanup()
 }
 
+func TestWebLog_Cleanup_ReleasesURLPatterns(t *testing.T) {
+	weblog := New()
+	weblog.Path = "testdata/common.log"
+	weblog.URLPatterns = []userPattern{{Name: "com", Match: "~ com$"}}
+	require.True(t, weblog.Init())
+	require.Len(t, weblog.urlPatterns, 1)
+	m := weblog.urlPatterns[0].Matcher
+	require.Implements(t, (*matcher.CachedMatcher)(nil), m)
+
+	weblog.Cleanup()
+	assert.Nil(t, weblog.urlPatterns)
+
+	// the released cache is not shared anymore, a new one is created
+	require.True(t, weblog.Check())
+	defer weblog.Cleanup()
+	require.Len(t, weblog.urlPatterns, 1)
+	assert.False(t, m == weblog.urlPatterns[0].Matcher)
+}
+
 func TestWebLog_Collect(t *testing.T) {
 	weblog := prepareWebLogCollectFull(t)
+	defer weblog.Cleanup()
 
 	//m := weblog.Collect()
 	//l := make([]string, 0)
@@ -220,6 +298,11 @@ func TestWebLog_Collect(t *testing.T) {
 		"upstream_resp_time_max":                                  497,
 		"upstream_resp_time_min":                                  7,
 		"upstream_resp_time_sum":                                  115615,
+		"url_pattern_cache_evictions":                             0,
+		"url_pattern_cache_expirations":                           0,
+		"url_pattern_cache_hits":                                  1096,
+		"url_pattern_cache_misses":                                10,
+		"url_pattern_cache_size":                                  10,
 		"url_ptn_com_bytes_received":                              379864,
 		"url_ptn_com_bytes_sent":                                  372669,
 		"url_ptn_com_req_method_GET":                              38,
@@ -306,6 +389,35 @@ func TestWebLog_Collect(t *testing.T) {
 	testCharts(t, weblog, mx)
 }
 
+func TestWebLog_Collect_Quantiles(t *testing.T) {
+	full := prepareWebLogCollectFull(t)
+	full.Cleanup()
+
+	weblog := New()
+	weblog.Config = full.Config
+	weblog.Quantiles = []float64{0.5, 0.99}
+	require.True(t, weblog.Init())
+	require.True(t, weblog.Check())
+	defer weblog.Cleanup()
+
+	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testFullLog))
+	require.NoError(t, err)
+	weblog.parser = p
+
+	mx := weblog.Collect()
+
+	for _, prefix := range []string{"req_proc_time_quantile", "upstream_resp_time_quantile"} {
+		p50, ok := mx[prefix+"_p50"]
+		require.Truef(t, ok, "no '%s_p50' metric", prefix)
+		p99, ok := mx[prefix+"_p99"]
+		require.Truef(t, ok, "no '%s_p99' metric", prefix)
+		assert.LessOrEqual(t, p50, p99)
+	}
+	assert.True(t, weblog.Charts().Has(reqProcTimeQuantiles.ID))
+	assert.True(t, weblog.Charts().Has(upsRespTimeQuantiles.ID))
+	testChartsDimIDs(t, weblog, mx)
+}
+
 func TestWebLog_Collect_CommonLogFormat(t *testing.T) {
 	weblog := prepareWebLogCollectCommon(t)
 
@@ -777,6 +889,10 @@ func testReqProcTimeCharts(t *testing.T, w *WebLog) {
 	} else {
 		assert.Truef(t, w.Charts().Has(reqProcTimeHist.ID), "chart '%s' is not created", reqProcTimeHist.ID)
 	}
+
+	if len(w.Quantiles) == 0 {
+		assert.Falsef(t, w.Charts().Has(reqProcTimeQuantiles.ID), "chart '%s' is created", reqProcTimeQuantiles.ID)
+	}
 }
 
 func testUpsRespTimeCharts(t *testing.T, w *WebLog) {
@@ -1018,7 +1134,7 @@ func prepareWebLogCollectFull(t *testing.T) *WebLog {
 	weblog.Config = cfg
 	require.True(t, weblog.Init())
 	require.True(t, weblog.Check())
-	defer weblog.Cleanup()
+	defer weblog.closeLogReader()
 
 	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testFullLog))
 	require.NoError(t, err)
@@ -1061,7 +1177,7 @@ func prepareWebLogCollectCommon(t *testing.T) *WebLog {
 	weblog.Config = cfg
 	require.True(t, weblog.Init())
 	require.True(t, weblog.Check())
-	defer weblog.Cleanup()
+	defer weblog.closeLogReader()
 
 	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCommonLog))
 	require.NoError(t, err)
@@ -1113,7 +1229,7 @@ func prepareWebLogCollectCustom(t *testing.T) *WebLog {
 	weblog.Config = cfg
 	require.True(t, weblog.Init())
 	require.True(t, weblog.Check())
-	defer weblog.Cleanup()
+	defer weblog.closeLogReader()
 
 	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCustomLog))
 	require.NoError(t, err)
@@ -1159,7 +1275,7 @@ func prepareWebLogCollectCustomTimeFields(t *testing.T) *WebLog {
 	weblog.Config = cfg
 	require.True(t, weblog.Init())
 	require.True(t, weblog.Check())
-	defer weblog.Cleanup()
+	defer weblog.closeLogReader()
 
 	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCustomTimeFieldLog))
 	require.NoError(t, err)
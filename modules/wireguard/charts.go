@@ -2,12 +2,7 @@
 
 package wireguard
 
-import (
-	"fmt"
-	"strings"
-
-	"github.com/netdata/go.d.plugin/agent/module"
-)
+import "github.com/netdata/go.d.plugin/agent/module"
 
 const (
 	prioDeviceNetworkIO = module.Priority + iota
@@ -23,7 +18,7 @@ var (
 	}
 
 	deviceNetworkIOChartTmpl = module.Chart{
-		ID:       "device_%s_network_io",
+		ID:       "device_{{.device}}_network_io",
 		Title:    "Device traffic",
 		Units:    "B/s",
 		Fam:      "device traffic",
@@ -31,19 +26,19 @@ var (
 		Type:     module.Area,
 		Priority: prioDeviceNetworkIO,
 		Dims: module.Dims{
-			{ID: "device_%s_receive", Name: "receive", Algo: module.Incremental},
-			{ID: "device_%s_transmit", Name: "transmit", Algo: module.Incremental, Mul: -1},
+			{ID: "device_{{.device}}_receive", Name: "receive", Algo: module.Incremental},
+			{ID: "device_{{.device}}_transmit", Name: "transmit", Algo: module.Incremental, Mul: -1},
 		},
 	}
 	devicePeersChartTmpl = module.Chart{
-		ID:       "device_%s_peers",
+		ID:       "device_{{.device}}_peers",
 		Title:    "Device peers",
 		Units:    "peers",
 		Fam:      "device peers",
 		Ctx:      "wireguard.device_peers",
 		Priority: prioDevicePeers,
 		Dims: module.Dims{
-			{ID: "device_%s_peers", Name: "peers"},
+			{ID: "device_{{.device}}_peers", Name: "peers"},
 		},
 	}
 )
@@ -55,7 +50,7 @@ var (
 	}
 
 	peerNetworkIOChartTmpl = module.Chart{
-		ID:       "peer_%s_network_io",
+		ID:       "peer_{{.device}}_{{.public_key}}_network_io",
 		Title:    "Peer traffic",
 		Units:    "B/s",
 		Fam:      "peer traffic",
@@ -63,90 +58,33 @@ var (
 		Type:     module.Area,
 		Priority: prioPeerNetworkIO,
 		Dims: module.Dims{
-			{ID: "peer_%s_receive", Name: "receive", Algo: module.Incremental},
-			{ID: "peer_%s_transmit", Name: "transmit", Algo: module.Incremental, Mul: -1},
+			{ID: "peer_{{.device}}_{{.public_key}}_receive", Name: "receive", Algo: module.Incremental},
+			{ID: "peer_{{.device}}_{{.public_key}}_transmit", Name: "transmit", Algo: module.Incremental, Mul: -1},
 		},
 	}
 	peerLatestHandShakeChartTmpl = module.Chart{
-		ID:       "peer_%s_latest_handshake_ago",
+		ID:       "peer_{{.device}}_{{.public_key}}_latest_handshake_ago",
 		Title:    "Peer time elapsed sine the latest handshake",
 		Units:    "seconds",
 		Fam:      "peer latest handshake",
 		Ctx:      "wireguard.peer_latest_handshake_ago",
 		Priority: prioPeerLatestHandShake,
 		Dims: module.Dims{
-			{ID: "peer_%s_latest_handshake_ago", Name: "time"},
+			{ID: "peer_{{.device}}_{{.public_key}}_latest_handshake_ago", Name: "time"},
 		},
 	}
 )
 
-func newDeviceCharts(device string) *module.Charts {
-	charts := deviceChartsTmpl.Copy()
-
-	for _, c := range *charts {
-		c.ID = fmt.Sprintf(c.ID, device)
-		c.Labels = []module.Label{
-			{Key: "device", Value: device},
-		}
-		for _, d := range c.Dims {
-			d.ID = fmt.Sprintf(d.ID, device)
-		}
-	}
-
-	return charts
-}
-
 func (w *WireGuard) addNewDeviceCharts(device string) {
-	charts := newDeviceCharts(device)
-
-	if err := w.Charts().Add(*charts...); err != nil {
+	labels := map[string]string{"device": device}
+	if err := w.deviceCharts.Add(w.Charts(), device, labels); err != nil {
 		w.Warning(err)
 	}
 }
 
-func (w *WireGuard) removeDeviceCharts(device string) {
-	prefix := fmt.Sprintf("device_%s", device)
-
-	for _, c := range *w.Charts() {
-		if strings.HasPrefix(c.ID, prefix) {
-			c.MarkRemove()
-			c.MarkNotCreated()
-		}
-	}
-}
-
-func newPeerCharts(id, device, pubKey string) *module.Charts {
-	charts := peerChartsTmpl.Copy()
-
-	for _, c := range *charts {
-		c.ID = fmt.Sprintf(c.ID, id)
-		c.Labels = []module.Label{
-			{Key: "device", Value: device},
-			{Key: "public_key", Value: pubKey},
-		}
-		for _, d := range c.Dims {
-			d.ID = fmt.Sprintf(d.ID, id)
-		}
-	}
-
-	return charts
-}
-
 func (w *WireGuard) addNewPeerCharts(id, device, pubKey string) {
-	charts := newPeerCharts(id, device, pubKey)
-
-	if err := w.Charts().Add(*charts...); err != nil {
+	labels := map[string]string{"device": device, "public_key": pubKey}
+	if err := w.peerCharts.Add(w.Charts(), id, labels); err != nil {
 		w.Warning(err)
 	}
 }
-
-func (w *WireGuard) removePeerCharts(id string) {
-	prefix := fmt.Sprintf("peer_%s", id)
-
-	for _, c := range *w.Charts() {
-		if strings.HasPrefix(c.ID, prefix) {
-			c.MarkRemove()
-			c.MarkNotCreated()
-		}
-	}
-}
@@ -48,8 +48,7 @@ func (w *WireGuard) collect() (map[string]int64, error) {
 
 func (w *WireGuard) collectDevicesPeers(mx map[string]int64, devices []*wgtypes.Device, now time.Time) {
 	for _, d := range devices {
-		if !w.devices[d.Name] {
-			w.devices[d.Name] = true
+		if !w.deviceCharts.Has(d.Name) {
 			w.addNewDeviceCharts(d.Name)
 		}
 
@@ -64,8 +63,7 @@ func (w *WireGuard) collectDevicesPeers(mx map[string]int64, devices []*wgtypes.
 			pubKey := p.PublicKey.String()
 			id := peerID(d.Name, pubKey)
 
-			if !w.peers[id] {
-				w.peers[id] = true
+			if !w.peerCharts.Has(id) {
 				w.addNewPeerCharts(id, d.Name, pubKey)
 			}
 
@@ -86,16 +84,14 @@ func (w *WireGuard) cleanupDevicesPeers(devices []*wgtypes.Device) {
 			seenPeers[peerID(d.Name, p.PublicKey.String())] = true
 		}
 	}
-	for d := range w.devices {
+	for _, d := range w.deviceCharts.Keys() {
 		if !seenDevices[d] {
-			delete(w.devices, d)
-			w.removeDeviceCharts(d)
+			w.deviceCharts.Remove(d)
 		}
 	}
-	for p := range w.peers {
+	for _, p := range w.peerCharts.Keys() {
 		if !seenPeers[p] {
-			delete(w.peers, p)
-			w.removePeerCharts(p)
+			w.peerCharts.Remove(p)
 		}
 	}
 }
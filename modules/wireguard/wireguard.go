@@ -21,8 +21,8 @@ func New() *WireGuard {
 	return &WireGuard{
 		newWGClient:  func() (wgClient, error) { return wgctrl.New() },
 		charts:       &module.Charts{},
-		devices:      make(map[string]bool),
-		peers:        make(map[string]bool),
+		deviceCharts: module.MustNewChartTemplate(deviceChartsTmpl),
+		peerCharts:   module.MustNewChartTemplate(peerChartsTmpl),
 		cleanupEvery: time.Minute,
 	}
 }
@@ -39,8 +39,8 @@ type (
 		cleanupLastTime time.Time
 		cleanupEvery    time.Duration
 
-		devices map[string]bool
-		peers   map[string]bool
+		deviceCharts *module.ChartTemplate
+		peerCharts   *module.ChartTemplate
 	}
 	wgClient interface {
 		Devices() ([]*wgtypes.Device, error)
@@ -11,49 +11,116 @@ type (
 	Opts   = module.Opts
 )
 
-var charts = Charts{
-	{
-		ID:    "time_until_expiration",
-		Title: "Time Until Certificate Expiration",
-		Units: "seconds",
-		Fam:   "expiration time",
-		Ctx:   "x509check.time_until_expiration",
-		Opts:  Opts{StoreFirst: true},
-		Dims: Dims{
-			{ID: "expiry"},
-		},
-		Vars: Vars{
-			{ID: "days_until_expiration_warning"},
-			{ID: "days_until_expiration_critical"},
-		},
+var timeUntilExpirationChart = module.Chart{
+	ID:    "time_until_expiration",
+	Title: "Time Until Certificate Expiration",
+	Units: "seconds",
+	Fam:   "expiration time",
+	Ctx:   "x509check.time_until_expiration",
+	Opts:  Opts{StoreFirst: true},
+	Dims: Dims{
+		{ID: "expiry"},
+	},
+	Vars: Vars{
+		{ID: "days_until_expiration_warning"},
+		{ID: "days_until_expiration_critical"},
 	},
 }
 
-var withRevocationCharts = Charts{
-	{
-		ID:    "time_until_expiration",
-		Title: "Time Until Certificate Expiration",
-		Units: "seconds",
-		Fam:   "expiration time",
-		Ctx:   "x509check.time_until_expiration",
-		Opts:  Opts{StoreFirst: true},
-		Dims: Dims{
-			{ID: "expiry"},
-		},
-		Vars: Vars{
-			{ID: "days_until_expiration_warning"},
-			{ID: "days_until_expiration_critical"},
-		},
+var revocationStatusChart = module.Chart{
+	ID:    "revocation_status",
+	Title: "Revocation Status",
+	Units: "boolean",
+	Fam:   "revocation",
+	Ctx:   "x509check.revocation_status",
+	Opts:  Opts{StoreFirst: true},
+	Dims: Dims{
+		{ID: "revoked"},
+	},
+}
+
+var chainVerificationChart = module.Chart{
+	ID:    "chain_verification",
+	Title: "Certificate Chain Verification Status",
+	Units: "status",
+	Fam:   "chain",
+	Ctx:   "x509check.chain_verification",
+	Opts:  Opts{StoreFirst: true},
+	Dims: Dims{
+		{ID: "chain_verified", Name: "verified"},
+		{ID: "chain_not_verified", Name: "not_verified"},
 	},
+}
+
+var ocspStaplingChart = module.Chart{
+	ID:    "ocsp_stapling",
+	Title: "OCSP Stapling Status",
+	Units: "status",
+	Fam:   "revocation",
+	Ctx:   "x509check.ocsp_stapling",
+	Opts:  Opts{StoreFirst: true},
+	Dims: Dims{
+		{ID: "ocsp_stapling_good", Name: "good"},
+		{ID: "ocsp_stapling_revoked", Name: "revoked"},
+		{ID: "ocsp_stapling_unknown", Name: "unknown"},
+		{ID: "ocsp_stapling_not_stapled", Name: "not_stapled"},
+	},
+}
+
+// chainCharts are the charts of every certificate of the chain, the placeholders are the certificate labels.
+var chainCharts = Charts{
 	{
-		ID:    "revocation_status",
-		Title: "Revocation Status",
-		Units: "boolean",
-		Fam:   "revocation",
-		Ctx:   "x509check.revocation_status",
+		ID:    "chain_cert_{{.depth}}_time_until_expiration",
+		Title: "Time Until Chain Certificate Expiration",
+		Units: "seconds",
+		Fam:   "chain",
+		Ctx:   "x509check.chain_time_until_expiration",
 		Opts:  Opts{StoreFirst: true},
 		Dims: Dims{
-			{ID: "revoked"},
+			{ID: "chain_cert_{{.depth}}_expiry", Name: "expiry"},
 		},
 	},
 }
+
+// fileCharts returns the charts of every file of the glob mode, they have the same contexts as the single source charts.
+func fileCharts(checkRevocation, verifyChain bool) Charts {
+	charts := Charts{timeUntilExpirationChart.Copy()}
+	if checkRevocation {
+		charts = append(charts, revocationStatusChart.Copy())
+	}
+	if verifyChain {
+		charts = append(charts, chainVerificationChart.Copy())
+	}
+
+	for _, chart := range charts {
+		chart.ID = "file_{{id .file}}_" + chart.ID
+		chart.Fam = "{{.file}}"
+		for _, dim := range chart.Dims {
+			if dim.Name == "" {
+				dim.Name = dim.ID
+			}
+			dim.ID = "file_{{id .file}}_" + dim.ID
+		}
+	}
+	return charts
+}
+
+// initCharts adds the charts of the single source mode, the charts of the glob mode are added on the fly.
+func (x *X509Check) initCharts() {
+	if _, ok := x.prov.(*fromGlob); ok {
+		x.fileTmpl = module.MustNewChartTemplate(fileCharts(x.CheckRevocation, x.verifyChain()))
+		return
+	}
+
+	charts := Charts{timeUntilExpirationChart.Copy()}
+	if x.CheckRevocation {
+		charts = append(charts, revocationStatusChart.Copy())
+	}
+	if x.verifyChain() {
+		charts = append(charts, chainVerificationChart.Copy())
+	}
+	if _, ok := x.prov.(*fromNet); ok {
+		charts = append(charts, ocspStaplingChart.Copy())
+	}
+	_ = x.charts.Add(charts...)
+}
@@ -3,50 +3,201 @@
 package x509check
 
 import (
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/netdata/go.d.plugin/agent/module"
+
 	"github.com/cloudflare/cfssl/revoke"
+	"golang.org/x/crypto/ocsp"
 )
 
 func (x *X509Check) collect() (map[string]int64, error) {
-	certs, err := x.prov.certificates()
+	sets, err := x.prov.certificates()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(certs) == 0 {
+	if _, ok := x.prov.(*fromGlob); ok {
+		return x.collectFiles(sets), nil
+	}
+
+	if len(sets) == 0 || len(sets[0].certs) == 0 {
 		return nil, fmt.Errorf("no certificate was provided by '%s'", x.Config.Source)
 	}
+	set := sets[0]
 
 	mx := make(map[string]int64)
 
-	x.collectExpiration(mx, certs)
+	x.collectExpiration(mx, "", set.certs)
 	if x.CheckRevocation {
-		x.collectRevocation(mx, certs)
+		x.collectRevocation(mx, "", set.certs)
 	}
+	if x.verifyChain() {
+		x.collectChainVerification(mx, "", set)
+	}
+	if set.tls {
+		x.collectOCSPStapling(mx, set)
+	}
+	x.collectChain(mx, set.certs)
 
 	return mx, nil
 }
 
-func (x X509Check) collectExpiration(mx map[string]int64, certs []*x509.Certificate) {
+// collectFiles collects the certificates of every file, the charts of the new files are added
+// and the charts of the gone ones are removed.
+func (x *X509Check) collectFiles(sets []certSet) map[string]int64 {
+	mx := make(map[string]int64)
+	seen := make(map[string]bool)
+
+	for _, set := range sets {
+		seen[set.file] = true
+		if !x.chartsByFile[set.file] {
+			x.Debugf("adding certificate file '%s'", set.file)
+			if err := x.fileTmpl.Add(x.charts, set.file, map[string]string{"file": set.file}); err != nil {
+				x.Warning(err)
+				continue
+			}
+			x.chartsByFile[set.file] = true
+		}
+
+		px := "file_" + module.CleanID(set.file) + "_"
+		x.collectExpiration(mx, px, set.certs)
+		if x.CheckRevocation {
+			x.collectRevocation(mx, px, set.certs)
+		}
+		if x.verifyChain() {
+			x.collectChainVerification(mx, px, set)
+		}
+	}
+
+	for file := range x.chartsByFile {
+		if !seen[file] {
+			x.Debugf("removing certificate file '%s'", file)
+			x.fileTmpl.Remove(file)
+			delete(x.chartsByFile, file)
+		}
+	}
+
+	return mx
+}
+
+func (x X509Check) collectExpiration(mx map[string]int64, px string, certs []*x509.Certificate) {
 	expiry := time.Until(certs[0].NotAfter).Seconds()
-	mx["expiry"] = int64(expiry)
+	mx[px+"expiry"] = int64(expiry)
 	mx["days_until_expiration_warning"] = x.DaysUntilWarn
 	mx["days_until_expiration_critical"] = x.DaysUntilCritical
-
 }
 
-func (x X509Check) collectRevocation(mx map[string]int64, certs []*x509.Certificate) {
+func (x X509Check) collectRevocation(mx map[string]int64, px string, certs []*x509.Certificate) {
 	rev, ok, err := revoke.VerifyCertificateError(certs[0])
 	if err != nil {
 		x.Debug(err)
 	}
 	switch {
 	case ok && rev:
-		mx["revoked"] = 1
+		mx[px+"revoked"] = 1
 	case ok && !rev:
-		mx["revoked"] = 0
+		mx[px+"revoked"] = 0
+	}
+}
+
+// collectChainVerification verifies the chain against the CA bundle ('tls_ca' or the system one),
+// the leaf certificate of the network sources is verified against the server name too.
+func (x X509Check) collectChainVerification(mx map[string]int64, px string, set certSet) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range set.certs[1:] {
+		intermediates.AddCert(cert)
 	}
+
+	_, err := set.certs[0].Verify(x509.VerifyOptions{
+		Roots:         x.roots,
+		Intermediates: intermediates,
+		DNSName:       set.serverName,
+	})
+
+	mx[px+"chain_verified"] = 0
+	mx[px+"chain_not_verified"] = 0
+	if err != nil {
+		x.Debugf("certificate chain verification: %v", err)
+		mx[px+"chain_not_verified"] = 1
+	} else {
+		mx[px+"chain_verified"] = 1
+	}
+}
+
+// collectOCSPStapling checks the stapled OCSP response, its signature is verified if the issuer is in the chain.
+func (x X509Check) collectOCSPStapling(mx map[string]int64, set certSet) {
+	for _, v := range []string{"good", "revoked", "unknown", "not_stapled"} {
+		mx["ocsp_stapling_"+v] = 0
+	}
+	if len(set.ocspStaple) == 0 {
+		mx["ocsp_stapling_not_stapled"] = 1
+		return
+	}
+
+	var issuer *x509.Certificate
+	if len(set.certs) > 1 {
+		issuer = set.certs[1]
+	}
+	resp, err := ocsp.ParseResponseForCert(set.ocspStaple, set.certs[0], issuer)
+	switch {
+	case err != nil:
+		x.Debugf("stapled OCSP response: %v", err)
+		mx["ocsp_stapling_unknown"] = 1
+	case resp.Status == ocsp.Good:
+		mx["ocsp_stapling_good"] = 1
+	case resp.Status == ocsp.Revoked:
+		mx["ocsp_stapling_revoked"] = 1
+	default:
+		mx["ocsp_stapling_unknown"] = 1
+	}
+}
+
+// collectChain collects the expiration of every chain certificate. The charts of a chain position are recreated
+// if the certificate is replaced, so the labels are up to date.
+func (x *X509Check) collectChain(mx map[string]int64, certs []*x509.Certificate) {
+	for i, cert := range certs {
+		depth := strconv.Itoa(i)
+		fp := fingerprint(cert)
+
+		if x.chainCerts[depth] != fp {
+			if x.chainTmpl.Has(depth) {
+				x.chainTmpl.Remove(depth)
+			}
+			labels := map[string]string{
+				"depth":   depth,
+				"subject": cert.Subject.CommonName,
+				"issuer":  cert.Issuer.CommonName,
+				"serial":  cert.SerialNumber.String(),
+			}
+			if err := x.chainTmpl.Add(x.charts, depth, labels); err != nil {
+				x.Warning(err)
+				continue
+			}
+			x.chainCerts[depth] = fp
+		}
+
+		mx["chain_cert_"+depth+"_expiry"] = int64(time.Until(cert.NotAfter).Seconds())
+	}
+
+	for depth := range x.chainCerts {
+		if i, _ := strconv.Atoi(depth); i >= len(certs) {
+			x.chainTmpl.Remove(depth)
+			delete(x.chainCerts, depth)
+		}
+	}
+}
+
+func (x X509Check) verifyChain() bool {
+	return !x.InsecureSkipVerify
+}
+
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
 }
@@ -8,124 +8,180 @@ import (
 	"encoding/pem"
 	"fmt"
 	"net"
-	"net/smtp"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
-
-	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 )
 
 type provider interface {
-	certificates() ([]*x509.Certificate, error)
+	certificates() ([]certSet, error)
+}
+
+// certSet is the certificates of a TLS peer or a file, the first one is the leaf.
+type certSet struct {
+	file       string // the file path, set only in the glob mode
+	certs      []*x509.Certificate
+	serverName string // the name the leaf certificate is verified against
+	tls        bool   // the certificates are from a TLS handshake
+	ocspStaple []byte
 }
 
 type fromFile struct {
 	path string
 }
 
-type fromNet struct {
-	url       *url.URL
-	tlsConfig *tls.Config
-	timeout   time.Duration
+// fromGlob provides the certificates of all the files matching the pattern.
+type fromGlob struct {
+	pattern string
 }
 
-type fromSMTP struct {
+type fromNet struct {
 	url       *url.URL
 	tlsConfig *tls.Config
 	timeout   time.Duration
+	starttls  starttlsFunc // nil if TLS is used from the start
 }
 
-func newProvider(config Config) (provider, error) {
+func newProvider(config Config, tlsCfg *tls.Config) (provider, error) {
 	sourceURL, err := url.Parse(config.Source)
 	if err != nil {
 		return nil, fmt.Errorf("source parse: %v", err)
 	}
 
-	tlsCfg, err := tlscfg.NewTLSConfig(config.TLSConfig)
-	if err != nil {
-		return nil, fmt.Errorf("create tls config: %v", err)
-	}
-
 	if tlsCfg == nil {
 		tlsCfg = &tls.Config{}
 	}
-	tlsCfg.ServerName = sourceURL.Hostname()
+	tlsCfg = tlsCfg.Clone()
+	if tlsCfg.ServerName == "" {
+		tlsCfg.ServerName = sourceURL.Hostname()
+	}
+	// the chain is verified after the handshake, so the invalid certificates are reported rather than not collected
+	tlsCfg.InsecureSkipVerify = true
 
 	switch sourceURL.Scheme {
 	case "file":
-		return &fromFile{path: sourceURL.Path}, nil
+		if !isGlob(sourceURL.Path) {
+			return &fromFile{path: sourceURL.Path}, nil
+		}
+		if _, err := filepath.Match(sourceURL.Path, ""); err != nil {
+			return nil, fmt.Errorf("file pattern '%s': %v", sourceURL.Path, err)
+		}
+		return &fromGlob{pattern: sourceURL.Path}, nil
 	case "https", "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
 		if sourceURL.Scheme == "https" {
 			sourceURL.Scheme = "tcp"
 		}
 		return &fromNet{url: sourceURL, tlsConfig: tlsCfg, timeout: config.Timeout.Duration}, nil
-	case "smtp":
-		sourceURL.Scheme = "tcp"
-		return &fromSMTP{url: sourceURL, tlsConfig: tlsCfg, timeout: config.Timeout.Duration}, nil
-	default:
+	}
+
+	starttls, ok := starttlsProtocols[sourceURL.Scheme]
+	if !ok {
 		return nil, fmt.Errorf("unsupported scheme '%s'", sourceURL)
 	}
+	sourceURL.Scheme = "tcp"
+	return &fromNet{url: sourceURL, tlsConfig: tlsCfg, timeout: config.Timeout.Duration, starttls: starttls}, nil
 }
 
-func (f fromFile) certificates() ([]*x509.Certificate, error) {
-	content, err := os.ReadFile(f.path)
+func (f fromFile) certificates() ([]certSet, error) {
+	certs, err := readCertificates(f.path)
 	if err != nil {
-		return nil, fmt.Errorf("error on reading '%s': %v", f.path, err)
-	}
-
-	block, _ := pem.Decode(content)
-	if block == nil {
-		return nil, fmt.Errorf("error on decoding '%s': %v", f.path, err)
+		return nil, err
 	}
+	return []certSet{{certs: certs}}, nil
+}
 
-	cert, err := x509.ParseCertificate(block.Bytes)
+// certificates returns the certificates of the matching files, the files without certificates are skipped.
+func (f fromGlob) certificates() ([]certSet, error) {
+	matches, err := filepath.Glob(f.pattern)
 	if err != nil {
-		return nil, fmt.Errorf("error on parsing certificate '%s': %v", f.path, err)
+		return nil, fmt.Errorf("error on matching '%s': %v", f.pattern, err)
 	}
 
-	return []*x509.Certificate{cert}, nil
+	var sets []certSet
+	for _, path := range matches {
+		if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		certs, err := readCertificates(path)
+		if err != nil {
+			continue
+		}
+		sets = append(sets, certSet{file: path, certs: certs})
+	}
+	return sets, nil
 }
 
-func (f fromNet) certificates() ([]*x509.Certificate, error) {
+func (f fromNet) certificates() ([]certSet, error) {
 	ipConn, err := net.DialTimeout(f.url.Scheme, f.url.Host, f.timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error on dial to '%s': %v", f.url, err)
 	}
 	defer func() { _ = ipConn.Close() }()
 
+	if f.timeout > 0 {
+		_ = ipConn.SetDeadline(time.Now().Add(f.timeout))
+	}
+
+	if f.starttls != nil {
+		host, _, _ := net.SplitHostPort(f.url.Host)
+		if err := f.starttls(ipConn, host); err != nil {
+			return nil, fmt.Errorf("error on startTLS with '%s': %v", f.url, err)
+		}
+	}
+
 	conn := tls.Client(ipConn, f.tlsConfig.Clone())
 	defer func() { _ = conn.Close() }()
 	if err := conn.Handshake(); err != nil {
 		return nil, fmt.Errorf("error on SSL handshake with '%s': %v", f.url, err)
 	}
 
-	certs := conn.ConnectionState().PeerCertificates
-	return certs, nil
+	state := conn.ConnectionState()
+	return []certSet{{
+		certs:      state.PeerCertificates,
+		serverName: f.tlsConfig.ServerName,
+		tls:        true,
+		ocspStaple: state.OCSPResponse,
+	}}, nil
 }
 
-func (f fromSMTP) certificates() ([]*x509.Certificate, error) {
-	ipConn, err := net.DialTimeout(f.url.Scheme, f.url.Host, f.timeout)
+// readCertificates reads the PEM encoded certificates, or the DER encoded ones if there are no PEM blocks.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("error on dial to '%s': %v", f.url, err)
+		return nil, fmt.Errorf("error on reading '%s': %v", path, err)
 	}
-	defer func() { _ = ipConn.Close() }()
 
-	host, _, _ := net.SplitHostPort(f.url.Host)
-	smtpClient, err := smtp.NewClient(ipConn, host)
-	if err != nil {
-		return nil, fmt.Errorf("error on creating SMTP client: %v", err)
+	var certs []*x509.Certificate
+	var found bool
+	for rest := content; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		found = true
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error on parsing certificate '%s': %v", path, err)
+		}
+		certs = append(certs, cert)
 	}
-	defer func() { _ = smtpClient.Quit() }()
 
-	err = smtpClient.StartTLS(f.tlsConfig.Clone())
-	if err != nil {
-		return nil, fmt.Errorf("error on startTLS with '%s': %v", f.url, err)
+	if !found {
+		if certs, err = x509.ParseCertificates(content); err != nil {
+			return nil, fmt.Errorf("error on decoding '%s': neither PEM nor DER: %v", path, err)
+		}
 	}
-
-	conn, ok := smtpClient.TLSConnectionState()
-	if !ok {
-		return nil, fmt.Errorf("startTLS didn't succeed")
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates in '%s'", path)
 	}
-	return conn.PeerCertificates, nil
+	return certs, nil
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
 }
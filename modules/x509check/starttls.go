@@ -0,0 +1,308 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package x509check
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+)
+
+// starttlsFunc upgrades the plain text connection, the TLS handshake follows.
+type starttlsFunc func(conn net.Conn, host string) error
+
+var starttlsProtocols = map[string]starttlsFunc{
+	"smtp":       starttlsSMTP,
+	"imap":       starttlsIMAP,
+	"pop3":       starttlsPOP3,
+	"ldap":       starttlsLDAP,
+	"postgres":   starttlsPostgres,
+	"postgresql": starttlsPostgres,
+	"mysql":      starttlsMySQL,
+	"xmpp":       starttlsXMPP,
+}
+
+// maxReplySize limits the size of the server replies read before the TLS handshake.
+const maxReplySize = 64 * 1024
+
+func starttlsSMTP(conn net.Conn, _ string) error {
+	tp := textproto.NewConn(conn)
+
+	if _, _, err := tp.ReadResponse(220); err != nil {
+		return fmt.Errorf("greeting: %v", err)
+	}
+	if err := tp.PrintfLine("EHLO localhost"); err != nil {
+		return err
+	}
+	if _, _, err := tp.ReadResponse(250); err != nil {
+		return fmt.Errorf("EHLO: %v", err)
+	}
+	if err := tp.PrintfLine("STARTTLS"); err != nil {
+		return err
+	}
+	if _, _, err := tp.ReadResponse(220); err != nil {
+		return fmt.Errorf("STARTTLS: %v", err)
+	}
+	return nil
+}
+
+func starttlsIMAP(conn net.Conn, _ string) error {
+	tp := textproto.NewConn(conn)
+
+	line, err := tp.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected greeting '%s'", line)
+	}
+	if err := tp.PrintfLine("a1 STARTTLS"); err != nil {
+		return err
+	}
+	for {
+		// untagged responses (e.g. capabilities) can precede the tagged one
+		if line, err = tp.ReadLine(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "a1 ") {
+			break
+		}
+	}
+	if !strings.HasPrefix(line, "a1 OK") {
+		return fmt.Errorf("STARTTLS: unexpected response '%s'", line)
+	}
+	return nil
+}
+
+func starttlsPOP3(conn net.Conn, _ string) error {
+	tp := textproto.NewConn(conn)
+
+	line, err := tp.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected greeting '%s'", line)
+	}
+	if err := tp.PrintfLine("STLS"); err != nil {
+		return err
+	}
+	if line, err = tp.ReadLine(); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("STLS: unexpected response '%s'", line)
+	}
+	return nil
+}
+
+// ldapStartTLSRequest is the LDAP StartTLS extended request (RFC 4511), message id 1.
+var ldapStartTLSRequest = append([]byte{
+	0x30, 0x1d, // LDAPMessage
+	0x02, 0x01, 0x01, // messageID
+	0x77, 0x18, // extendedReq
+	0x80, 0x16, // requestName
+}, "1.3.6.1.4.1.1466.20037"...)
+
+func starttlsLDAP(conn net.Conn, _ string) error {
+	if _, err := conn.Write(ldapStartTLSRequest); err != nil {
+		return err
+	}
+
+	msg, err := readBER(conn)
+	if err != nil {
+		return err
+	}
+	tag, content, _, err := parseBER(msg)
+	if err != nil || tag != 0x30 {
+		return errors.New("malformed response")
+	}
+	// messageID
+	if _, _, content, err = parseBER(content); err != nil {
+		return errors.New("malformed response")
+	}
+	// extendedResp
+	if tag, content, _, err = parseBER(content); err != nil || tag != 0x78 {
+		return errors.New("malformed response")
+	}
+	// resultCode
+	tag, code, _, err := parseBER(content)
+	if err != nil || tag != 0x0a || len(code) != 1 {
+		return errors.New("malformed response")
+	}
+	if code[0] != 0 {
+		return fmt.Errorf("StartTLS: result code %d", code[0])
+	}
+	return nil
+}
+
+// readBER reads a BER encoded element.
+func readBER(r io.Reader) ([]byte, error) {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	length := int(hdr[1])
+	if hdr[1]&0x80 != 0 {
+		n := int(hdr[1] & 0x7f)
+		if n == 0 || n > 3 {
+			return nil, errors.New("unsupported BER length")
+		}
+		lb := make([]byte, n)
+		if _, err := io.ReadFull(r, lb); err != nil {
+			return nil, err
+		}
+		hdr = append(hdr, lb...)
+		length = 0
+		for _, b := range lb {
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxReplySize {
+		return nil, errors.New("BER element is too large")
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return append(hdr, content...), nil
+}
+
+// parseBER returns the tag and the content of the first element and the bytes after it.
+func parseBER(b []byte) (tag byte, content, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, io.ErrUnexpectedEOF
+	}
+	tag, length, off := b[0], int(b[1]), 2
+	if b[1]&0x80 != 0 {
+		n := int(b[1] & 0x7f)
+		if n == 0 || n > 3 || len(b) < 2+n {
+			return 0, nil, nil, errors.New("unsupported BER length")
+		}
+		length = 0
+		for _, v := range b[2 : 2+n] {
+			length = length<<8 | int(v)
+		}
+		off += n
+	}
+	if len(b) < off+length {
+		return 0, nil, nil, io.ErrUnexpectedEOF
+	}
+	return tag, b[off : off+length], b[off+length:], nil
+}
+
+// postgresSSLRequest is the SSLRequest message: the length and the SSL request code.
+var postgresSSLRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+func starttlsPostgres(conn net.Conn, _ string) error {
+	if _, err := conn.Write(postgresSSLRequest); err != nil {
+		return err
+	}
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	if resp[0] != 'S' {
+		return fmt.Errorf("the server refused SSL (%q)", resp[0])
+	}
+	return nil
+}
+
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	mysqlCharsetUTF8            = 33
+)
+
+func starttlsMySQL(conn net.Conn, _ string) error {
+	r := bufio.NewReader(conn)
+
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return err
+	}
+	length := int(hdr[0]) | int(hdr[1])<<8 | int(hdr[2])<<16
+	if length == 0 || length > maxReplySize {
+		return fmt.Errorf("unexpected handshake packet length %d", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return err
+	}
+	if payload[0] == 0xff {
+		return errors.New("the server returned an error packet")
+	}
+	if payload[0] != 10 {
+		return fmt.Errorf("unsupported protocol version %d", payload[0])
+	}
+
+	// server version (null terminated), connection id (4), auth plugin data part 1 (8), filler (1), capabilities (2)
+	i := bytes.IndexByte(payload[1:], 0)
+	pos := 1 + i + 1 + 4 + 8 + 1
+	if i < 0 || len(payload) < pos+2 {
+		return errors.New("malformed handshake packet")
+	}
+	if binary.LittleEndian.Uint16(payload[pos:])&mysqlClientSSL == 0 {
+		return errors.New("the server doesn't support SSL")
+	}
+
+	req := make([]byte, 4+32)
+	req[0] = 32
+	req[3] = hdr[3] + 1
+	binary.LittleEndian.PutUint32(req[4:],
+		mysqlClientLongPassword|mysqlClientProtocol41|mysqlClientSSL|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(req[8:], 1<<24)
+	req[12] = mysqlCharsetUTF8
+	_, err := conn.Write(req)
+	return err
+}
+
+func starttlsXMPP(conn net.Conn, host string) error {
+	r := bufio.NewReader(io.LimitReader(conn, maxReplySize))
+
+	_, err := fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream to='%s' xmlns='jabber:client' "+
+		"xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>", host)
+	if err != nil {
+		return err
+	}
+	features, err := readUntil(r, "</stream:features>")
+	if err != nil {
+		return fmt.Errorf("stream features: %v", err)
+	}
+	if !strings.Contains(features, "<starttls") {
+		return errors.New("the server doesn't support STARTTLS")
+	}
+
+	if _, err := io.WriteString(conn, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"); err != nil {
+		return err
+	}
+	resp, err := readUntil(r, ">")
+	if err != nil {
+		return fmt.Errorf("STARTTLS: %v", err)
+	}
+	if !strings.Contains(resp, "<proceed") {
+		return fmt.Errorf("STARTTLS: unexpected response '%s'", resp)
+	}
+	return nil
+}
+
+// readUntil reads until the string is read.
+func readUntil(r *bufio.Reader, s string) (string, error) {
+	var sb strings.Builder
+	for !strings.HasSuffix(sb.String(), s) {
+		b, err := r.ReadByte()
+		if err != nil {
+			return sb.String(), err
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package x509check
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestX509Check_Collect_STARTTLS(t *testing.T) {
+	tests := map[string]struct {
+		scheme    string
+		negotiate func(conn net.Conn) error
+		wantFail  bool
+	}{
+		"smtp":                     {scheme: "smtp", negotiate: serveSMTP},
+		"imap":                     {scheme: "imap", negotiate: serveIMAP},
+		"pop3":                     {scheme: "pop3", negotiate: servePOP3},
+		"ldap":                     {scheme: "ldap", negotiate: serveLDAP},
+		"postgres":                 {scheme: "postgres", negotiate: servePostgres('S')},
+		"mysql":                    {scheme: "mysql", negotiate: serveMySQL(mysqlClientSSL)},
+		"xmpp":                     {scheme: "xmpp", negotiate: serveXMPP("<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")},
+		"postgres without SSL":     {scheme: "postgres", negotiate: servePostgres('N'), wantFail: true},
+		"mysql without SSL":        {scheme: "mysql", negotiate: serveMySQL(0), wantFail: true},
+		"xmpp without STARTTLS":    {scheme: "xmpp", negotiate: serveXMPP(""), wantFail: true},
+		"smtp refused STARTTLS":    {scheme: "smtp", negotiate: serveSMTPRefused, wantFail: true},
+		"ldap non-zero resultCode": {scheme: "ldap", negotiate: serveLDAPError, wantFail: true},
+	}
+
+	pki := newTestPKI(t, time.Now().Add(time.Hour*24*30))
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr := startTLSServer(t, pki.tlsCertificate(nil), test.negotiate)
+
+			x509Check := New()
+			x509Check.Source = test.scheme + "://" + addr
+			x509Check.TLSCA = pki.writeRootCA(t)
+			require.True(t, x509Check.Init())
+
+			mx := x509Check.Collect()
+
+			if test.wantFail {
+				assert.Nil(t, mx)
+				return
+			}
+			require.NotNil(t, mx)
+			assert.InDelta(t, time.Until(pki.leaf.NotAfter).Seconds(), mx["expiry"], 10)
+			assert.Equal(t, int64(1), mx["chain_verified"])
+			assert.Equal(t, int64(1), mx["ocsp_stapling_not_stapled"])
+		})
+	}
+}
+
+func serveSMTP(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	return serveLines(conn, r, "220 mx.example.org ESMTP\r\n",
+		"EHLO", "250-mx.example.org\r\n250 STARTTLS\r\n",
+		"STARTTLS", "220 2.0.0 Ready to start TLS\r\n",
+	)
+}
+
+func serveSMTPRefused(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	_ = serveLines(conn, r, "220 mx.example.org ESMTP\r\n",
+		"EHLO", "250 mx.example.org\r\n",
+		"STARTTLS", "502 5.5.1 Command not implemented\r\n",
+	)
+	return errors.New("STARTTLS refused")
+}
+
+func serveIMAP(conn net.Conn) error {
+	return serveLines(conn, bufio.NewReader(conn), "* OK IMAP4rev1 ready\r\n",
+		"a1 STARTTLS", "* CAPABILITY IMAP4rev1\r\na1 OK Begin TLS negotiation now\r\n",
+	)
+}
+
+func servePOP3(conn net.Conn) error {
+	return serveLines(conn, bufio.NewReader(conn), "+OK POP3 ready\r\n",
+		"STLS", "+OK Begin TLS negotiation\r\n",
+	)
+}
+
+// serveLines writes the greeting and then answers every expected command with the reply that follows it.
+func serveLines(conn net.Conn, r *bufio.Reader, greeting string, cmdReplies ...string) error {
+	if _, err := io.WriteString(conn, greeting); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(cmdReplies); i += 2 {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, cmdReplies[i]) {
+			return fmt.Errorf("unexpected command '%s'", line)
+		}
+		if _, err := io.WriteString(conn, cmdReplies[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serveLDAP(conn net.Conn) error {
+	return serveLDAPResult(conn, 0)
+}
+
+func serveLDAPError(conn net.Conn) error {
+	_ = serveLDAPResult(conn, 2)
+	return errors.New("protocol error")
+}
+
+func serveLDAPResult(conn net.Conn, resultCode byte) error {
+	req, err := readBER(conn)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(req, ldapStartTLSRequest) {
+		return fmt.Errorf("unexpected request %x", req)
+	}
+	// LDAPMessage { messageID 1, extendedResp { resultCode, matchedDN "", diagnosticMessage "" } }
+	_, err = conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, resultCode, 0x04, 0x00, 0x04, 0x00})
+	return err
+}
+
+func servePostgres(reply byte) func(conn net.Conn) error {
+	return func(conn net.Conn) error {
+		req := make([]byte, len(postgresSSLRequest))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return err
+		}
+		if !bytes.Equal(req, postgresSSLRequest) {
+			return fmt.Errorf("unexpected request %x", req)
+		}
+		if _, err := conn.Write([]byte{reply}); err != nil {
+			return err
+		}
+		if reply != 'S' {
+			return errors.New("SSL is not supported")
+		}
+		return nil
+	}
+}
+
+func serveMySQL(caps uint16) func(conn net.Conn) error {
+	return func(conn net.Conn) error {
+		var payload bytes.Buffer
+		payload.WriteByte(10)
+		payload.WriteString("8.0.35\x00")
+		payload.Write([]byte{1, 0, 0, 0})
+		payload.WriteString("12345678\x00")
+		_ = binary.Write(&payload, binary.LittleEndian, caps|mysqlClientProtocol41|mysqlClientSecureConnection)
+
+		hdr := []byte{byte(payload.Len()), byte(payload.Len() >> 8), byte(payload.Len() >> 16), 0}
+		if _, err := conn.Write(append(hdr, payload.Bytes()...)); err != nil {
+			return err
+		}
+		if caps&mysqlClientSSL == 0 {
+			return errors.New("SSL is not supported")
+		}
+
+		req := make([]byte, 4+32)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return err
+		}
+		if req[3] != 1 || binary.LittleEndian.Uint32(req[4:])&mysqlClientSSL == 0 {
+			return fmt.Errorf("unexpected SSL request %x", req)
+		}
+		return nil
+	}
+}
+
+func serveXMPP(starttls string) func(conn net.Conn) error {
+	return func(conn net.Conn) error {
+		r := bufio.NewReader(conn)
+		if _, err := readUntil(r, "version='1.0'>"); err != nil {
+			return err
+		}
+		_, err := io.WriteString(conn, "<?xml version='1.0'?><stream:stream from='example.org' xmlns='jabber:client' "+
+			"xmlns:stream='http://etherx.jabber.org/streams' version='1.0'><stream:features>"+starttls+
+			"<mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>PLAIN</mechanism></mechanisms>"+
+			"</stream:features>")
+		if err != nil {
+			return err
+		}
+		if starttls == "" {
+			return errors.New("STARTTLS is not supported")
+		}
+		if _, err := readUntil(r, "/>"); err != nil {
+			return err
+		}
+		_, err = io.WriteString(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+		return err
+	}
+}
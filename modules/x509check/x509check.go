@@ -3,7 +3,9 @@
 package x509check
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/tlscfg"
@@ -32,6 +34,10 @@ func New() *X509Check {
 			DaysUntilWarn:     14,
 			DaysUntilCritical: 7,
 		},
+		charts:       &module.Charts{},
+		chainTmpl:    module.MustNewChartTemplate(chainCharts),
+		chainCerts:   make(map[string]string),
+		chartsByFile: make(map[string]bool),
 	}
 }
 
@@ -47,7 +53,16 @@ type Config struct {
 type X509Check struct {
 	module.Base
 	Config `yaml:",inline"`
-	prov   provider
+
+	charts    *module.Charts
+	chainTmpl *module.ChartTemplate
+	fileTmpl  *module.ChartTemplate
+
+	prov  provider
+	roots *x509.CertPool // nil means the system pool
+
+	chainCerts   map[string]string // chain depth => certificate fingerprint
+	chartsByFile map[string]bool
 }
 
 func (x X509Check) validateConfig() error {
@@ -58,7 +73,15 @@ func (x X509Check) validateConfig() error {
 }
 
 func (x *X509Check) initProvider() error {
-	p, err := newProvider(x.Config)
+	tlsCfg, err := tlscfg.NewTLSConfig(x.TLSConfig)
+	if err != nil {
+		return fmt.Errorf("create tls config: %v", err)
+	}
+	if tlsCfg != nil {
+		x.roots = tlsCfg.RootCAs
+	}
+
+	p, err := newProvider(x.Config, tlsCfg)
 	if err != nil {
 		return err
 	}
@@ -77,6 +100,8 @@ func (x *X509Check) Init() bool {
 		x.Errorf("error on initializing certificate provider: %v", err)
 		return false
 	}
+
+	x.initCharts()
 	return true
 }
 
@@ -84,11 +109,8 @@ func (x *X509Check) Check() bool {
 	return len(x.Collect()) > 0
 }
 
-func (x X509Check) Charts() *Charts {
-	if x.CheckRevocation {
-		return withRevocationCharts.Copy()
-	}
-	return charts.Copy()
+func (x *X509Check) Charts() *Charts {
+	return x.charts
 }
 
 func (x *X509Check) Collect() map[string]int64 {
@@ -3,15 +3,28 @@
 package x509check
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
+	stdnet "net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
 )
 
 func TestNew(t *testing.T) {
@@ -33,8 +46,9 @@ func TestX509Check_Charts(t *testing.T) {
 func TestX509Check_Init(t *testing.T) {
 	const (
 		file = iota
+		glob
 		net
-		smtp
+		starttls
 	)
 	tests := map[string]struct {
 		config       Config
@@ -55,7 +69,19 @@ func TestX509Check_Init(t *testing.T) {
 		},
 		"ok from smtp": {
 			config:       Config{Source: "smtp://smtp.my_mail.org:587"},
-			providerType: smtp,
+			providerType: starttls,
+		},
+		"ok from imap": {
+			config:       Config{Source: "imap://imap.my_mail.org:143"},
+			providerType: starttls,
+		},
+		"ok from file glob": {
+			config:       Config{Source: "file:///etc/ssl/certs/*.pem"},
+			providerType: glob,
+		},
+		"invalid file glob": {
+			config: Config{Source: "file:///etc/ssl/certs/[.pem"},
+			err:    true,
 		},
 		"empty source": {
 			config: Config{Source: ""},
@@ -84,10 +110,14 @@ func TestX509Check_Init(t *testing.T) {
 				switch test.providerType {
 				case file:
 					_, typeOK = x509Check.prov.(*fromFile)
+				case glob:
+					_, typeOK = x509Check.prov.(*fromGlob)
 				case net:
-					_, typeOK = x509Check.prov.(*fromNet)
-				case smtp:
-					_, typeOK = x509Check.prov.(*fromSMTP)
+					p, ok := x509Check.prov.(*fromNet)
+					typeOK = ok && p.starttls == nil
+				case starttls:
+					p, ok := x509Check.prov.(*fromNet)
+					typeOK = ok && p.starttls != nil
 				}
 
 				assert.True(t, typeOK)
@@ -98,7 +128,7 @@ func TestX509Check_Init(t *testing.T) {
 
 func TestX509Check_Check(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{certs: []*x509.Certificate{{}}}
+	x509Check.prov = &mockProvider{certs: []*x509.Certificate{prepareCertificate(t)}}
 
 	assert.True(t, x509Check.Check())
 }
@@ -112,7 +142,9 @@ func TestX509Check_Check_ReturnsFalseOnProviderError(t *testing.T) {
 
 func TestX509Check_Collect(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{certs: []*x509.Certificate{{}}}
+	x509Check.Source = "file:///home/me/cert.pem"
+	require.True(t, x509Check.Init())
+	x509Check.prov = &mockProvider{certs: []*x509.Certificate{prepareCertificate(t)}}
 
 	collected := x509Check.Collect()
 
@@ -120,6 +152,165 @@ func TestX509Check_Collect(t *testing.T) {
 	ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, collected)
 }
 
+func TestX509Check_Collect_TLS(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(time.Hour*24*30))
+
+	tests := map[string]struct {
+		staple    func(t *testing.T, pki *testPKI) []byte
+		withCA    bool
+		skipCheck bool
+		expected  map[string]int64
+	}{
+		"verified chain, good staple": {
+			staple: func(t *testing.T, pki *testPKI) []byte { return pki.ocspResponse(t, ocsp.Good) },
+			withCA: true,
+			expected: map[string]int64{
+				"chain_verified":            1,
+				"chain_not_verified":        0,
+				"ocsp_stapling_good":        1,
+				"ocsp_stapling_revoked":     0,
+				"ocsp_stapling_unknown":     0,
+				"ocsp_stapling_not_stapled": 0,
+			},
+		},
+		"unknown root CA, revoked staple": {
+			staple: func(t *testing.T, pki *testPKI) []byte { return pki.ocspResponse(t, ocsp.Revoked) },
+			expected: map[string]int64{
+				"chain_verified":            0,
+				"chain_not_verified":        1,
+				"ocsp_stapling_good":        0,
+				"ocsp_stapling_revoked":     1,
+				"ocsp_stapling_unknown":     0,
+				"ocsp_stapling_not_stapled": 0,
+			},
+		},
+		"skip verify, no staple": {
+			skipCheck: true,
+			expected: map[string]int64{
+				"ocsp_stapling_good":        0,
+				"ocsp_stapling_revoked":     0,
+				"ocsp_stapling_unknown":     0,
+				"ocsp_stapling_not_stapled": 1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var staple []byte
+			if test.staple != nil {
+				staple = test.staple(t, pki)
+			}
+			addr := startTLSServer(t, pki.tlsCertificate(staple), nil)
+
+			x509Check := New()
+			x509Check.Source = "tcp://" + addr
+			x509Check.InsecureSkipVerify = test.skipCheck
+			if test.withCA {
+				x509Check.TLSCA = pki.writeRootCA(t)
+			}
+			require.True(t, x509Check.Init())
+
+			mx := x509Check.Collect()
+			require.NotNil(t, mx)
+
+			for k, v := range test.expected {
+				assert.Equalf(t, v, mx[k], "metric '%s'", k)
+			}
+			assert.Equal(t, !test.skipCheck, x509Check.Charts().Has(chainVerificationChart.ID))
+			assert.True(t, x509Check.Charts().Has(ocspStaplingChart.ID))
+			assert.True(t, x509Check.Charts().Has("chain_cert_0_time_until_expiration"))
+			assert.True(t, x509Check.Charts().Has("chain_cert_1_time_until_expiration"))
+			assert.InDelta(t, time.Until(pki.inter.NotAfter).Seconds(), mx["chain_cert_1_expiry"], 10)
+			ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, mx)
+		})
+	}
+}
+
+func TestX509Check_Collect_ChainCertificateReplaced(t *testing.T) {
+	x509Check := New()
+	x509Check.Source = "file:///home/me/cert.pem"
+	require.True(t, x509Check.Init())
+
+	pki := newTestPKI(t, time.Now().Add(time.Hour*24*30))
+	x509Check.prov = &mockProvider{certs: []*x509.Certificate{pki.leaf, pki.inter}}
+	require.NotNil(t, x509Check.Collect())
+
+	chart := x509Check.Charts().Get("chain_cert_1_time_until_expiration")
+	require.NotNil(t, chart)
+	assert.Contains(t, chart.Labels, module.Label{Key: "subject", Value: "Test Intermediate CA"})
+
+	x509Check.prov = &mockProvider{certs: []*x509.Certificate{pki.root}}
+	require.NotNil(t, x509Check.Collect())
+
+	assert.True(t, chart.Obsolete)
+	chart = lastChart(x509Check.Charts(), "chain_cert_0_time_until_expiration")
+	require.NotNil(t, chart)
+	assert.False(t, chart.Obsolete)
+	assert.Contains(t, chart.Labels, module.Label{Key: "subject", Value: "Test Root CA"})
+}
+
+func TestX509Check_Collect_FileGlob(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(time.Hour*24*30))
+	dir := t.TempDir()
+
+	leafFile := filepath.Join(dir, "leaf.pem")
+	require.NoError(t, os.WriteFile(leafFile, append(pemEncode(pki.leaf), pemEncode(pki.inter)...), 0644))
+	rootFile := filepath.Join(dir, "root.crt")
+	require.NoError(t, os.WriteFile(rootFile, pki.root.Raw, 0644))
+
+	x509Check := New()
+	x509Check.Source = "file://" + filepath.Join(dir, "*")
+	x509Check.TLSCA = pki.writeRootCA(t)
+	require.True(t, x509Check.Init())
+
+	mx := x509Check.Collect()
+	require.NotNil(t, mx)
+
+	for _, file := range []string{leafFile, rootFile} {
+		assert.Equal(t, int64(1), mx["file_"+file+"_chain_verified"])
+		assert.True(t, x509Check.Charts().Has("file_"+file+"_time_until_expiration"))
+	}
+	assert.InDelta(t, time.Until(pki.leaf.NotAfter).Seconds(), mx["file_"+leafFile+"_expiry"], 10)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, mx)
+
+	require.NoError(t, os.Remove(rootFile))
+	interFile := filepath.Join(dir, "inter.pem")
+	require.NoError(t, os.WriteFile(interFile, pemEncode(pki.inter), 0644))
+
+	mx = x509Check.Collect()
+	require.NotNil(t, mx)
+
+	_, ok := mx["file_"+rootFile+"_expiry"]
+	assert.False(t, ok)
+	assert.True(t, x509Check.Charts().Get("file_"+rootFile+"_time_until_expiration").Obsolete)
+	assert.Equal(t, int64(1), mx["file_"+interFile+"_chain_verified"])
+	assert.False(t, x509Check.Charts().Get("file_"+interFile+"_time_until_expiration").Obsolete)
+}
+
+func TestX509Check_Collect_FileGlob_PathWithSpaces(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(time.Hour*24*30))
+	dir := filepath.Join(t.TempDir(), "my certs")
+	require.NoError(t, os.Mkdir(dir, 0755))
+
+	leafFile := filepath.Join(dir, "leaf cert.pem")
+	require.NoError(t, os.WriteFile(leafFile, pemEncode(pki.leaf), 0644))
+
+	x509Check := New()
+	x509Check.Source = "file://" + filepath.Join(dir, "*.pem")
+	require.True(t, x509Check.Init())
+
+	mx := x509Check.Collect()
+	require.NotNil(t, mx)
+
+	id := "file_" + strings.ReplaceAll(leafFile, " ", "_")
+	assert.InDelta(t, time.Until(pki.leaf.NotAfter).Seconds(), mx[id+"_expiry"], 10)
+	chart := x509Check.Charts().Get(id + "_time_until_expiration")
+	require.NotNil(t, chart)
+	assert.Contains(t, chart.Labels, module.Label{Key: "file", Value: leafFile})
+	ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, mx)
+}
+
 func TestX509Check_Collect_ReturnsNilOnProviderError(t *testing.T) {
 	x509Check := New()
 	x509Check.prov = &mockProvider{err: true}
@@ -148,14 +339,145 @@ func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, x509Check *X509Check,
 	}
 }
 
+// lastChart returns the most recently added chart with the ID, the removed charts are kept until the job drops them.
+func lastChart(charts *module.Charts, id string) *module.Chart {
+	for i := len(*charts) - 1; i >= 0; i-- {
+		if (*charts)[i].ID == id {
+			return (*charts)[i]
+		}
+	}
+	return nil
+}
+
 type mockProvider struct {
 	certs []*x509.Certificate
 	err   bool
 }
 
-func (m mockProvider) certificates() ([]*x509.Certificate, error) {
+func (m mockProvider) certificates() ([]certSet, error) {
 	if m.err {
 		return nil, errors.New("mock certificates error")
 	}
-	return m.certs, nil
+	return []certSet{{certs: m.certs}}, nil
+}
+
+func prepareCertificate(t *testing.T) *x509.Certificate {
+	return newTestPKI(t, time.Now().Add(time.Hour*24*30)).leaf
+}
+
+type testPKI struct {
+	root, inter, leaf          *x509.Certificate
+	rootKey, interKey, leafKey *ecdsa.PrivateKey
+}
+
+func newTestPKI(t *testing.T, leafNotAfter time.Time) *testPKI {
+	var pki testPKI
+	notBefore := time.Now().Add(-time.Hour)
+
+	pki.root, pki.rootKey = newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             notBefore,
+		NotAfter:              time.Now().Add(time.Hour * 24 * 365),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	pki.inter, pki.interKey = newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		NotBefore:             notBefore,
+		NotAfter:              time.Now().Add(time.Hour * 24 * 180),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, pki.root, pki.rootKey)
+	pki.leaf, pki.leafKey = newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []stdnet.IP{stdnet.IPv4(127, 0, 0, 1)},
+		NotBefore:    notBefore,
+		NotAfter:     leafNotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, pki.inter, pki.interKey)
+
+	return &pki
+}
+
+func newTestCertificate(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func (p *testPKI) tlsCertificate(ocspStaple []byte) tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{p.leaf.Raw, p.inter.Raw},
+		PrivateKey:  p.leafKey,
+		OCSPStaple:  ocspStaple,
+	}
+}
+
+func (p *testPKI) ocspResponse(t *testing.T, status int) []byte {
+	tmpl := ocsp.Response{
+		Status:       status,
+		SerialNumber: p.leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		tmpl.RevokedAt = time.Now().Add(-time.Minute)
+	}
+	resp, err := ocsp.CreateResponse(p.inter, p.inter, tmpl, p.interKey)
+	require.NoError(t, err)
+	return resp
+}
+
+func (p *testPKI) writeRootCA(t *testing.T) string {
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(file, pemEncode(p.root), 0644))
+	return file
+}
+
+func pemEncode(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// startTLSServer starts a TLS server, the handshake is preceded by the protocol specific STARTTLS negotiation.
+func startTLSServer(t *testing.T, cert tls.Certificate, negotiate func(conn stdnet.Conn) error) string {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+				if negotiate != nil {
+					if err := negotiate(conn); err != nil {
+						return
+					}
+				}
+				_ = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
 }
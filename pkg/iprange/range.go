@@ -24,6 +24,8 @@ type Range interface {
 	Family() Family
 	Contains(ip net.IP) bool
 	Size() *big.Int
+	Start() net.IP
+	End() net.IP
 	fmt.Stringer
 }
 
@@ -65,6 +67,16 @@ func (r v4Range) Size() *big.Int {
 	return big.NewInt(v4ToInt(r.end) - v4ToInt(r.start) + 1)
 }
 
+// Start returns the first IP address of the range.
+func (r v4Range) Start() net.IP {
+	return r.start
+}
+
+// End returns the last IP address of the range.
+func (r v4Range) End() net.IP {
+	return r.end
+}
+
 type v6Range struct {
 	start net.IP
 	end   net.IP
@@ -94,6 +106,16 @@ func (r v6Range) Size() *big.Int {
 	return size
 }
 
+// Start returns the first IP address of the range.
+func (r v6Range) Start() net.IP {
+	return r.start
+}
+
+// End returns the last IP address of the range.
+func (r v6Range) End() net.IP {
+	return r.end
+}
+
 func v4ToInt(ip net.IP) int64 {
 	ip = ip.To4()
 	return int64(ip[0])<<24 | int64(ip[1])<<16 | int64(ip[2])<<8 | int64(ip[3])
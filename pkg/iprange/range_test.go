@@ -78,6 +78,14 @@ func TestV4Range_Size(t *testing.T) {
 	}
 }
 
+func TestV4Range_StartEnd(t *testing.T) {
+	r, err := ParseRange("192.0.2.0/24")
+	require.NoError(t, err)
+
+	assert.Equal(t, "192.0.2.1", r.Start().String())
+	assert.Equal(t, "192.0.2.254", r.End().String())
+}
+
 func TestV4Range_Contains(t *testing.T) {
 	tests := map[string]struct {
 		input    string
@@ -169,6 +177,14 @@ func TestV6Range_Size(t *testing.T) {
 	}
 }
 
+func TestV6Range_StartEnd(t *testing.T) {
+	r, err := ParseRange("2001:db8::-2001:db8::10")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2001:db8::", r.Start().String())
+	assert.Equal(t, "2001:db8::10", r.End().String())
+}
+
 func TestV6Range_Contains(t *testing.T) {
 	tests := map[string]struct {
 		input    string
@@ -0,0 +1,344 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+// BackfillConfig is the BackfillReader configuration.
+type BackfillConfig struct {
+	// Path is the shell file name pattern of the current log file.
+	Path string
+	// ExcludePath is the shell file name pattern, it excludes files from the current log file lookup (as in Reader).
+	ExcludePath string
+	// ExcludeRotated is the shell file name pattern, a rotated file is excluded if its path or name matches it.
+	// It is separate from ExcludePath, that commonly excludes the compressed rotated files from following.
+	ExcludeRotated string
+	// MaxAge limits files by modification time. No limit if it is zero.
+	MaxAge time.Duration
+	// MaxSize limits the total on-disk size of files, newer files are preferred. No limit if it is zero.
+	MaxSize int64
+	// SkipCurrent excludes the current log file, only rotated files are read.
+	SkipCurrent bool
+}
+
+// BackfillReader reads the rotated siblings of a log file (access.log.1, access.log.2.gz, access.log-20220101.zst, ...)
+// in chronological order, and then the log file itself up to its size at the open time.
+// It doesn't follow the log file, use Reader for that.
+type BackfillReader struct {
+	files   []string
+	limits  map[string]int64
+	next    int
+	current string
+	rc      io.ReadCloser
+	lastLn  bool
+	log     *logger.Logger
+}
+
+// OpenBackfill finds the current log file and its rotated siblings that fit the config limits.
+func OpenBackfill(config BackfillConfig, log *logger.Logger) (*BackfillReader, error) {
+	var err error
+	if config.Path, err = filepath.Abs(config.Path); err != nil {
+		return nil, err
+	}
+	if _, err = filepath.Match(config.Path, "/"); err != nil {
+		return nil, fmt.Errorf("bad path syntax: %q", config.Path)
+	}
+	if _, err = filepath.Match(config.ExcludePath, "/"); err != nil {
+		return nil, fmt.Errorf("bad exclude_path syntax: %q", config.ExcludePath)
+	}
+	if _, err = filepath.Match(config.ExcludeRotated, "/"); err != nil {
+		return nil, fmt.Errorf("bad rotated files exclude syntax: %q", config.ExcludeRotated)
+	}
+
+	current := find(config.Path, config.ExcludePath)
+	if current == "" {
+		return nil, ErrNoMatchedFile
+	}
+
+	files, err := findRotated(current, config.ExcludeRotated)
+	if err != nil {
+		return nil, err
+	}
+	if !config.SkipCurrent {
+		fi, err := os.Stat(current)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rotatedFile{path: current, stat: fi})
+	}
+	files = limitRotated(files, config.MaxAge, config.MaxSize, time.Now())
+
+	r := &BackfillReader{
+		limits: make(map[string]int64),
+		lastLn: true,
+		log:    log,
+	}
+	for _, f := range files {
+		r.files = append(r.files, f.path)
+	}
+	if !config.SkipCurrent && len(files) > 0 && files[len(files)-1].path == current {
+		r.limits[current] = files[len(files)-1].stat.Size()
+	}
+	if len(r.files) == 0 {
+		return nil, ErrNoMatchedFile
+	}
+	r.log.Debugf("backfill files: %v", r.files)
+	return r, nil
+}
+
+// Filenames returns names of the files to read in the reading order.
+func (r *BackfillReader) Filenames() []string {
+	return r.files
+}
+
+// CurrentFilename returns the name of the file being read.
+func (r *BackfillReader) CurrentFilename() string {
+	return r.current
+}
+
+func (r *BackfillReader) Read(p []byte) (n int, err error) {
+	for {
+		if r.rc == nil {
+			// files are concatenated and may be followed by other input,
+			// make sure the last line of the previous file is terminated
+			if !r.lastLn && len(p) > 0 {
+				p[0] = '\n'
+				r.lastLn = true
+				return 1, nil
+			}
+			if r.next >= len(r.files) {
+				return 0, io.EOF
+			}
+			if err = r.openNext(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err = r.rc.Read(p)
+		if n > 0 {
+			r.lastLn = p[n-1] == '\n'
+			return n, nil
+		}
+		if err == nil {
+			continue
+		}
+		if err != io.EOF {
+			return 0, fmt.Errorf("read '%s': %v", r.current, err)
+		}
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+}
+
+func (r *BackfillReader) Close() (err error) {
+	if r == nil || r.rc == nil {
+		return
+	}
+	err = r.rc.Close()
+	r.rc = nil
+	r.next = len(r.files)
+	r.lastLn = true
+	return
+}
+
+func (r *BackfillReader) openNext() error {
+	path := r.files[r.next]
+	r.next++
+	r.log.Debug("open backfill file: ", path)
+
+	rc, err := openDecompressed(path)
+	if err != nil {
+		return fmt.Errorf("open '%s': %v", path, err)
+	}
+	if limit, ok := r.limits[path]; ok {
+		rc = readCloser{Reader: io.LimitReader(rc, limit), Closer: rc}
+	}
+	r.rc = rc
+	r.current = path
+	return nil
+}
+
+// FollowAfterBackfill returns a reader that reads the backfill files first and then the follow reader.
+// The backfill reader is closed once it is read or fails, its error is returned once.
+func FollowAfterBackfill(backfill *BackfillReader, follow io.Reader) io.Reader {
+	return &backfillFollower{backfill: backfill, follow: follow}
+}
+
+type backfillFollower struct {
+	backfill *BackfillReader
+	follow   io.Reader
+}
+
+func (r *backfillFollower) Read(p []byte) (int, error) {
+	if r.backfill != nil {
+		n, err := r.backfill.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil {
+			_ = r.backfill.Close()
+			r.backfill = nil
+			if err != io.EOF {
+				return 0, err
+			}
+		}
+		if r.backfill != nil {
+			return 0, nil
+		}
+	}
+	return r.follow.Read(p)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error { return f() }
+
+func openDecompressed(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".gz":
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return readCloser{Reader: zr, Closer: closeFunc(func() error { _ = zr.Close(); return f.Close() })}, nil
+	case ".zst", ".zstd":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return readCloser{Reader: zr, Closer: closeFunc(func() error { zr.Close(); return f.Close() })}, nil
+	case ".bz2":
+		return readCloser{Reader: bzip2.NewReader(f), Closer: f}, nil
+	default:
+		return f, nil
+	}
+}
+
+type rotatedFile struct {
+	path string
+	stat os.FileInfo
+}
+
+// findRotated returns the rotated siblings of the file sorted from the oldest to the newest.
+func findRotated(current, exclude string) ([]rotatedFile, error) {
+	dir, base := filepath.Split(current)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []rotatedFile
+	for _, e := range entries {
+		name := e.Name()
+		if name == base || !isRotatedName(base, name) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if exclude != "" {
+			// rotated files are not known in advance, so the pattern is allowed to match the name only
+			if ok, _ := filepath.Match(exclude, path); ok {
+				continue
+			}
+			if ok, _ := filepath.Match(exclude, name); ok {
+				continue
+			}
+		}
+		fi, err := os.Stat(path)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, rotatedFile{path: path, stat: fi})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		ti, tj := files[i].stat.ModTime(), files[j].stat.ModTime()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return rotatedBefore(base, filepath.Base(files[i].path), filepath.Base(files[j].path))
+	})
+	return files, nil
+}
+
+// rotatedBefore reports whether the rotated file a is older than b, it is used if their modification times are equal.
+// Numeric suffixes are compared as numbers: access.log.10 is older than access.log.2,
+// but access.log-20220101 (dateext) is older than access.log-20220102.
+func rotatedBefore(base, a, b string) bool {
+	na, da := rotationNumber(base, a)
+	nb, db := rotationNumber(base, b)
+	if da == 0 || db == 0 || na == nb {
+		return a > b
+	}
+	if da >= 8 && db >= 8 {
+		return na < nb
+	}
+	return na > nb
+}
+
+// rotationNumber returns the number that follows the base name separator and the number of its digits.
+func rotationNumber(base, name string) (n uint64, digits int) {
+	suffix := name[len(base)+1:]
+	for digits < len(suffix) && suffix[digits] >= '0' && suffix[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 {
+		return 0, 0
+	}
+	n, err := strconv.ParseUint(suffix[:digits], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	return n, digits
+}
+
+func isRotatedName(base, name string) bool {
+	if !strings.HasPrefix(name, base) || len(name) == len(base) {
+		return false
+	}
+	switch name[len(base)] {
+	case '.', '-', '_':
+		return true
+	}
+	return false
+}
+
+// limitRotated drops the oldest files that exceed the age or the total size limit.
+func limitRotated(files []rotatedFile, maxAge time.Duration, maxSize int64, now time.Time) []rotatedFile {
+	var size int64
+	for i := len(files) - 1; i >= 0; i-- {
+		if maxAge > 0 && now.Sub(files[i].stat.ModTime()) > maxAge {
+			return files[i+1:]
+		}
+		size += files[i].stat.Size()
+		if maxSize > 0 && size > maxSize {
+			return files[i+1:]
+		}
+	}
+	return files
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenBackfill(t *testing.T) {
+	tests := map[string]struct {
+		config        func(dir string) BackfillConfig
+		wantFilenames []string
+		wantErr       bool
+	}{
+		"all files": {
+			config:        func(dir string) BackfillConfig { return BackfillConfig{Path: filepath.Join(dir, "access.log")} },
+			wantFilenames: []string{"access.log.3.zst", "access.log.2.gz", "access.log.1", "access.log"},
+		},
+		"skip current": {
+			config: func(dir string) BackfillConfig {
+				return BackfillConfig{Path: filepath.Join(dir, "access.log"), SkipCurrent: true}
+			},
+			wantFilenames: []string{"access.log.3.zst", "access.log.2.gz", "access.log.1"},
+		},
+		"exclude rotated": {
+			config: func(dir string) BackfillConfig {
+				return BackfillConfig{Path: filepath.Join(dir, "access.log"), ExcludeRotated: "*.zst"}
+			},
+			wantFilenames: []string{"access.log.2.gz", "access.log.1", "access.log"},
+		},
+		"max age": {
+			config: func(dir string) BackfillConfig {
+				return BackfillConfig{Path: filepath.Join(dir, "access.log"), MaxAge: time.Hour * 36}
+			},
+			wantFilenames: []string{"access.log.1", "access.log"},
+		},
+		"max size": {
+			config: func(dir string) BackfillConfig {
+				return BackfillConfig{Path: filepath.Join(dir, "access.log"), MaxSize: 40}
+			},
+			wantFilenames: []string{"access.log.1", "access.log"},
+		},
+		"no match": {
+			config:  func(dir string) BackfillConfig { return BackfillConfig{Path: filepath.Join(dir, "error.log")} },
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := prepareRotatedLogs(t)
+
+			r, err := OpenBackfill(test.config(dir), nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = r.Close() }()
+			var names []string
+			for _, name := range r.Filenames() {
+				names = append(names, filepath.Base(name))
+			}
+			assert.Equal(t, test.wantFilenames, names)
+		})
+	}
+}
+
+func TestBackfillReader_Read(t *testing.T) {
+	dir := prepareRotatedLogs(t)
+	r, err := OpenBackfill(BackfillConfig{Path: filepath.Join(dir, "access.log")}, nil)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	// the current file is read up to its size at the open time
+	appendLogs(t, filepath.Join(dir, "access.log"), 0, 1)
+
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	require.NoError(t, sc.Err())
+
+	expected := []string{
+		"line 3.1", "line 3.2",
+		"line 2.1", "line 2.2",
+		"line 1.1", "line 1.2",
+		"line 0.1", "line 0.2",
+	}
+	assert.Equal(t, expected, lines)
+
+	n, err := r.Read(make([]byte, 10))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestOpenBackfill_GlobPathExclude(t *testing.T) {
+	dir := prepareRotatedLogs(t)
+
+	config := BackfillConfig{Path: filepath.Join(dir, "access.log*"), ExcludePath: filepath.Join(dir, "access.log.*")}
+	r, err := OpenBackfill(config, nil)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	// a rotated file must not be chosen as the current one, but the rotated files are still read
+	var names []string
+	for _, name := range r.Filenames() {
+		names = append(names, filepath.Base(name))
+	}
+	assert.Equal(t, []string{"access.log.3.zst", "access.log.2.gz", "access.log.1", "access.log"}, names)
+}
+
+func TestFollowAfterBackfill(t *testing.T) {
+	dir := prepareRotatedLogs(t)
+	backfill, err := OpenBackfill(BackfillConfig{Path: filepath.Join(dir, "access.log"), SkipCurrent: true}, nil)
+	require.NoError(t, err)
+	follow := &bytes.Buffer{}
+	follow.WriteString("line 0.3\n")
+
+	var lines []string
+	sc := bufio.NewScanner(FollowAfterBackfill(backfill, follow))
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	require.NoError(t, sc.Err())
+
+	expected := []string{
+		"line 3.1", "line 3.2",
+		"line 2.1", "line 2.2",
+		"line 1.1", "line 1.2",
+		"line 0.3",
+	}
+	assert.Equal(t, expected, lines)
+	assert.Nil(t, backfill.rc, "backfill reader is not closed")
+}
+
+func TestFindRotated_SameModTime(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Now().Add(-time.Hour)
+	for _, name := range []string{"access.log", "access.log.1", "access.log.2", "access.log.10.gz",
+		"access.log-20220102", "access.log-20220101"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	files, err := findRotated(filepath.Join(dir, "access.log"), "access.log-*")
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+	assert.Equal(t, []string{"access.log.10.gz", "access.log.2", "access.log.1"}, names)
+
+	files, err = findRotated(filepath.Join(dir, "access.log"), "access.log.*")
+	require.NoError(t, err)
+	names = names[:0]
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+	assert.Equal(t, []string{"access.log-20220101", "access.log-20220102"}, names)
+}
+
+func prepareRotatedLogs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	now := time.Now()
+
+	write := func(name string, data []byte, age time.Duration) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte("line 2.1\nline 2.2\n"))
+	require.NoError(t, zw.Close())
+	gz := buf.Bytes()
+
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zst := enc.EncodeAll([]byte("line 3.1\nline 3.2\n"), nil)
+	_ = enc.Close()
+
+	write("access.log", []byte("line 0.1\nline 0.2\n"), 0)
+	// no trailing new line
+	write("access.log.1", []byte("line 1.1\nline 1.2"), time.Hour*24)
+	write("access.log.2.gz", gz, time.Hour*48)
+	write("access.log.3.zst", zst, time.Hour*72)
+	write("error.log.1", []byte("error\n"), time.Hour)
+
+	return dir
+}
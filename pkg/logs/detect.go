@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	reLTSV   = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
+	reJSON   = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
+	reLogfmt = regexp.MustCompile(`^[[:space:]]*` + logfmtPair + `([[:space:]]+` + logfmtPair + `)*[[:space:]]*$`)
+)
+
+const logfmtPair = `[a-zA-Z_][a-zA-Z0-9_.\-/@]*=("(?:[^"\\]|\\.)*"|[^[:space:]"]*)`
+
+// DetectLogType guesses the log type of the record. It returns TypeLTSV, TypeJSON, TypeLogfmt or TypeCSV.
+// CSV is the fallback, its format can't be detected in a generic way.
+func DetectLogType(record []byte) string {
+	switch {
+	case reLTSV.Match(record):
+		return TypeLTSV
+	case reJSON.Match(record):
+		return TypeJSON
+	case reLogfmt.Match(record):
+		return TypeLogfmt
+	default:
+		return TypeCSV
+	}
+}
+
+// DetectFileLogType guesses the log type of the file using its last line.
+func DetectFileLogType(filename string) (string, error) {
+	record, err := ReadLastLine(filename, 0)
+	if err != nil {
+		return "", fmt.Errorf("read last line: %v", err)
+	}
+	record = bytes.TrimRight(record, "\r\n")
+	if len(record) == 0 {
+		return "", fmt.Errorf("empty line, can't auto-detect format (%s)", filename)
+	}
+	return DetectLogType(record), nil
+}
+
+// detectReaderLogType guesses the log type of the file the reader reads.
+func detectReaderLogType(in interface{}) (string, error) {
+	f, ok := in.(interface{ CurrentFilename() string })
+	if !ok {
+		return "", errors.New("log type auto-detection requires a file reader")
+	}
+	return DetectFileLogType(f.CurrentFilename())
+}
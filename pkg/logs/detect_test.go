@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLogType(t *testing.T) {
+	tests := map[string]struct {
+		record   string
+		wantType string
+	}{
+		"ltsv":            {record: "host:127.0.0.1\tstatus:200\treqtime:0.1", wantType: TypeLTSV},
+		"json":            {record: `{"host": "127.0.0.1", "status": 200}`, wantType: TypeJSON},
+		"logfmt":          {record: `level=info msg="user logged in" user_id=42`, wantType: TypeLogfmt},
+		"logfmt one pair": {record: `status=200`, wantType: TypeLogfmt},
+		"csv":             {record: `127.0.0.1 - - [22/Mar/2009:09:30:31 +0100] "GET /?a=b HTTP/1.0" 200 8674`, wantType: TypeCSV},
+		"csv with equals": {record: `a=1 b c=2`, wantType: TypeCSV},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.wantType, DetectLogType([]byte(test.record)))
+		})
+	}
+}
+
+func TestNewParser_Auto(t *testing.T) {
+	tests := map[string]struct {
+		lastLine   string
+		wantParser Parser
+		wantErr    bool
+	}{
+		"json":       {lastLine: `{"status": 200}`, wantParser: &JSONParser{}},
+		"ltsv":       {lastLine: "status:200\treqtime:0.1", wantParser: &LTSVParser{}},
+		"logfmt":     {lastLine: `status=200 reqtime=0.1`, wantParser: &LogfmtParser{}},
+		"csv":        {lastLine: `200 0.1`, wantParser: &CSVParser{}},
+		"empty line": {lastLine: "", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "test.log")
+			data := "first line\n" + test.lastLine + "\n"
+			if test.lastLine == "" {
+				data = ""
+			}
+			require.NoError(t, os.WriteFile(filename, []byte(data), 0644))
+			r, err := Open(filename, "", nil)
+			require.NoError(t, err)
+			defer func() { _ = r.Close() }()
+
+			p, err := NewParser(ParserConfig{LogType: TypeAuto, CSV: CSVConfig{Format: "$status $reqtime"}}, r)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.IsType(t, test.wantParser, p)
+			}
+		})
+	}
+}
+
+func TestNewParser_Auto_NotFileReader(t *testing.T) {
+	_, err := NewParser(ParserConfig{LogType: TypeAuto}, strings.NewReader("status=200\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// journaldFields maps journal fields to syslog fields, other fields are assigned as is.
+var journaldFields = map[string]string{
+	"__REALTIME_TIMESTAMP": SyslogFieldTimestamp,
+	"_HOSTNAME":            SyslogFieldHostname,
+	"SYSLOG_IDENTIFIER":    SyslogFieldAppName,
+	"_PID":                 SyslogFieldProcID,
+	"MESSAGE_ID":           SyslogFieldMsgID,
+	"MESSAGE":              SyslogFieldMsg,
+	"PRIORITY":             SyslogFieldSeverity,
+	"SYSLOG_FACILITY":      SyslogFieldFacility,
+}
+
+type (
+	JournalExportConfig struct {
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	// JournalExportParser parses the journal export format (journalctl -o export).
+	// Entries are separated by an empty line, a field is either "KEY=value\n"
+	// or "KEY\n<little endian uint64 size><binary value>\n".
+	JournalExportParser struct {
+		r     *bufio.Reader
+		entry []byte
+		// fieldStart is the entry offset of the field being read, a field line may take several reads
+		fieldStart int
+		// binStart is the entry offset of the binary value being read, -1 if there is none
+		binStart int
+		mapping  map[string]string
+	}
+)
+
+func NewJournalExportParser(config JournalExportConfig, in io.Reader) (*JournalExportParser, error) {
+	p := &JournalExportParser{
+		r:        bufio.NewReader(in),
+		entry:    make([]byte, 0, 1024),
+		binStart: -1,
+		mapping:  config.Mapping,
+	}
+	return p, nil
+}
+
+// ReadLine reads and parses an entry. A partially read entry is kept until the next call.
+func (p *JournalExportParser) ReadLine(line LogLine) error {
+	if p.binStart >= 0 {
+		if err := p.readBinaryValue(); err != nil {
+			return err
+		}
+	}
+	for {
+		row, err := p.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			p.entry = append(p.entry, row...)
+			continue
+		}
+		if err != nil {
+			p.entry = append(p.entry, row...)
+			return err
+		}
+		if len(row) == 1 && len(p.entry) == p.fieldStart {
+			if len(p.entry) == 0 {
+				continue
+			}
+			err = p.Parse(p.entry, line)
+			p.entry, p.fieldStart = p.entry[:0], 0
+			return err
+		}
+
+		p.entry = append(p.entry, row...)
+		// the field is binary only if there is no '=' in the whole field line, not just in the last read chunk
+		if bytes.IndexByte(p.entry[p.fieldStart:], '=') >= 0 {
+			p.fieldStart = len(p.entry)
+			continue
+		}
+		p.binStart = len(p.entry)
+		if err := p.readBinaryValue(); err != nil {
+			return err
+		}
+	}
+}
+
+// readBinaryValue reads the size, the value and its trailing new line of the binary field.
+// A partially read value is kept in the entry, the reading is resumed on the next call.
+func (p *JournalExportParser) readBinaryValue() error {
+	for {
+		have, need := len(p.entry)-p.binStart, 8
+		if have >= 8 {
+			n := binary.LittleEndian.Uint64(p.entry[p.binStart:])
+			if n > 1<<24 {
+				p.entry, p.fieldStart, p.binStart = p.entry[:0], 0, -1
+				return &ParseError{msg: fmt.Sprintf("journal export parse: too big binary field (%d bytes)", n)}
+			}
+			need += int(n) + 1
+		}
+		if have == need {
+			p.fieldStart, p.binStart = len(p.entry), -1
+			return nil
+		}
+
+		buf := make([]byte, need-have)
+		n, err := io.ReadFull(p.r, buf)
+		p.entry = append(p.entry, buf[:n]...)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *JournalExportParser) Parse(row []byte, line LogLine) error {
+	if err := p.parse(row, line); err != nil {
+		return &ParseError{msg: fmt.Sprintf("journal export parse: %v", err), err: err}
+	}
+	return nil
+}
+
+func (p *JournalExportParser) parse(row []byte, line LogLine) error {
+	for len(row) > 0 {
+		field, rest := row, []byte(nil)
+		if i := bytes.IndexByte(row, '\n'); i >= 0 {
+			field, rest = row[:i], row[i+1:]
+		}
+
+		var name, value string
+		if eq := bytes.IndexByte(field, '='); eq >= 0 {
+			name, value = string(field[:eq]), string(field[eq+1:])
+			row = rest
+		} else {
+			if len(rest) < 8 {
+				return fmt.Errorf("field '%s': missing binary value size", field)
+			}
+			n := binary.LittleEndian.Uint64(rest[:8])
+			if uint64(len(rest)-8) < n {
+				return fmt.Errorf("field '%s': short binary value", field)
+			}
+			name, value = string(field), string(rest[8:8+n])
+			row = rest[8+n:]
+			if len(row) > 0 && row[0] == '\n' {
+				row = row[1:]
+			}
+		}
+
+		if name == "" {
+			return errors.New("empty field name")
+		}
+		if err := p.assign(line, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *JournalExportParser) assign(line LogLine, name, value string) error {
+	if v, ok := p.mapping[name]; ok {
+		return line.Assign(v, value)
+	}
+	v, ok := journaldFields[name]
+	if !ok {
+		return line.Assign(name, value)
+	}
+	switch v {
+	case SyslogFieldSeverity:
+		value = journaldSyslogName(value, syslogSeverities)
+	case SyslogFieldFacility:
+		value = journaldSyslogName(value, syslogFacilities)
+	}
+	return line.Assign(v, value)
+}
+
+func (p *JournalExportParser) Info() string {
+	return fmt.Sprintf("journal export: %q", p.mapping)
+}
+
+func journaldSyslogName(value string, names []string) string {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < len(names) {
+		return names[n]
+	}
+	return value
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalExportParser_ReadLine(t *testing.T) {
+	var in bytes.Buffer
+	in.WriteString("__REALTIME_TIMESTAMP=1666170000000000\n_HOSTNAME=host\nSYSLOG_IDENTIFIER=sshd\n_PID=42\nPRIORITY=6\nSYSLOG_FACILITY=4\n_SYSTEMD_UNIT=ssh.service\nMESSAGE=accepted\n\n")
+	in.WriteString("SYSLOG_IDENTIFIER=app\nMESSAGE\n")
+	_ = binary.Write(&in, binary.LittleEndian, uint64(11))
+	in.WriteString("line1\nline2\n\n")
+
+	p, err := NewJournalExportParser(JournalExportConfig{}, &in)
+	require.NoError(t, err)
+
+	line := newLogLine()
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{
+		"timestamp":     "1666170000000000",
+		"hostname":      "host",
+		"appname":       "sshd",
+		"procid":        "42",
+		"severity":      "info",
+		"facility":      "auth",
+		"_SYSTEMD_UNIT": "ssh.service",
+		"msg":           "accepted",
+	}, line.assigned)
+
+	line = newLogLine()
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{
+		"appname": "app",
+		"msg":     "line1\nline2",
+	}, line.assigned)
+
+	assert.Equal(t, io.EOF, p.ReadLine(newLogLine()))
+}
+
+func TestJournalExportParser_ReadLine_PartialEntry(t *testing.T) {
+	var in bytes.Buffer
+	p, err := NewJournalExportParser(JournalExportConfig{}, &in)
+	require.NoError(t, err)
+
+	in.WriteString("SYSLOG_IDENTIFIER=app\n")
+	line := newLogLine()
+	assert.Equal(t, io.EOF, p.ReadLine(line))
+	assert.Empty(t, line.assigned)
+
+	in.WriteString("MESSAGE=msg\n\n")
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{"appname": "app", "msg": "msg"}, line.assigned)
+}
+
+func TestJournalExportParser_ReadLine_PartialBinaryValue(t *testing.T) {
+	var in bytes.Buffer
+	p, err := NewJournalExportParser(JournalExportConfig{}, &in)
+	require.NoError(t, err)
+
+	in.WriteString("MESSAGE\n")
+	in.Write([]byte{11, 0, 0, 0})
+	line := newLogLine()
+	assert.Equal(t, io.EOF, p.ReadLine(line))
+
+	in.Write([]byte{0, 0, 0, 0})
+	in.WriteString("line1\nli")
+	assert.Equal(t, io.EOF, p.ReadLine(line))
+
+	in.WriteString("ne2\nSYSLOG_IDENTIFIER=app\n\n")
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{"appname": "app", "msg": "line1\nline2"}, line.assigned)
+
+	line = newLogLine()
+	in.WriteString("MESSAGE=next\n\n")
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{"msg": "next"}, line.assigned)
+}
+
+func TestJournalExportParser_ReadLine_LongTextField(t *testing.T) {
+	msg := strings.Repeat("a", 5000)
+
+	t.Run("single read", func(t *testing.T) {
+		in := strings.NewReader("MESSAGE=" + msg + "\nPRIORITY=6\n\n")
+		p, err := NewJournalExportParser(JournalExportConfig{}, in)
+		require.NoError(t, err)
+
+		line := newLogLine()
+		require.NoError(t, p.ReadLine(line))
+		assert.Equal(t, map[string]string{"msg": msg, "severity": "info"}, line.assigned)
+	})
+
+	t.Run("split across EOF", func(t *testing.T) {
+		var in bytes.Buffer
+		p, err := NewJournalExportParser(JournalExportConfig{}, &in)
+		require.NoError(t, err)
+
+		in.WriteString("MESSAGE=" + msg[:4500])
+		line := newLogLine()
+		assert.Equal(t, io.EOF, p.ReadLine(line))
+
+		in.WriteString(msg[4500:] + "\nPRIORITY=6\n\n")
+		require.NoError(t, p.ReadLine(line))
+		assert.Equal(t, map[string]string{"msg": msg, "severity": "info"}, line.assigned)
+
+		line = newLogLine()
+		in.WriteString("MESSAGE=next\n\n")
+		require.NoError(t, p.ReadLine(line))
+		assert.Equal(t, map[string]string{"msg": "next"}, line.assigned)
+	})
+}
+
+func TestJournalExportParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		config  JournalExportConfig
+		row     string
+		wantErr bool
+	}{
+		"no error":             {row: "MESSAGE=msg\nPRIORITY=3"},
+		"mapping":              {config: JournalExportConfig{Mapping: map[string]string{"MESSAGE": "message"}}, row: "MESSAGE=msg"},
+		"empty field name":     {row: "=msg", wantErr: true},
+		"short binary value":   {row: "MESSAGE\n\x10\x00\x00\x00\x00\x00\x00\x00msg\n", wantErr: true},
+		"missing binary value": {row: "MESSAGE\n", wantErr: true},
+		"error on assigning":   {row: "ERR=1", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewJournalExportParser(test.config, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.row), newLogLine())
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJournalExportParser_Info(t *testing.T) {
+	p, err := NewJournalExportParser(JournalExportConfig{}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
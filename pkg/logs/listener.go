@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const (
+	defaultListenerBufferSize = 1024 * 1024
+	// maxSyslogMessageSize is the max size of a datagram or a TCP frame.
+	maxSyslogMessageSize = 64 * 1024
+)
+
+var errMessageTooLong = fmt.Errorf("message exceeds %d bytes", maxSyslogMessageSize)
+
+type (
+	ListenerConfig struct {
+		// Network is either "udp" or "tcp".
+		Network string `yaml:"network"`
+		Address string `yaml:"address"`
+		// BufferSize is the max size of received but not yet read messages, newer messages are dropped.
+		BufferSize int `yaml:"buffer_size"`
+	}
+
+	// SyslogListener receives syslog messages over UDP (a message per datagram)
+	// or TCP (RFC6587 octet counting or newline delimited framing).
+	// It implements io.Reader, received messages are returned as lines, io.EOF is returned when there are no messages.
+	SyslogListener struct {
+		mu      sync.Mutex
+		buf     bytes.Buffer
+		maxSize int
+		dropped int64
+
+		pc    net.PacketConn
+		ln    net.Listener
+		conns map[net.Conn]struct{}
+		wg    sync.WaitGroup
+		log   *logger.Logger
+	}
+)
+
+// ListenSyslog starts listening on the config address.
+func ListenSyslog(config ListenerConfig, log *logger.Logger) (*SyslogListener, error) {
+	l := &SyslogListener{
+		maxSize: config.BufferSize,
+		conns:   make(map[net.Conn]struct{}),
+		log:     log,
+	}
+	if l.maxSize <= 0 {
+		l.maxSize = defaultListenerBufferSize
+	}
+
+	var err error
+	switch config.Network {
+	case "udp", "udp4", "udp6":
+		if l.pc, err = net.ListenPacket(config.Network, config.Address); err != nil {
+			return nil, err
+		}
+		l.wg.Add(1)
+		go l.servePacket()
+	case "tcp", "tcp4", "tcp6":
+		if l.ln, err = net.Listen(config.Network, config.Address); err != nil {
+			return nil, err
+		}
+		l.wg.Add(1)
+		go l.serveStream()
+	default:
+		return nil, fmt.Errorf("unsupported network: %q", config.Network)
+	}
+	return l, nil
+}
+
+// Addr returns the listener network address.
+func (l *SyslogListener) Addr() net.Addr {
+	if l.pc != nil {
+		return l.pc.LocalAddr()
+	}
+	return l.ln.Addr()
+}
+
+// Dropped returns the number of messages dropped because of the full buffer.
+func (l *SyslogListener) Dropped() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+
+func (l *SyslogListener) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return l.buf.Read(p)
+}
+
+func (l *SyslogListener) Close() error {
+	var err error
+	if l.pc != nil {
+		err = l.pc.Close()
+	}
+	if l.ln != nil {
+		err = l.ln.Close()
+	}
+	l.mu.Lock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	return err
+}
+
+func (l *SyslogListener) servePacket() {
+	defer l.wg.Done()
+	buf := make([]byte, maxSyslogMessageSize)
+	for {
+		n, _, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			l.log.Debugf("syslog listener: read: %v", err)
+			continue
+		}
+		l.push(buf[:n])
+	}
+}
+
+func (l *SyslogListener) serveStream() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			l.log.Debugf("syslog listener: accept: %v", err)
+			continue
+		}
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		l.wg.Add(1)
+		go l.serveConn(conn)
+	}
+}
+
+func (l *SyslogListener) serveConn(conn net.Conn) {
+	defer l.wg.Done()
+	defer func() {
+		_ = conn.Close()
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readFramedMessage(r)
+		if len(msg) > 0 {
+			l.push(msg)
+		}
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				l.log.Debugf("syslog listener: read from '%s': %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+func (l *SyslogListener) push(msg []byte) {
+	msg = bytes.TrimRight(msg, "\r\n\x00")
+	if len(msg) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buf.Len()+len(msg)+1 > l.maxSize {
+		l.dropped++
+		return
+	}
+	for _, c := range msg {
+		// a message is a line
+		if c == '\n' {
+			c = ' '
+		}
+		l.buf.WriteByte(c)
+	}
+	l.buf.WriteByte('\n')
+}
+
+// readFramedMessage reads "MSG-LEN SP SYSLOG-MSG" if the frame starts with a digit, otherwise a line.
+// The size of a frame is limited, the connection is expected to be dropped on error.
+func readFramedMessage(r *bufio.Reader) ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] < '1' || b[0] > '9' {
+		return readDelimited(r, '\n')
+	}
+
+	s, err := readDelimited(r, ' ')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(string(s[:len(s)-1]))
+	if err != nil || n > maxSyslogMessageSize {
+		return nil, fmt.Errorf("invalid frame length: %q", s)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readDelimited reads until the delimiter, it fails if there is no delimiter within maxSyslogMessageSize bytes.
+func readDelimited(r *bufio.Reader, delim byte) ([]byte, error) {
+	var msg []byte
+	for {
+		b, err := r.ReadSlice(delim)
+		if len(msg)+len(b) > maxSyslogMessageSize {
+			return nil, errMessageTooLong
+		}
+		msg = append(msg, b...)
+		if err != bufio.ErrBufferFull {
+			return msg, err
+		}
+		// the delimiter is not read yet, so the message can't fit
+		if len(msg) >= maxSyslogMessageSize {
+			return nil, errMessageTooLong
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenSyslog(t *testing.T) {
+	tests := map[string]struct {
+		config  ListenerConfig
+		wantErr bool
+	}{
+		"udp":                 {config: ListenerConfig{Network: "udp", Address: "127.0.0.1:0"}},
+		"tcp":                 {config: ListenerConfig{Network: "tcp", Address: "127.0.0.1:0"}},
+		"unsupported network": {config: ListenerConfig{Network: "unix", Address: "/tmp/socket"}, wantErr: true},
+		"bad address":         {config: ListenerConfig{Network: "tcp", Address: "127.0.0.1:-1"}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := ListenSyslog(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, l.Close())
+			}
+		})
+	}
+}
+
+func TestSyslogListener_Read_UDP(t *testing.T) {
+	l, err := ListenSyslog(ListenerConfig{Network: "udp", Address: "127.0.0.1:0"}, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, _ = conn.Write([]byte("<13>1 - host app - - - msg 1"))
+	_, _ = conn.Write([]byte("<13>1 - host app - - - multi\nline\n"))
+
+	assert.Equal(t, []string{"msg 1", "multi line"}, readSyslogMessages(t, l, 2))
+}
+
+func TestSyslogListener_Read_TCP(t *testing.T) {
+	l, err := ListenSyslog(ListenerConfig{Network: "tcp", Address: "127.0.0.1:0"}, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, _ = fmt.Fprint(conn, "<13>1 - host app - - - msg 1\n")
+	octet := "<13>1 - host app - - - msg\n2"
+	_, _ = fmt.Fprintf(conn, "%d %s", len(octet), octet)
+	_, _ = fmt.Fprint(conn, "<13>Oct 11 22:14:15 host app: msg 3\n")
+
+	assert.Equal(t, []string{"msg 1", "msg 2", "msg 3"}, readSyslogMessages(t, l, 3))
+}
+
+func TestSyslogListener_TCP_DropsConnectionOnTooLongMessage(t *testing.T) {
+	l, err := ListenSyslog(ListenerConfig{Network: "tcp", Address: "127.0.0.1:0"}, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	// no delimiter
+	_, _ = conn.Write(bytes.Repeat([]byte("a"), maxSyslogMessageSize+1))
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	// EOF or reset, the unread data is discarded on close
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	var netErr net.Error
+	assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), "connection is not dropped")
+}
+
+func TestReadFramedMessage(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		wantMsg string
+		wantErr bool
+	}{
+		"line":                   {input: "<13>msg\n", wantMsg: "<13>msg\n"},
+		"octet counting":         {input: "8 <13>msg\n", wantMsg: "<13>msg\n"},
+		"too long line":          {input: strings.Repeat("a", maxSyslogMessageSize+1) + "\n", wantErr: true},
+		"too long frame length":  {input: strings.Repeat("1", maxSyslogMessageSize+1) + " msg", wantErr: true},
+		"too big frame length":   {input: "100000 msg", wantErr: true},
+		"line at the size limit": {input: strings.Repeat("a", maxSyslogMessageSize-1) + "\n", wantMsg: strings.Repeat("a", maxSyslogMessageSize-1) + "\n"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg, err := readFramedMessage(bufio.NewReader(strings.NewReader(test.input)))
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMsg, string(msg))
+		})
+	}
+}
+
+func TestSyslogListener_Read_BufferFull(t *testing.T) {
+	l, err := ListenSyslog(ListenerConfig{Network: "udp", Address: "127.0.0.1:0", BufferSize: 50}, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, _ = conn.Write([]byte("<13>1 - host app - - - msg 1"))
+	_, _ = conn.Write([]byte("<13>1 - host app - - - msg 2"))
+
+	assert.Eventually(t, func() bool { return l.Dropped() == 1 }, time.Second, time.Millisecond*10)
+	assert.Equal(t, []string{"msg 1"}, readSyslogMessages(t, l, 2))
+}
+
+func readSyslogMessages(t *testing.T, r io.Reader, num int) []string {
+	t.Helper()
+	p, err := NewSyslogParser(SyslogConfig{}, r)
+	require.NoError(t, err)
+
+	var msgs []string
+	deadline := time.Now().Add(time.Second * 2)
+	for len(msgs) < num && time.Now().Before(deadline) {
+		line := newLogLine()
+		if err := p.ReadLine(line); err != nil {
+			require.Equal(t, io.EOF, err)
+			time.Sleep(time.Millisecond * 10)
+			continue
+		}
+		msgs = append(msgs, line.assigned["msg"])
+	}
+	return msgs
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type (
+	LogfmtConfig struct {
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	// LogfmtParser parses logfmt lines: `key=value key2="quoted \"value\"" key3`.
+	// A key without a value is assigned an empty string.
+	LogfmtParser struct {
+		r       *bufio.Reader
+		mapping map[string]string
+	}
+)
+
+func NewLogfmtParser(config LogfmtConfig, in io.Reader) (*LogfmtParser, error) {
+	p := &LogfmtParser{
+		r:       bufio.NewReader(in),
+		mapping: config.Mapping,
+	}
+	return p, nil
+}
+
+func (p *LogfmtParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+func (p *LogfmtParser) Parse(row []byte, line LogLine) error {
+	if err := p.parse(row, line); err != nil {
+		return &ParseError{msg: fmt.Sprintf("logfmt parse: %v", err), err: err}
+	}
+	return nil
+}
+
+func (p *LogfmtParser) parse(row []byte, line LogLine) error {
+	for {
+		row = bytes.TrimLeft(row, " \t\r")
+		if len(row) == 0 {
+			return nil
+		}
+
+		end := bytes.IndexAny(row, "= \t\r")
+		if end < 0 {
+			end = len(row)
+		}
+		if end == 0 {
+			return errors.New("empty key")
+		}
+		key := string(row[:end])
+		row = row[end:]
+
+		var value string
+		if len(row) > 0 && row[0] == '=' {
+			row = row[1:]
+			if len(row) > 0 && row[0] == '"' {
+				v, n, err := unquoteLogfmtValue(row[1:])
+				if err != nil {
+					return fmt.Errorf("key '%s': %v", key, err)
+				}
+				value, row = v, row[1+n:]
+			} else {
+				end := bytes.IndexAny(row, " \t\r")
+				if end < 0 {
+					end = len(row)
+				}
+				value, row = string(row[:end]), row[end:]
+			}
+		}
+
+		if v, ok := p.mapping[key]; ok {
+			key = v
+		}
+		if err := line.Assign(key, value); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *LogfmtParser) Info() string {
+	return fmt.Sprintf("logfmt: %q", p.mapping)
+}
+
+// unquoteLogfmtValue returns the value and the number of consumed bytes including the closing quote.
+func unquoteLogfmtValue(b []byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(b) {
+				break
+			}
+			i++
+			switch b[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(b[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated quoted value")
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogfmtConfig = LogfmtConfig{
+	Mapping: map[string]string{"KEY": "key"},
+}
+
+func TestNewLogfmtParser(t *testing.T) {
+	p, err := NewLogfmtParser(testLogfmtConfig, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, testLogfmtConfig.Mapping, p.mapping)
+}
+
+func TestLogfmtParser_ReadLine(t *testing.T) {
+	tests := map[string]struct {
+		row          string
+		wantErr      bool
+		wantParseErr bool
+	}{
+		"no error":             {row: `a=1 b="2 3" KEY=4`},
+		"error on parsing":     {row: `a="1`, wantErr: true, wantParseErr: true},
+		"error on assigning":   {row: `a=1 ERR=2`, wantErr: true, wantParseErr: true},
+		"error on reading EOF": {row: "", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var line logLine
+			p, err := NewLogfmtParser(testLogfmtConfig, strings.NewReader(test.row))
+			require.NoError(t, err)
+
+			err = p.ReadLine(&line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, test.wantParseErr, IsParseError(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		row          string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"simple": {
+			row:          `level=info msg=started duration=1.5s`,
+			wantAssigned: map[string]string{"level": "info", "msg": "started", "duration": "1.5s"},
+		},
+		"quoted and escaped": {
+			row:          `msg="request \"GET /\" done" path="/a b" nl="a\nb"`,
+			wantAssigned: map[string]string{"msg": `request "GET /" done`, "path": "/a b", "nl": "a\nb"},
+		},
+		"mapping, empty and bare keys": {
+			row:          `KEY=1 empty= bare  other="" `,
+			wantAssigned: map[string]string{"key": "1", "empty": "", "bare": "", "other": ""},
+		},
+		"empty key": {
+			row:     `=1`,
+			wantErr: true,
+		},
+		"unterminated quote": {
+			row:     `a=1 b="2`,
+			wantErr: true,
+		},
+		"error on assigning": {
+			row:     `ERR=1`,
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewLogfmtParser(testLogfmtConfig, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.row), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_Info(t *testing.T) {
+	p, err := NewLogfmtParser(testLogfmtConfig, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
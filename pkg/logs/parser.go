@@ -37,6 +37,11 @@ const (
 	TypeLTSV   = "ltsv"
 	TypeRegExp = "regexp"
 	TypeJSON   = "json"
+	TypeLogfmt = "logfmt"
+	TypeAuto   = "auto"
+
+	TypeSyslog        = "syslog"
+	TypeJournalExport = "journal_export"
 )
 
 type ParserConfig struct {
@@ -45,6 +50,10 @@ type ParserConfig struct {
 	LTSV    LTSVConfig   `yaml:"ltsv_config"`
 	RegExp  RegExpConfig `yaml:"regexp_config"`
 	JSON    JSONConfig   `yaml:"json_config"`
+	Logfmt  LogfmtConfig `yaml:"logfmt_config"`
+
+	Syslog        SyslogConfig        `yaml:"syslog_config"`
+	JournalExport JournalExportConfig `yaml:"journal_export_config"`
 }
 
 func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
@@ -57,6 +66,19 @@ func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
 		return NewRegExpParser(config.RegExp, in)
 	case TypeJSON:
 		return NewJSONParser(config.JSON, in)
+	case TypeLogfmt:
+		return NewLogfmtParser(config.Logfmt, in)
+	case TypeSyslog:
+		return NewSyslogParser(config.Syslog, in)
+	case TypeJournalExport:
+		return NewJournalExportParser(config.JournalExport, in)
+	case TypeAuto:
+		typ, err := detectReaderLogType(in)
+		if err != nil {
+			return nil, err
+		}
+		config.LogType = typ
+		return NewParser(config, in)
 	default:
 		return nil, fmt.Errorf("invalid type: %q", config.LogType)
 	}
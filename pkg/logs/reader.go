@@ -3,6 +3,7 @@
 package logs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -21,15 +22,28 @@ var (
 	ErrNoMatchedFile = errors.New("no matched files")
 )
 
-// Reader is a log rotate aware Reader
-// TODO: better reopen algorithm
-// TODO: handle truncate
+// ReaderConfig is the Reader configuration.
+type ReaderConfig struct {
+	// Path is the shell file name pattern.
+	Path string
+	// ExcludePath is the shell file name pattern.
+	ExcludePath string
+	// FollowAll enables following all the files matching Path, not only the last one.
+	FollowAll bool
+	// StateDir is the directory to persist per file offsets in. Offsets are not persisted if it is empty.
+	StateDir string
+}
+
+// Reader is a log rotate and truncate aware Reader.
+// It returns only complete lines (unless a line is longer than the read buffer),
+// that allows to switch between files and to persist offsets without breaking lines.
 type Reader struct {
-	file          *os.File
-	path          string
-	excludePath   string
+	config        ReaderConfig
+	files         []*logFile
+	current       int
 	eofCounter    int
 	continuousEOF int
+	state         *readerState
 	log           *logger.Logger
 }
 
@@ -37,20 +51,31 @@ type Reader struct {
 // path: the shell file name pattern
 // excludePath: the shell file name pattern
 func Open(path string, excludePath string, log *logger.Logger) (*Reader, error) {
+	return OpenWithConfig(ReaderConfig{Path: path, ExcludePath: excludePath}, log)
+}
+
+// OpenWithConfig opens files matching the config path.
+// A file is positioned at its persisted offset if there is one, otherwise at the end of the file.
+func OpenWithConfig(config ReaderConfig, log *logger.Logger) (*Reader, error) {
 	var err error
-	if path, err = filepath.Abs(path); err != nil {
+	if config.Path, err = filepath.Abs(config.Path); err != nil {
 		return nil, err
 	}
-	if _, err = filepath.Match(path, "/"); err != nil {
-		return nil, fmt.Errorf("bad path syntax: %q", path)
+	if _, err = filepath.Match(config.Path, "/"); err != nil {
+		return nil, fmt.Errorf("bad path syntax: %q", config.Path)
 	}
-	if _, err = filepath.Match(excludePath, "/"); err != nil {
-		return nil, fmt.Errorf("bad exclude_path syntax: %q", path)
+	if _, err = filepath.Match(config.ExcludePath, "/"); err != nil {
+		return nil, fmt.Errorf("bad exclude_path syntax: %q", config.ExcludePath)
 	}
 	r := &Reader{
-		path:        path,
-		excludePath: excludePath,
-		log:         log,
+		config: config,
+		log:    log,
+	}
+	if config.StateDir != "" {
+		r.state = newReaderState(config.StateDir, config.Path, config.ExcludePath)
+		if err = r.state.load(); err != nil {
+			r.log.Warningf("load log reader state from '%s': %v", r.state.filename, err)
+		}
 	}
 
 	if err = r.open(); err != nil {
@@ -61,48 +86,116 @@ func Open(path string, excludePath string, log *logger.Logger) (*Reader, error)
 
 // CurrentFilename get current opened file name
 func (r *Reader) CurrentFilename() string {
-	return r.file.Name()
+	if len(r.files) == 0 {
+		return ""
+	}
+	return r.files[r.current].Name()
+}
+
+// Filenames returns names of all opened files.
+func (r *Reader) Filenames() []string {
+	names := make([]string, 0, len(r.files))
+	for _, f := range r.files {
+		names = append(names, f.Name())
+	}
+	return names
 }
 
 func (r *Reader) open() error {
-	path := r.findFile()
-	if path == "" {
-		r.log.Debugf("couldn't find log file, used path: '%s', exclude_path: '%s'", r.path, r.excludePath)
+	paths := r.findFiles()
+	if len(paths) == 0 {
+		r.log.Debugf("couldn't find log file, used path: '%s', exclude_path: '%s'", r.config.Path, r.config.ExcludePath)
 		return ErrNoMatchedFile
 	}
+
+	files := make([]*logFile, 0, len(paths))
+	for _, path := range paths {
+		f := r.lookupFile(path)
+		if f == nil {
+			var err error
+			if f, err = r.openFile(path); err != nil {
+				closeFiles(files)
+				return err
+			}
+		}
+		files = append(files, f)
+	}
+	// close files that no longer match the path
+	closeFiles(r.files)
+	r.files = files
+	r.current = 0
+	return nil
+}
+
+func (r *Reader) openFile(path string) (*logFile, error) {
 	r.log.Debug("open log file: ", path)
 	file, err := os.Open(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	stat, err := file.Stat()
 	if err != nil {
-		return err
+		_ = file.Close()
+		return nil, err
 	}
-	if _, err = file.Seek(stat.Size(), io.SeekStart); err != nil {
-		return err
+
+	f := &logFile{File: file, inode: fileInode(stat), offset: stat.Size()}
+	if cp, ok := r.state.lookup(path); ok && cp.Inode == f.inode && cp.Offset <= stat.Size() {
+		r.log.Debugf("restore log file '%s' offset %d (size %d)", path, cp.Offset, stat.Size())
+		f.offset = cp.Offset
+	}
+	if _, err = file.Seek(f.offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// lookupFile returns already opened file if it is still at the path.
+func (r *Reader) lookupFile(path string) *logFile {
+	for i, f := range r.files {
+		if f == nil || f.Name() != path {
+			continue
+		}
+		r.files[i] = nil
+		fi, err := os.Stat(path)
+		if err != nil || fileInode(fi) != f.inode {
+			r.log.Debug("log file was rotated: ", path)
+			_ = f.Close()
+			return nil
+		}
+		return f
 	}
-	r.file = file
 	return nil
 }
 
 func (r *Reader) Read(p []byte) (n int, err error) {
-	n, err = r.file.Read(p)
-	if err != nil {
-		switch err {
-		case io.EOF:
-			err = r.handleEOFErr()
-		case os.ErrInvalid: // r.file is nil after Close
-			err = r.handleInvalidArgErr()
+	if len(r.files) == 0 {
+		return 0, r.handleInvalidArgErr()
+	}
+	for i := 0; i < len(r.files); i++ {
+		f := r.files[r.current]
+		n, err = f.readLines(p)
+		if n > 0 {
+			r.continuousEOF = 0
+			return n, nil
 		}
-		return
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+		if f.partial {
+			// in the middle of a too long line, wait for the rest of it
+			break
+		}
+		r.current = (r.current + 1) % len(r.files)
 	}
-	r.continuousEOF = 0
-	return
+	return 0, r.handleEOFErr()
 }
 
 func (r *Reader) handleEOFErr() (err error) {
 	err = io.EOF
+	r.checkTruncated()
+	r.saveState(false)
 	r.eofCounter++
 	r.continuousEOF++
 	if r.eofCounter < maxEOF || r.continuousEOF < 2 {
@@ -122,46 +215,151 @@ func (r *Reader) handleInvalidArgErr() (err error) {
 	return err
 }
 
+func (r *Reader) checkTruncated() {
+	for _, f := range r.files {
+		stat, err := f.Stat()
+		if err != nil || stat.Size() >= f.offset {
+			continue
+		}
+		r.log.Debugf("log file '%s' was truncated (size %d, offset %d)", f.Name(), stat.Size(), f.offset)
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			continue
+		}
+		f.offset = 0
+		f.partial = false
+	}
+}
+
 func (r *Reader) Close() (err error) {
-	if r == nil || r.file == nil {
+	if r == nil || len(r.files) == 0 {
 		return
 	}
-	r.log.Debug("close log file: ", r.file.Name())
-	err = r.file.Close()
-	r.file = nil
+	r.saveState(true)
+	for _, f := range r.files {
+		r.log.Debug("close log file: ", f.Name())
+		if v := f.Close(); v != nil {
+			err = v
+		}
+	}
+	r.files = nil
+	r.current = 0
 	r.eofCounter = 0
 	return
 }
 
 func (r *Reader) reopen() error {
-	r.log.Debugf("reopen, look for: %s", r.path)
-	_ = r.Close()
-	return r.open()
+	r.log.Debugf("reopen, look for: %s", r.config.Path)
+	r.saveState(true)
+	err := r.open()
+	if err != nil {
+		closeFiles(r.files)
+		r.files = nil
+	}
+	r.current = 0
+	r.eofCounter = 0
+	return err
+}
+
+func (r *Reader) saveState(force bool) {
+	if r.state == nil {
+		return
+	}
+	r.state.reset()
+	for _, f := range r.files {
+		r.state.update(f.Name(), f.inode, f.offset)
+	}
+	if err := r.state.save(force); err != nil {
+		r.log.Warningf("save log reader state to '%s': %v", r.state.filename, err)
+	}
+}
+
+func (r *Reader) findFiles() []string {
+	if r.config.FollowAll {
+		return findAll(r.config.Path, r.config.ExcludePath)
+	}
+	if file := find(r.config.Path, r.config.ExcludePath); file != "" {
+		return []string{file}
+	}
+	return nil
 }
 
-func (r *Reader) findFile() string {
-	return find(r.path, r.excludePath)
+type logFile struct {
+	*os.File
+	inode   uint64
+	offset  int64
+	partial bool // the last returned chunk ended in the middle of a line
+}
+
+// readLines reads only complete lines, the incomplete tail is left unread.
+// If there is no line end in the whole buffer the chunk is returned as is.
+func (f *logFile) readLines(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	if i := bytes.LastIndexByte(p[:n], '\n'); i >= 0 {
+		if tail := n - (i + 1); tail > 0 {
+			if _, err := f.Seek(int64(-tail), io.SeekCurrent); err != nil {
+				return 0, err
+			}
+			n -= tail
+		}
+		f.offset += int64(n)
+		f.partial = false
+		return n, nil
+	}
+
+	if f.partial || n == len(p) {
+		f.offset += int64(n)
+		f.partial = true
+		return n, nil
+	}
+
+	if _, err := f.Seek(int64(-n), io.SeekCurrent); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+func closeFiles(files []*logFile) {
+	for _, f := range files {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
 }
 
 func find(path, exclude string) string {
 	return finder{}.find(path, exclude)
 }
 
-// TODO: tests
+func findAll(path, exclude string) []string {
+	return finder{}.findAll(path, exclude)
+}
+
 type finder struct{}
 
 func (f finder) find(path, exclude string) string {
-	files, _ := filepath.Glob(path)
+	files := f.findAll(path, exclude)
 	if len(files) == 0 {
 		return ""
 	}
+	return files[len(files)-1]
+}
+
+func (f finder) findAll(path, exclude string) []string {
+	files, _ := filepath.Glob(path)
+	if len(files) == 0 {
+		return nil
+	}
 
 	files = f.filter(files, exclude)
 	if len(files) == 0 {
-		return ""
+		return nil
 	}
 
-	return f.findLastFile(files)
+	return f.regularFiles(files)
 }
 
 func (f finder) filter(files []string, exclude string) []string {
@@ -179,15 +377,15 @@ func (f finder) filter(files []string, exclude string) []string {
 	return fs
 }
 
-// TODO: the logic is probably wrong
-func (f finder) findLastFile(files []string) string {
+func (f finder) regularFiles(files []string) []string {
 	sort.Strings(files)
-	for i := len(files) - 1; i >= 0; i-- {
-		stat, err := os.Stat(files[i])
+	fs := files[:0]
+	for _, file := range files {
+		stat, err := os.Stat(file)
 		if err != nil || !stat.Mode().IsRegular() {
 			continue
 		}
-		return files[i]
+		fs = append(fs, file)
 	}
-	return ""
+	return fs
 }
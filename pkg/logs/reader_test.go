@@ -4,10 +4,12 @@ package logs
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -83,12 +85,189 @@ func TestReader_Read_HandleFileRotationWithDelay(t *testing.T) {
 	assert.Equal(t, numLogs, n)
 }
 
+func TestReader_Read_HandleFileTruncate(t *testing.T) {
+	reader, teardown := prepareTestReader(t)
+	defer teardown()
+
+	r := testReader{bufio.NewReader(reader)}
+	filename := reader.CurrentFilename()
+	numLogs := 5
+	appendLogs(t, filename, 0, numLogs)
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+
+	require.NoError(t, os.Truncate(filename, 0))
+	appendLogs(t, filename, 0, numLogs-2)
+
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs-2, n)
+}
+
+func TestReader_Read_PartialLine(t *testing.T) {
+	reader, teardown := prepareTestReader(t)
+	defer teardown()
+
+	r := testReader{bufio.NewReader(reader)}
+	filename := reader.CurrentFilename()
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND, os.ModeAppend)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	_, err = file.WriteString("line 1\nline")
+	require.NoError(t, err)
+	line, err := r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "line 1\n", line)
+	_, err = r.ReadString('\n')
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, int64(len("line 1\n")), reader.files[0].offset)
+
+	_, err = file.WriteString(" 2\n")
+	require.NoError(t, err)
+	line, err = r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "line 2\n", line)
+}
+
+func TestReader_Read_FollowAll(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		filepath.Join(dir, "1.log"),
+		filepath.Join(dir, "2.log"),
+		filepath.Join(dir, "3.log"),
+	}
+	for _, name := range files {
+		require.NoError(t, os.WriteFile(name, nil, 0644))
+	}
+
+	reader, err := OpenWithConfig(ReaderConfig{Path: filepath.Join(dir, "*.log"), FollowAll: true}, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	assert.Equal(t, files, reader.Filenames())
+
+	r := testReader{bufio.NewReader(reader)}
+	numLogs := 5
+	for _, name := range files {
+		appendLogs(t, name, 0, numLogs)
+	}
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs*len(files), n)
+
+	newFile := filepath.Join(dir, "4.log")
+	require.NoError(t, os.WriteFile(newFile, nil, 0644))
+	_, _ = r.readUntilEOFTimes(maxEOF)
+	assert.Equal(t, append(files, newFile), reader.Filenames())
+}
+
+func TestReader_Read_RestoreOffsets(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	require.NoError(t, os.WriteFile(filename, nil, 0644))
+	cfg := ReaderConfig{Path: filename, StateDir: filepath.Join(dir, "state")}
+
+	reader, err := OpenWithConfig(cfg, nil)
+	require.NoError(t, err)
+	numLogs := 5
+	appendLogs(t, filename, 0, numLogs)
+	r := testReader{bufio.NewReader(reader)}
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+	require.NoError(t, reader.Close())
+
+	appendLogs(t, filename, 0, numLogs)
+	reader, err = OpenWithConfig(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	r = testReader{bufio.NewReader(reader)}
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+}
+
+func TestReader_Read_RestoreOffsets_FileRotated(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	require.NoError(t, os.WriteFile(filename, nil, 0644))
+	cfg := ReaderConfig{Path: filename, StateDir: filepath.Join(dir, "state")}
+
+	reader, err := OpenWithConfig(cfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, os.WriteFile(filename, nil, 0644))
+	appendLogs(t, filename, 0, 5)
+	reader, err = OpenWithConfig(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	r := testReader{bufio.NewReader(reader)}
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestReader_Read_ThrottlesStateSave(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	require.NoError(t, os.WriteFile(filename, nil, 0644))
+	cfg := ReaderConfig{Path: filename, StateDir: filepath.Join(dir, "state")}
+
+	reader, err := OpenWithConfig(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	now := time.Now()
+	reader.state.now = func() time.Time { return now }
+
+	savedOffset := func() int64 {
+		var cps map[string]checkpoint
+		bs, err := os.ReadFile(reader.state.filename)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bs, &cps))
+		return cps[filename].Offset
+	}
+	r := testReader{bufio.NewReader(reader)}
+
+	appendLogs(t, filename, 0, 1)
+	_, _ = r.readUntilEOF()
+	first := savedOffset()
+	assert.NotZero(t, first)
+
+	appendLogs(t, filename, 0, 1)
+	_, _ = r.readUntilEOF()
+	assert.Equal(t, first, savedOffset(), "saved before the interval has passed")
+
+	now = now.Add(stateSaveInterval)
+	_, _ = r.readUntilEOF()
+	second := savedOffset()
+	assert.Greater(t, second, first)
+
+	appendLogs(t, filename, 0, 1)
+	_, _ = r.readUntilEOF()
+	require.NoError(t, reader.Close())
+	assert.Greater(t, savedOffset(), second, "not saved on close")
+}
+
+func TestNewReaderState_GlobPath(t *testing.T) {
+	state := newReaderState("/var/lib/netdata/go.d-logs", "/var/log/nginx/*access*.log", "")
+
+	assert.NotContains(t, filepath.Base(state.filename), "*")
+	assert.True(t, strings.HasPrefix(filepath.Base(state.filename), "_access_.log-"))
+}
+
 func TestReader_Close(t *testing.T) {
 	reader, teardown := prepareTestReader(t)
 	defer teardown()
 
 	assert.NoError(t, reader.Close())
-	assert.Nil(t, reader.file)
+	assert.Nil(t, reader.files)
 }
 
 func TestReader_Close_NilFile(t *testing.T) {
@@ -156,7 +335,7 @@ func TestOpen(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, r.file)
+				assert.Len(t, r.files, 1)
 				_ = r.Close()
 			}
 		})
@@ -167,7 +346,7 @@ func TestReader_CurrentFilename(t *testing.T) {
 	reader, teardown := prepareTestReader(t)
 	defer teardown()
 
-	assert.Equal(t, reader.file.Name(), reader.CurrentFilename())
+	assert.Equal(t, reader.files[0].Name(), reader.CurrentFilename())
 }
 
 type testReader struct {
@@ -212,11 +391,13 @@ func prepareTestReader(t *testing.T) (reader *Reader, teardown func()) {
 
 	teardown = func() {
 		_ = os.Remove(filename)
-		_ = reader.file.Close()
+		closeFiles(reader.files)
 	}
+	stat, err := f.Stat()
+	require.NoError(t, err)
 	reader = &Reader{
-		file: f,
-		path: filename,
+		config: ReaderConfig{Path: filename},
+		files:  []*logFile{{File: f, inode: fileInode(stat)}},
 	}
 	return reader, teardown
 }
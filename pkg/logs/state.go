@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stateSaveInterval limits how often the offsets are written while reading, they are always written on close.
+const stateSaveInterval = time.Second * 10
+
+// DefaultStateDir returns the directory to persist the Reader offsets in.
+// It is empty if the netdata lib directory is unknown.
+func DefaultStateDir() string {
+	dir := os.Getenv("NETDATA_LIB_DIR")
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "go.d-logs")
+}
+
+type (
+	// checkpoint is a file position the Reader has read up to.
+	checkpoint struct {
+		Inode  uint64 `json:"inode"`
+		Offset int64  `json:"offset"`
+	}
+
+	readerState struct {
+		filename    string
+		checkpoints map[string]checkpoint
+		saved       map[string]checkpoint
+		savedAt     time.Time
+		now         func() time.Time
+	}
+)
+
+func newReaderState(dir, path, excludePath string) *readerState {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(path + "|" + excludePath))
+	return &readerState{
+		filename:    filepath.Join(dir, fmt.Sprintf("%s-%x.json", stateFileBase(path), h.Sum64())),
+		checkpoints: make(map[string]checkpoint),
+		now:         time.Now,
+	}
+}
+
+// stateFileBase returns the path base name safe to use in a file name, glob meta characters are replaced.
+func stateFileBase(path string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, filepath.Base(path))
+}
+
+func (s *readerState) lookup(path string) (checkpoint, bool) {
+	if s == nil {
+		return checkpoint{}, false
+	}
+	cp, ok := s.checkpoints[path]
+	return cp, ok
+}
+
+func (s *readerState) load() error {
+	bs, err := os.ReadFile(s.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(bs, &s.checkpoints); err != nil {
+		return err
+	}
+	s.saved = copyCheckpoints(s.checkpoints)
+	return nil
+}
+
+// reset drops checkpoints of the files that are no longer followed.
+func (s *readerState) reset() {
+	s.checkpoints = make(map[string]checkpoint)
+}
+
+func (s *readerState) update(path string, inode uint64, offset int64) {
+	s.checkpoints[path] = checkpoint{Inode: inode, Offset: offset}
+}
+
+// save writes the changed checkpoints. Unless forced, they are written at most once per stateSaveInterval.
+func (s *readerState) save(force bool) error {
+	if equalCheckpoints(s.checkpoints, s.saved) {
+		return nil
+	}
+	now := s.now()
+	if !force && now.Sub(s.savedAt) < stateSaveInterval {
+		return nil
+	}
+	bs, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.filename), 0755); err != nil {
+		return err
+	}
+	tmp := s.filename + ".tmp"
+	if err := os.WriteFile(tmp, bs, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.filename); err != nil {
+		return err
+	}
+	s.saved = copyCheckpoints(s.checkpoints)
+	s.savedAt = now
+	return nil
+}
+
+func copyCheckpoints(src map[string]checkpoint) map[string]checkpoint {
+	dst := make(map[string]checkpoint, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func equalCheckpoints(a, b map[string]checkpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !unix && !windows
+// +build !unix,!windows
+
+package logs
+
+import "os"
+
+func fileInode(_ os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import "os"
+
+// fileInode returns 0, the file index is not a part of os.FileInfo on Windows.
+// Rotation is detected by the file size decrease only.
+func fileInode(_ os.FileInfo) uint64 {
+	return 0
+}
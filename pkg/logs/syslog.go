@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	SyslogFormatAuto    = "auto"
+	SyslogFormatRFC3164 = "rfc3164"
+	SyslogFormatRFC5424 = "rfc5424"
+)
+
+// Syslog fields assigned to LogLine. Structured data params are assigned as "sd.<sd-id>.<param-name>".
+const (
+	SyslogFieldFacility  = "facility"
+	SyslogFieldSeverity  = "severity"
+	SyslogFieldVersion   = "version"
+	SyslogFieldTimestamp = "timestamp"
+	SyslogFieldHostname  = "hostname"
+	SyslogFieldAppName   = "appname"
+	SyslogFieldProcID    = "procid"
+	SyslogFieldMsgID     = "msgid"
+	SyslogFieldMsg       = "msg"
+	SyslogFieldSDPrefix  = "sd."
+)
+
+var (
+	syslogFacilities = []string{
+		"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+		"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+		"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+	}
+	syslogSeverities = []string{
+		"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+	}
+)
+
+type (
+	SyslogConfig struct {
+		Format  string            `yaml:"format"`
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	// SyslogParser parses RFC3164 (BSD) and RFC5424 syslog messages.
+	// PRI part is optional to support files written by syslog daemons.
+	SyslogParser struct {
+		r       *bufio.Reader
+		format  string
+		mapping map[string]string
+	}
+)
+
+func NewSyslogParser(config SyslogConfig, in io.Reader) (*SyslogParser, error) {
+	format := config.Format
+	switch format {
+	case "":
+		format = SyslogFormatAuto
+	case SyslogFormatAuto, SyslogFormatRFC3164, SyslogFormatRFC5424:
+	default:
+		return nil, fmt.Errorf("invalid syslog format: %q", format)
+	}
+	p := &SyslogParser{
+		r:       bufio.NewReader(in),
+		format:  format,
+		mapping: config.Mapping,
+	}
+	return p, nil
+}
+
+func (p *SyslogParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+func (p *SyslogParser) Parse(row []byte, line LogLine) error {
+	row = bytes.TrimRight(row, "\r")
+	rest, err := p.parsePRI(row, line)
+	if err == nil {
+		format := p.format
+		if format == SyslogFormatAuto {
+			format = detectSyslogFormat(rest)
+		}
+		if format == SyslogFormatRFC5424 {
+			err = p.parseRFC5424(rest, line)
+		} else {
+			err = p.parseRFC3164(rest, line)
+		}
+	}
+	if err != nil {
+		return &ParseError{msg: fmt.Sprintf("syslog parse: %v", err), err: err}
+	}
+	return nil
+}
+
+func (p *SyslogParser) Info() string {
+	return fmt.Sprintf("syslog: %s, %q", p.format, p.mapping)
+}
+
+func (p *SyslogParser) assign(line LogLine, name, value string) error {
+	if v, ok := p.mapping[name]; ok {
+		name = v
+	}
+	return line.Assign(name, value)
+}
+
+func (p *SyslogParser) parsePRI(row []byte, line LogLine) ([]byte, error) {
+	if len(row) == 0 || row[0] != '<' {
+		return row, nil
+	}
+	end := bytes.IndexByte(row, '>')
+	if end < 2 || end > 4 {
+		return nil, errors.New("invalid PRI")
+	}
+	// PRI is 1-3 digits, Atoi accepts a sign
+	for _, c := range row[1:end] {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid PRI: %q", row[1:end])
+		}
+	}
+	pri, err := strconv.Atoi(string(row[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, fmt.Errorf("invalid PRI: %q", row[1:end])
+	}
+	if err := p.assign(line, SyslogFieldFacility, syslogFacilities[pri/8]); err != nil {
+		return nil, err
+	}
+	if err := p.assign(line, SyslogFieldSeverity, syslogSeverities[pri%8]); err != nil {
+		return nil, err
+	}
+	return row[end+1:], nil
+}
+
+// parseRFC5424 parses "VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]".
+func (p *SyslogParser) parseRFC5424(row []byte, line LogLine) error {
+	fields := []string{
+		SyslogFieldVersion,
+		SyslogFieldTimestamp,
+		SyslogFieldHostname,
+		SyslogFieldAppName,
+		SyslogFieldProcID,
+		SyslogFieldMsgID,
+	}
+	for _, name := range fields {
+		var value []byte
+		if value, row = nextField(row); value == nil {
+			return fmt.Errorf("missing %s", name)
+		}
+		if len(value) == 1 && value[0] == '-' {
+			continue
+		}
+		if err := p.assign(line, name, string(value)); err != nil {
+			return err
+		}
+	}
+
+	if len(row) == 0 {
+		return errors.New("missing structured data")
+	}
+	if row[0] == '-' {
+		row = row[1:]
+	} else {
+		var err error
+		if row, err = p.parseStructuredData(row, line); err != nil {
+			return err
+		}
+	}
+
+	if len(row) > 0 && row[0] == ' ' {
+		row = row[1:]
+	}
+	// BOM
+	row = bytes.TrimPrefix(row, []byte("\xef\xbb\xbf"))
+	if len(row) == 0 {
+		return nil
+	}
+	return p.assign(line, SyslogFieldMsg, string(row))
+}
+
+func (p *SyslogParser) parseStructuredData(row []byte, line LogLine) ([]byte, error) {
+	for len(row) > 0 && row[0] == '[' {
+		end := bytes.IndexAny(row, " ]")
+		if end < 0 {
+			return nil, errors.New("unterminated structured data")
+		}
+		id := string(row[1:end])
+		row = row[end:]
+
+		for len(row) > 0 && row[0] == ' ' {
+			row = row[1:]
+			eq := bytes.IndexByte(row, '=')
+			if eq < 1 || len(row) < eq+2 || row[eq+1] != '"' {
+				return nil, fmt.Errorf("invalid structured data param in '%s'", id)
+			}
+			name := string(row[:eq])
+			value, n, err := unquoteSDValue(row[eq+2:])
+			if err != nil {
+				return nil, fmt.Errorf("structured data param '%s.%s': %v", id, name, err)
+			}
+			if err := p.assign(line, SyslogFieldSDPrefix+id+"."+name, value); err != nil {
+				return nil, err
+			}
+			row = row[eq+2+n:]
+		}
+		if len(row) == 0 || row[0] != ']' {
+			return nil, fmt.Errorf("unterminated structured data element '%s'", id)
+		}
+		row = row[1:]
+	}
+	return row, nil
+}
+
+// parseRFC3164 parses "TIMESTAMP HOSTNAME TAG[PID]: MSG".
+// Timestamp is either "Mmm dd hh:mm:ss" or RFC3339 (used by rsyslog and syslog-ng in files).
+func (p *SyslogParser) parseRFC3164(row []byte, line LogLine) error {
+	var ts []byte
+	if len(row) >= 15 && row[3] == ' ' && row[6] == ' ' && row[9] == ':' {
+		ts, row = row[:15], row[15:]
+		if len(row) > 0 && row[0] == ' ' {
+			row = row[1:]
+		}
+	} else if len(row) > 0 && row[0] >= '0' && row[0] <= '9' {
+		ts, row = nextField(row)
+	}
+	if len(ts) > 0 {
+		if err := p.assign(line, SyslogFieldTimestamp, string(ts)); err != nil {
+			return err
+		}
+	}
+
+	// hostname is absent if the first word is the tag
+	if i := bytes.IndexByte(row, ' '); i > 0 && !isSyslogTag(row[:i]) {
+		if err := p.assign(line, SyslogFieldHostname, string(row[:i])); err != nil {
+			return err
+		}
+		row = row[i+1:]
+	}
+
+	if i := bytes.IndexByte(row, ' '); i > 0 && isSyslogTag(row[:i]) {
+		tag := row[:i-1]
+		row = row[i+1:]
+		if j := bytes.IndexByte(tag, '['); j > 0 && tag[len(tag)-1] == ']' {
+			if err := p.assign(line, SyslogFieldProcID, string(tag[j+1:len(tag)-1])); err != nil {
+				return err
+			}
+			tag = tag[:j]
+		}
+		if err := p.assign(line, SyslogFieldAppName, string(tag)); err != nil {
+			return err
+		}
+	}
+
+	if len(row) == 0 {
+		return nil
+	}
+	return p.assign(line, SyslogFieldMsg, string(row))
+}
+
+func detectSyslogFormat(row []byte) string {
+	// RFC5424 VERSION is "1" (non-zero digits), RFC3164 has no version
+	if i := bytes.IndexByte(row, ' '); i > 0 && i <= 3 {
+		for _, c := range row[:i] {
+			if c < '0' || c > '9' {
+				return SyslogFormatRFC3164
+			}
+		}
+		return SyslogFormatRFC5424
+	}
+	return SyslogFormatRFC3164
+}
+
+// isSyslogTag reports whether the word is "tag:" or "tag[pid]:".
+func isSyslogTag(word []byte) bool {
+	return len(word) > 1 && word[len(word)-1] == ':'
+}
+
+func nextField(row []byte) (field, rest []byte) {
+	if len(row) == 0 {
+		return nil, nil
+	}
+	if i := bytes.IndexByte(row, ' '); i >= 0 {
+		return row[:i], row[i+1:]
+	}
+	return row, nil
+}
+
+// unquoteSDValue returns the param value and the number of consumed bytes including the closing quote.
+func unquoteSDValue(b []byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(b) && (b[i+1] == '"' || b[i+1] == '\\' || b[i+1] == ']') {
+				i++
+				c = b[i]
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated value")
+}
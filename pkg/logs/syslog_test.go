@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyslogParser(t *testing.T) {
+	tests := map[string]struct {
+		config  SyslogConfig
+		wantErr bool
+	}{
+		"empty config":   {},
+		"rfc3164":        {config: SyslogConfig{Format: SyslogFormatRFC3164}},
+		"rfc5424":        {config: SyslogConfig{Format: SyslogFormatRFC5424}},
+		"invalid format": {config: SyslogConfig{Format: "rfc1"}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewSyslogParser(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestSyslogParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		config       SyslogConfig
+		row          string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"rfc5424": {
+			row: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Appl\"ication"][examplePriority@32473 class="high"] An application event`,
+			wantAssigned: map[string]string{
+				"facility":                         "local4",
+				"severity":                         "notice",
+				"version":                          "1",
+				"timestamp":                        "2003-10-11T22:14:15.003Z",
+				"hostname":                         "mymachine.example.com",
+				"appname":                          "evntslog",
+				"msgid":                            "ID47",
+				"sd.exampleSDID@32473.iut":         "3",
+				"sd.exampleSDID@32473.eventSource": `Appl"ication`,
+				"sd.examplePriority@32473.class":   "high",
+				"msg":                              "An application event",
+			},
+		},
+		"rfc5424 nil values": {
+			row: `<34>1 2003-10-11T22:14:15.003Z - - - - -`,
+			wantAssigned: map[string]string{
+				"facility":  "auth",
+				"severity":  "crit",
+				"version":   "1",
+				"timestamp": "2003-10-11T22:14:15.003Z",
+			},
+		},
+		"rfc3164": {
+			row: `<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`,
+			wantAssigned: map[string]string{
+				"facility":  "auth",
+				"severity":  "crit",
+				"timestamp": "Oct 11 22:14:15",
+				"hostname":  "mymachine",
+				"appname":   "su",
+				"procid":    "123",
+				"msg":       "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		"rfc3164 file without pri": {
+			row: `Oct  1 08:01:02 mymachine CRON[4242]: (root) CMD (run-parts /etc/cron.hourly)`,
+			wantAssigned: map[string]string{
+				"timestamp": "Oct  1 08:01:02",
+				"hostname":  "mymachine",
+				"appname":   "CRON",
+				"procid":    "4242",
+				"msg":       "(root) CMD (run-parts /etc/cron.hourly)",
+			},
+		},
+		"rfc3164 rfc3339 timestamp without hostname": {
+			row: `2022-10-19T10:00:00.123456+00:00 kernel: eth0: link up`,
+			wantAssigned: map[string]string{
+				"timestamp": "2022-10-19T10:00:00.123456+00:00",
+				"appname":   "kernel",
+				"msg":       "eth0: link up",
+			},
+		},
+		"forced rfc5424 on rfc3164": {
+			config:  SyslogConfig{Format: SyslogFormatRFC5424},
+			row:     `<34>Oct 11 22:14:15 mymachine`,
+			wantErr: true,
+		},
+		"invalid pri": {
+			row:     `<1000>1 - - - - - -`,
+			wantErr: true,
+		},
+		"negative pri": {
+			row:     `<-1>1 - - - - - -`,
+			wantErr: true,
+		},
+		"signed pri": {
+			row:     `<+5>1 - - - - - -`,
+			wantErr: true,
+		},
+		"unterminated structured data": {
+			row:     `<34>1 - - - - - [id a="1"`,
+			wantErr: true,
+		},
+		"error on assigning": {
+			config:  SyslogConfig{Mapping: map[string]string{"appname": "ERR"}},
+			row:     `<34>1 - - app - - -`,
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewSyslogParser(test.config, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.row), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestSyslogParser_ReadLine(t *testing.T) {
+	r := strings.NewReader("<13>1 - host app - - - msg 1\n<13>Oct 11 22:14:15 host app: msg 2\n")
+	p, err := NewSyslogParser(SyslogConfig{}, r)
+	require.NoError(t, err)
+
+	var msgs []string
+	for {
+		line := newLogLine()
+		if err := p.ReadLine(line); err != nil {
+			break
+		}
+		msgs = append(msgs, line.assigned["msg"])
+	}
+	assert.Equal(t, []string{"msg 1", "msg 2"}, msgs)
+}
+
+func TestSyslogParser_Info(t *testing.T) {
+	p, err := NewSyslogParser(SyslogConfig{}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
@@ -2,24 +2,88 @@
 
 package matcher
 
-import "sync"
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the maximum number of cached results used by WithCache.
+const DefaultCacheSize = 10000
 
 type (
+	// CacheConfig is the matcher cache configuration.
+	CacheConfig struct {
+		// Size is the maximum number of cached results, the least recently used result is evicted
+		// when the cache is full. Zero or negative means DefaultCacheSize.
+		Size int `yaml:"size"`
+		// TTL is the time a result is cached for. Zero means the results don't expire.
+		TTL time.Duration `yaml:"ttl"`
+	}
+
+	// CacheStats is the matcher cache statistics, the counters are cumulative.
+	CacheStats struct {
+		Hits        int64 `stm:"hits"`
+		Misses      int64 `stm:"misses"`
+		Evictions   int64 `stm:"evictions"`
+		Expirations int64 `stm:"expirations"`
+		Size        int64 `stm:"size"`
+	}
+
+	// CachedMatcher is a Matcher that caches results.
+	CachedMatcher interface {
+		Matcher
+		// Stats returns the cache statistics.
+		Stats() CacheStats
+	}
+
 	cachedMatcher struct {
 		matcher Matcher
+		size    int
+		ttl     time.Duration
+		now     func() time.Time
 
-		mux   sync.RWMutex
-		cache map[string]bool
+		mux   sync.Mutex
+		cache map[string]*list.Element
+		lru   *list.List // front is the most recently used
+		stats CacheStats
+	}
+	cacheEntry struct {
+		key     string
+		result  bool
+		expires time.Time
 	}
 )
 
-// WithCache adds cache to the matcher.
+// WithCache adds cache of up to DefaultCacheSize results to the matcher.
 func WithCache(m Matcher) Matcher {
+	return WithCacheConfig(m, CacheConfig{})
+}
+
+// WithCacheConfig adds cache to the matcher. The returned matcher implements CachedMatcher,
+// unless the matcher is TRUE() or FALSE(), they are returned as is.
+func WithCacheConfig(m Matcher, cfg CacheConfig) Matcher {
 	switch m {
 	case TRUE(), FALSE():
 		return m
 	default:
-		return &cachedMatcher{matcher: m, cache: make(map[string]bool)}
+		return newCachedMatcher(m, cfg)
+	}
+}
+
+func newCachedMatcher(m Matcher, cfg CacheConfig) *cachedMatcher {
+	size := cfg.Size
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &cachedMatcher{
+		matcher: m,
+		size:    size,
+		ttl:     cfg.TTL,
+		now:     time.Now,
+		cache:   make(map[string]*list.Element),
+		lru:     list.New(),
 	}
 }
 
@@ -42,15 +106,118 @@ func (m *cachedMatcher) MatchString(s string) bool {
 	return result
 }
 
+func (m *cachedMatcher) Stats() CacheStats {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	stats := m.stats
+	stats.Size = int64(m.lru.Len())
+	return stats
+}
+
 func (m *cachedMatcher) fetch(key string) (result bool, ok bool) {
-	m.mux.RLock()
-	result, ok = m.cache[key]
-	m.mux.RUnlock()
-	return
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	elem, ok := m.cache[key]
+	if !ok {
+		m.stats.Misses++
+		return false, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if m.ttl > 0 && !m.now().Before(entry.expires) {
+		m.remove(elem)
+		m.stats.Expirations++
+		m.stats.Misses++
+		return false, false
+	}
+	m.lru.MoveToFront(elem)
+	m.stats.Hits++
+	return entry.result, true
 }
 
 func (m *cachedMatcher) put(key string, result bool) {
 	m.mux.Lock()
-	m.cache[key] = result
-	m.mux.Unlock()
+	defer m.mux.Unlock()
+
+	var expires time.Time
+	if m.ttl > 0 {
+		expires = m.now().Add(m.ttl)
+	}
+	if elem, ok := m.cache[key]; ok {
+		// concurrent miss of the same key
+		entry := elem.Value.(*cacheEntry)
+		entry.result, entry.expires = result, expires
+		m.lru.MoveToFront(elem)
+		return
+	}
+	for m.lru.Len() >= m.size {
+		m.remove(m.lru.Back())
+		m.stats.Evictions++
+	}
+	m.cache[key] = m.lru.PushFront(&cacheEntry{key: key, result: result, expires: expires})
+}
+
+func (m *cachedMatcher) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.cache, elem.Value.(*cacheEntry).key)
+}
+
+var sharedCaches = struct {
+	mux     sync.Mutex
+	entries map[string]*sharedCache
+}{entries: make(map[string]*sharedCache)}
+
+type sharedCache struct {
+	matcher Matcher
+	refs    int
+}
+
+// ParseCached parses the expression (see Parse) and adds cache to the matcher.
+// Matchers built from the same expression and cache config share the cache,
+// so jobs using the same filter don't match the same values over and over.
+// The statistics of a shared cache are the sum of all its users.
+// Every matcher returned by ParseCached must be released with ReleaseCached when it is no longer used.
+func ParseCached(expr string, cfg CacheConfig) (Matcher, error) {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultCacheSize
+	}
+	key := fmt.Sprintf("%d|%d|%s", cfg.Size, cfg.TTL, expr)
+
+	sharedCaches.mux.Lock()
+	defer sharedCaches.mux.Unlock()
+
+	if e, ok := sharedCaches.entries[key]; ok {
+		e.refs++
+		return e.matcher, nil
+	}
+	m, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	m = WithCacheConfig(m, cfg)
+	if _, ok := m.(CachedMatcher); ok {
+		sharedCaches.entries[key] = &sharedCache{matcher: m, refs: 1}
+	}
+	return m, nil
+}
+
+// ReleaseCached releases the matcher returned by ParseCached.
+// The shared cache is dropped once all the matchers built from the same expression and cache config are released.
+func ReleaseCached(m Matcher) {
+	if _, ok := m.(CachedMatcher); !ok {
+		return
+	}
+
+	sharedCaches.mux.Lock()
+	defer sharedCaches.mux.Unlock()
+
+	for key, e := range sharedCaches.entries {
+		if e.matcher != m {
+			continue
+		}
+		if e.refs--; e.refs <= 0 {
+			delete(sharedCaches.entries, key)
+		}
+		return
+	}
 }
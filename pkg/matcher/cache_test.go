@@ -3,9 +3,13 @@
 package matcher
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithCache(t *testing.T) {
@@ -21,7 +25,142 @@ func TestWithCache(t *testing.T) {
 func TestWithCache_specialCase(t *testing.T) {
 	assert.Equal(t, TRUE(), WithCache(TRUE()))
 	assert.Equal(t, FALSE(), WithCache(FALSE()))
+	assert.Equal(t, TRUE(), WithCacheConfig(TRUE(), CacheConfig{Size: 1}))
 }
+
+func TestWithCacheConfig_Stats(t *testing.T) {
+	cached := WithCacheConfig(Must(NewRegExpMatcher("[0-9]+")), CacheConfig{Size: 2}).(CachedMatcher)
+
+	assert.True(t, cached.MatchString("1"))
+	assert.True(t, cached.MatchString("1"))
+	assert.False(t, cached.Match([]byte("a")))
+	assert.False(t, cached.Match([]byte("a")))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 2, Size: 2}, cached.Stats())
+
+	// "1" is the least recently used
+	assert.True(t, cached.MatchString("2"))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 2}, cached.Stats())
+
+	assert.False(t, cached.MatchString("a"))
+	assert.True(t, cached.MatchString("1"))
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 4, Evictions: 2, Size: 2}, cached.Stats())
+
+	// "2" was evicted, "a" and "1" are cached
+	assert.True(t, cached.MatchString("2"))
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 5, Evictions: 3, Size: 2}, cached.Stats())
+}
+
+func TestWithCacheConfig_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := WithCacheConfig(Must(NewRegExpMatcher("[0-9]+")), CacheConfig{TTL: time.Minute})
+	cached := m.(*cachedMatcher)
+	cached.now = func() time.Time { return now }
+
+	assert.True(t, cached.MatchString("1"))
+	now = now.Add(time.Second * 59)
+	assert.True(t, cached.MatchString("1"))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, cached.Stats())
+
+	now = now.Add(time.Second)
+	assert.True(t, cached.MatchString("1"))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Expirations: 1, Size: 1}, cached.Stats())
+
+	// the result was cached again
+	now = now.Add(time.Second * 59)
+	assert.True(t, cached.MatchString("1"))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 2, Expirations: 1, Size: 1}, cached.Stats())
+}
+
+func TestWithCache_Bounded(t *testing.T) {
+	cached := WithCache(Must(NewRegExpMatcher("[0-9]+"))).(CachedMatcher)
+
+	for i := 0; i < DefaultCacheSize*2; i++ {
+		cached.MatchString(fmt.Sprintf("value%d", i))
+	}
+	stats := cached.Stats()
+	assert.Equal(t, int64(DefaultCacheSize), stats.Size)
+	assert.Equal(t, int64(DefaultCacheSize), stats.Evictions)
+}
+
+func TestWithCache_Concurrent(t *testing.T) {
+	cached := WithCacheConfig(Must(NewRegExpMatcher("^[0-9]+$")), CacheConfig{Size: 10}).(CachedMatcher)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				s := fmt.Sprint(j % 20)
+				if !cached.MatchString(s) || cached.Match([]byte(s+"a")) {
+					t.Error("unexpected match result")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cached.Stats()
+	assert.Equal(t, int64(8*1000*2), stats.Hits+stats.Misses)
+	assert.Equal(t, int64(10), stats.Size)
+}
+
+func TestParseCached(t *testing.T) {
+	m1, err := ParseCached("~ ^parse_cached[0-9]+$", CacheConfig{})
+	require.NoError(t, err)
+	m2, err := ParseCached("~ ^parse_cached[0-9]+$", CacheConfig{Size: DefaultCacheSize})
+	require.NoError(t, err)
+	m3, err := ParseCached("~ ^parse_cached[0-9]+$", CacheConfig{Size: 10})
+	require.NoError(t, err)
+	m4, err := ParseCached("~ ^parse_cached[a-z]+$", CacheConfig{})
+	require.NoError(t, err)
+
+	assert.Same(t, m1, m2)
+	assert.NotSame(t, m1, m3)
+	assert.NotSame(t, m1, m4)
+
+	assert.True(t, m1.MatchString("parse_cached1"))
+	assert.True(t, m2.MatchString("parse_cached1"))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, m1.(CachedMatcher).Stats())
+	assert.Equal(t, CacheStats{}, m3.(CachedMatcher).Stats())
+
+	_, err = ParseCached("~ [", CacheConfig{})
+	assert.Error(t, err)
+
+	for _, m := range []Matcher{m1, m2, m3, m4} {
+		ReleaseCached(m)
+	}
+	assert.Empty(t, sharedCaches.entries)
+}
+
+func TestReleaseCached(t *testing.T) {
+	m1, err := ParseCached("~ ^release_cached$", CacheConfig{})
+	require.NoError(t, err)
+	m2, err := ParseCached("~ ^release_cached$", CacheConfig{})
+	require.NoError(t, err)
+	require.Same(t, m1, m2)
+
+	ReleaseCached(m1)
+	m3, err := ParseCached("~ ^release_cached$", CacheConfig{})
+	require.NoError(t, err)
+	assert.Same(t, m2, m3, "the cache is dropped while in use")
+
+	ReleaseCached(m2)
+	ReleaseCached(m3)
+	m4, err := ParseCached("~ ^release_cached$", CacheConfig{})
+	require.NoError(t, err)
+	assert.NotSame(t, m3, m4, "the cache is not dropped after all users released it")
+	ReleaseCached(m4)
+
+	// matchers without cache are not registered
+	m5, err := ParseCached("* *", CacheConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, TRUE(), m5)
+	ReleaseCached(m5)
+	assert.Empty(t, sharedCaches.entries)
+}
+
 func BenchmarkCachedMatcher_MatchString_cache_hit(b *testing.B) {
 	benchmarks := []struct {
 		name   string
@@ -9,6 +9,7 @@ Supported Format
 	glob
 	regexp
 	simple patterns
+	ip
 
 The string matcher reports whether the given value equals to the string ( use == ).
 
@@ -36,5 +37,9 @@ The RegExp syntax is described at https://golang.org/pkg/regexp/syntax/.
 The simple patterns matcher reports whether the given value matches the simple patterns.
 The simple patterns is a custom format used in netdata,
 it's syntax is described at https://docs.netdata.cloud/libnetdata/simple_pattern/.
+
+The ip matcher reports whether the given value is an IP address within the IP ranges.
+The expression is a space separated list of IP addresses, ranges, CIDRs and subnet masks,
+a range preceded by '!' excludes addresses.
 */
 package matcher
@@ -47,3 +47,15 @@ func ExampleNew_regexp_format() {
 	m.MatchString("1a") // => true
 	m.MatchString("a")  // => false
 }
+
+func ExampleNew_ip_format() {
+	// create an ip matcher, which perform IP ranges match
+	m, err := matcher.New(matcher.FmtIP, "10.0.0.0/8 !10.1.0.0/16")
+	if err != nil {
+		panic(err)
+	}
+	m.MatchString("10.0.0.1")    // => true
+	m.MatchString("10.1.0.1")    // => false
+	m.MatchString("192.168.0.1") // => false
+	m.MatchString("hello")       // => false
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/iprange"
+)
+
+type (
+	// ipMatcher matches IP addresses against IP ranges:
+	//     (includes.contains(ip) || there are no includes) && !excludes.contains(ip)
+	ipMatcher struct {
+		includes    ipTrie
+		excludes    ipTrie
+		hasIncludes bool
+	}
+
+	// ipTrie is a binary trie of network prefixes, an address is in the trie if any of its prefixes is.
+	ipTrie struct {
+		v4 *ipTrieNode
+		v6 *ipTrieNode
+	}
+	ipTrieNode struct {
+		children [2]*ipTrieNode
+		leaf     bool
+	}
+)
+
+// NewIPMatcher creates a new IP addresses matcher.
+// The expression is a space separated list of IP ranges, a range can be prefixed with '!' (excluded range)
+// and with the "ip:" format. A range can be in any pkg/iprange form: address ("192.0.2.1"),
+// range ("192.0.2.0-192.0.2.10"), CIDR ("192.0.2.0/24", "2001:db8::/64") or subnet mask ("192.0.2.0/255.255.255.0").
+// CIDR and subnet mask ranges include network and broadcast addresses.
+//
+// The matcher reports whether the value is an IP address that is in any of the included ranges
+// (or there are no included ranges) and is not in any of the excluded ranges.
+func NewIPMatcher(expr string) (Matcher, error) {
+	m := &ipMatcher{}
+	terms := strings.Fields(expr)
+	if len(terms) == 0 {
+		return nil, errors.New("empty ip matcher expression")
+	}
+
+	for _, term := range terms {
+		exclude := strings.HasPrefix(term, "!")
+		term = strings.TrimPrefix(term, "!")
+		term = strings.TrimPrefix(term, string(FmtIP)+Separator)
+
+		start, end, err := parseIPMatcherRange(term)
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			m.excludes.insertRange(start, end)
+		} else {
+			m.includes.insertRange(start, end)
+			m.hasIncludes = true
+		}
+	}
+	return m, nil
+}
+
+func (m *ipMatcher) Match(b []byte) bool {
+	return m.MatchString(string(b))
+}
+
+func (m *ipMatcher) MatchString(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return false
+	}
+	addr = addr.WithZone("").Unmap()
+	if m.hasIncludes && !m.includes.contains(addr) {
+		return false
+	}
+	return !m.excludes.contains(addr)
+}
+
+func parseIPMatcherRange(s string) (start, end netip.Addr, err error) {
+	if idx := strings.IndexByte(s, '/'); idx != -1 {
+		prefix, err := parseIPMatcherPrefix(s[:idx], s[idx+1:])
+		if err != nil {
+			return start, end, fmt.Errorf("ip range (%s) invalid syntax", s)
+		}
+		return prefix.Addr(), lastAddr(prefix), nil
+	}
+
+	r, err := iprange.ParseRange(s)
+	if err != nil {
+		return start, end, err
+	}
+	start, _ = netip.AddrFromSlice(r.Start())
+	end, _ = netip.AddrFromSlice(r.End())
+	return start.Unmap(), end.Unmap(), nil
+}
+
+// parseIPMatcherPrefix parses CIDR and subnet mask ranges, unlike pkg/iprange it keeps network and broadcast addresses.
+func parseIPMatcherPrefix(address, bits string) (netip.Prefix, error) {
+	if strings.Contains(bits, ".") {
+		mask := net.ParseIP(bits).To4()
+		if mask == nil {
+			return netip.Prefix{}, errors.New("invalid subnet mask")
+		}
+		ones, size := net.IPv4Mask(mask[0], mask[1], mask[2], mask[3]).Size()
+		if ones+size == 0 {
+			return netip.Prefix{}, errors.New("non canonical subnet mask")
+		}
+		bits = fmt.Sprint(ones)
+	}
+	prefix, err := netip.ParsePrefix(address + "/" + bits)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() {
+		return netip.Prefix{}, errors.New("IPv4-mapped IPv6 prefixes are not supported")
+	}
+	return prefix.Masked(), nil
+}
+
+// insertRange inserts the minimal set of prefixes that covers the range.
+func (t *ipTrie) insertRange(start, end netip.Addr) {
+	for start.IsValid() && start.Compare(end) <= 0 {
+		bits := start.BitLen()
+		for bits > 0 {
+			p := netip.PrefixFrom(start, bits-1).Masked()
+			if p.Addr() != start || lastAddr(p).Compare(end) > 0 {
+				break
+			}
+			bits--
+		}
+		prefix := netip.PrefixFrom(start, bits)
+		t.insert(prefix)
+		start = lastAddr(prefix).Next()
+	}
+}
+
+func (t *ipTrie) insert(prefix netip.Prefix) {
+	root := &t.v6
+	if prefix.Addr().Is4() {
+		root = &t.v4
+	}
+	if *root == nil {
+		*root = &ipTrieNode{}
+	}
+
+	node := *root
+	ip := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		if node.leaf {
+			return
+		}
+		bit := ip[i/8] >> (7 - i%8) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &ipTrieNode{}
+		}
+		node = node.children[bit]
+	}
+	node.leaf = true
+	node.children = [2]*ipTrieNode{}
+}
+
+func (t *ipTrie) contains(addr netip.Addr) bool {
+	node := t.v6
+	if addr.Is4() {
+		node = t.v4
+	}
+
+	ip := addr.AsSlice()
+	for i := 0; node != nil; i++ {
+		if node.leaf {
+			return true
+		}
+		if i == len(ip)*8 {
+			return false
+		}
+		node = node.children[ip[i/8]>>(7-i%8)&1]
+	}
+	return false
+}
+
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	ip := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(ip)*8; i++ {
+		ip[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return addr
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package matcher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIPMatcher(t *testing.T) {
+	tests := map[string]struct {
+		expr    string
+		wantErr bool
+	}{
+		"address":            {expr: "192.0.2.1"},
+		"range":              {expr: "192.0.2.0-192.0.2.10"},
+		"CIDR":               {expr: "192.0.2.0/24"},
+		"subnet mask":        {expr: "192.0.2.0/255.255.255.0"},
+		"IPv6 CIDR":          {expr: "2001:db8::/64"},
+		"terms with format":  {expr: "ip:10.0.0.0/8 !ip:10.1.0.0/16"},
+		"empty":              {expr: " ", wantErr: true},
+		"invalid address":    {expr: "192.0.2.300", wantErr: true},
+		"invalid range":      {expr: "192.0.2.10-192.0.2.1", wantErr: true},
+		"invalid CIDR":       {expr: "192.0.2.0/33", wantErr: true},
+		"invalid mask":       {expr: "192.0.2.0/255.0.255.0", wantErr: true},
+		"not an ip":          {expr: "example.com", wantErr: true},
+		"IPv4-mapped prefix": {expr: "::ffff:192.0.2.0/120", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewIPMatcher(test.expr)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, m)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, m)
+			}
+		})
+	}
+}
+
+func TestIPMatcher_MatchString(t *testing.T) {
+	tests := map[string]struct {
+		expr      string
+		matches   []string
+		unmatches []string
+	}{
+		"address": {
+			expr:      "192.0.2.1",
+			matches:   []string{"192.0.2.1", "::ffff:192.0.2.1"},
+			unmatches: []string{"192.0.2.2", "192.0.2.0", "2001:db8::1", "", "foo"},
+		},
+		"CIDR includes network and broadcast": {
+			expr:      "192.0.2.0/24",
+			matches:   []string{"192.0.2.0", "192.0.2.1", "192.0.2.255"},
+			unmatches: []string{"192.0.1.255", "192.0.3.0"},
+		},
+		"subnet mask": {
+			expr:      "192.0.2.0/255.255.255.128",
+			matches:   []string{"192.0.2.0", "192.0.2.127"},
+			unmatches: []string{"192.0.2.128"},
+		},
+		"range not aligned to prefixes": {
+			expr:      "192.0.2.3-192.0.2.17",
+			matches:   []string{"192.0.2.3", "192.0.2.4", "192.0.2.8", "192.0.2.16", "192.0.2.17"},
+			unmatches: []string{"192.0.2.2", "192.0.2.18", "192.0.3.3"},
+		},
+		"everything": {
+			expr:      "0.0.0.0/0 ::/0",
+			matches:   []string{"0.0.0.0", "255.255.255.255", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			unmatches: []string{"not an ip"},
+		},
+		"range up to the last address": {
+			expr:      "255.255.255.250-255.255.255.255",
+			matches:   []string{"255.255.255.250", "255.255.255.255"},
+			unmatches: []string{"255.255.255.249"},
+		},
+		"IPv6": {
+			expr:      "2001:db8::/64 2001:db8:1::1-2001:db8:1::10",
+			matches:   []string{"2001:db8::", "2001:db8::ffff:1", "2001:db8:1::a", "2001:db8::1%eth0"},
+			unmatches: []string{"2001:db8:0:1::", "2001:db8:1::11", "192.0.2.1"},
+		},
+		"include and exclude": {
+			expr:      "ip:10.0.0.0/8 !ip:10.1.0.0/16",
+			matches:   []string{"10.0.0.1", "10.2.3.4", "10.255.255.255"},
+			unmatches: []string{"10.1.0.0", "10.1.2.3", "11.0.0.1"},
+		},
+		"exclude only": {
+			expr:      "!10.0.0.0/8 !2001:db8::/32",
+			matches:   []string{"192.0.2.1", "2001:db9::1"},
+			unmatches: []string{"10.0.0.1", "2001:db8::1", "not an ip"},
+		},
+		"overlapping ranges": {
+			expr:      "10.0.0.0/16 10.0.0.0/8 10.0.0.1",
+			matches:   []string{"10.0.0.1", "10.200.0.1"},
+			unmatches: []string{"11.0.0.0"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewIPMatcher(test.expr)
+			require.NoError(t, err)
+
+			for _, ip := range test.matches {
+				assert.Truef(t, m.MatchString(ip), "'%s' should match", ip)
+				assert.Truef(t, m.Match([]byte(ip)), "'%s' should match", ip)
+			}
+			for _, ip := range test.unmatches {
+				assert.Falsef(t, m.MatchString(ip), "'%s' should not match", ip)
+				assert.Falsef(t, m.Match([]byte(ip)), "'%s' should not match", ip)
+			}
+		})
+	}
+}
+
+func TestIPMatcher_Parse(t *testing.T) {
+	m, err := Parse("ip:10.0.0.0/8 !ip:10.1.0.0/16")
+	require.NoError(t, err)
+	assert.True(t, m.MatchString("10.2.0.1"))
+	assert.False(t, m.MatchString("10.1.0.1"))
+
+	m, err = Parse("!ip:10.0.0.0/8")
+	require.NoError(t, err)
+	assert.False(t, m.MatchString("10.2.0.1"))
+	assert.True(t, m.MatchString("192.0.2.1"))
+}
+
+func TestIPMatcher_SimpleExpr(t *testing.T) {
+	expr := &SimpleExpr{
+		Includes: []string{"ip:10.0.0.0/8", "ip:192.168.0.0/16"},
+		Excludes: []string{"ip:10.1.0.0/16", "= 192.168.1.1"},
+	}
+	m, err := expr.Parse()
+	require.NoError(t, err)
+
+	assert.True(t, m.MatchString("10.0.0.1"))
+	assert.True(t, m.MatchString("192.168.2.1"))
+	assert.False(t, m.MatchString("10.1.0.1"))
+	assert.False(t, m.MatchString("192.168.1.1"))
+	assert.False(t, m.MatchString("172.16.0.1"))
+}
+
+func TestIPMatcher_Logical(t *testing.T) {
+	ip := Must(New(FmtIP, "192.0.2.0/24"))
+	even := Must(NewRegExpMatcher(`[02468]$`))
+
+	m := And(ip, Not(even))
+	assert.True(t, m.MatchString("192.0.2.1"))
+	assert.False(t, m.MatchString("192.0.2.2"))
+	assert.False(t, m.MatchString("192.0.3.1"))
+
+	m = Or(ip, Must(New(FmtIP, "2001:db8::/32")))
+	assert.True(t, m.MatchString("2001:db8::1"))
+	assert.True(t, m.MatchString("192.0.2.2"))
+}
+
+func BenchmarkIPMatcher_MatchString(b *testing.B) {
+	var expr string
+	for i := 0; i < 256; i++ {
+		expr += fmt.Sprintf("10.%d.0.0/16 ", i)
+	}
+	m := Must(NewIPMatcher(expr + "!10.1.2.0/24"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.MatchString("10.200.3.4")
+	}
+}
@@ -31,6 +31,8 @@ const (
 	// FmtSimplePattern is a simple pattern match format
 	// https://docs.netdata.cloud/libnetdata/simple_pattern/
 	FmtSimplePattern Format = "simple_patterns"
+	// FmtIP is an IP addresses match format.
+	FmtIP Format = "ip"
 
 	// Separator is a separator between match format and expression.
 	Separator = ":"
@@ -71,6 +73,8 @@ func New(format Format, expr string) (Matcher, error) {
 		return NewRegExpMatcher(expr)
 	case FmtSimplePattern:
 		return NewSimplePatternsMatcher(expr)
+	case FmtIP:
+		return NewIPMatcher(expr)
 	default:
 		return nil, fmt.Errorf("unsupported matcher format: '%s'", format)
 	}
@@ -93,7 +97,7 @@ func New(format Format, expr string) (Matcher, error) {
 // Long Syntax
 //
 //	<line>      ::= [ <not> ] <format> <separator> <expr>
-//	<format>    ::= [ 'string' | 'glob' | 'regexp' | 'simple_patterns' ]
+//	<format>    ::= [ 'string' | 'glob' | 'regexp' | 'simple_patterns' | 'ip' ]
 //	<not>       ::= '!'
 //	                  negative expression
 //	<separator> ::= ':'
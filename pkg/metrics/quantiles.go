@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+type (
+	// Quantiles estimates quantiles (percentiles) of observations from an event or sample stream.
+	// It is a DDSketch: observations are counted in logarithmically sized bins, that guarantees
+	// the relative error of every estimated quantile to be at most the configured relative accuracy.
+	// Memory is bounded by the max number of bins, the lowest bins are collapsed when it is reached,
+	// so the accuracy guarantee holds for the higher quantiles.
+	//
+	// To create quantiles instances, use NewQuantiles.
+	Quantiles interface {
+		Observer
+		Reset()
+		Quantile(q float64) float64
+	}
+
+	quantiles struct {
+		quantiles []float64
+		names     []string
+
+		gamma    float64
+		logGamma float64
+		maxBins  int
+
+		positive map[int]int64
+		negative map[int]int64
+		zero     int64
+		count    int64
+		min      float64
+		max      float64
+	}
+)
+
+var (
+	_ stm.Value = quantiles{}
+)
+
+const (
+	// DefQuantilesRelativeAccuracy is the default relative accuracy of estimated quantiles.
+	DefQuantilesRelativeAccuracy = 0.01
+	// DefQuantilesMaxBins is the default max number of bins. With the default relative accuracy
+	// 2048 bins cover values from 1 to ~10^17 without collapsing.
+	DefQuantilesMaxBins = 2048
+
+	// minQuantilesValue is the smallest absolute value that isn't counted as zero.
+	minQuantilesValue = 1e-9
+)
+
+// DefQuantiles are the default quantiles.
+var DefQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// NewQuantiles creates a new Quantiles with the default relative accuracy and max number of bins.
+func NewQuantiles(qs []float64) Quantiles {
+	return NewQuantilesWithAccuracy(qs, DefQuantilesRelativeAccuracy, DefQuantilesMaxBins)
+}
+
+// NewQuantilesWithAccuracy creates a new Quantiles.
+//
+// The function panics if a quantile is not in the range [0, 1],
+// if 'relativeAccuracy' is not in the range (0, 1), or if 'maxBins' is less than 1.
+func NewQuantilesWithAccuracy(qs []float64, relativeAccuracy float64, maxBins int) Quantiles {
+	if len(qs) == 0 {
+		qs = DefQuantiles
+	}
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		panic("Quantiles needs a relative accuracy in the range (0, 1)")
+	}
+	if maxBins < 1 {
+		panic("Quantiles needs a positive max bins")
+	}
+
+	qs = append([]float64(nil), qs...)
+	sort.Float64s(qs)
+	names := make([]string, len(qs))
+	for i, q := range qs {
+		if q < 0 || q > 1 {
+			panic("Quantiles needs quantiles in the range [0, 1]")
+		}
+		names[i] = QuantileName(q)
+	}
+
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	s := &quantiles{
+		quantiles: qs,
+		names:     names,
+		gamma:     gamma,
+		logGamma:  math.Log(gamma),
+		maxBins:   maxBins,
+	}
+	s.Reset()
+	return s
+}
+
+// WriteTo writes its values into given map.
+// It adds those key-value pairs:
+//
+//	${key}_p50        gauge, for 0.5 quantile of it's observed values from last Reset calls (only exists if count > 0)
+//	${key}_p99        gauge, for 0.99 quantile of it's observed values from last Reset calls (only exists if count > 0)
+//	${key}_p99_9      gauge, for 0.999 quantile of it's observed values from last Reset calls (only exists if count > 0)
+//	...
+func (s quantiles) WriteTo(rv map[string]int64, key string, mul, div int) {
+	for i, q := range s.quantiles {
+		k := key + "_" + s.names[i]
+		if s.count == 0 {
+			delete(rv, k)
+			continue
+		}
+		rv[k] = int64(s.Quantile(q) * float64(mul) / float64(div))
+	}
+}
+
+// Reset resets all of its bins.
+// Call it before every scrape loop.
+func (s *quantiles) Reset() {
+	s.positive = make(map[int]int64)
+	s.negative = make(map[int]int64)
+	s.zero = 0
+	s.count = 0
+	s.min = math.MaxFloat64
+	s.max = -math.MaxFloat64
+}
+
+// Observe observes a value
+func (s *quantiles) Observe(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	switch {
+	case v > minQuantilesValue:
+		s.positive[s.index(v)]++
+		s.collapse(s.positive)
+	case v < -minQuantilesValue:
+		s.negative[s.index(-v)]++
+		s.collapse(s.negative)
+	default:
+		s.zero++
+	}
+	if v > s.max {
+		s.max = v
+	}
+	if v < s.min {
+		s.min = v
+	}
+	s.count++
+}
+
+// Quantile returns the estimated value of the q quantile, it returns 0 if there are no observations.
+func (s quantiles) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return s.min
+	}
+	if q >= 1 {
+		return s.max
+	}
+
+	rank := int64(q * float64(s.count-1))
+	var v float64
+	switch {
+	case rank < sumBins(s.negative):
+		// negative values are walked from the highest absolute value
+		idx := sortedIndexes(s.negative)
+		v = -s.value(walkBins(s.negative, idx, len(idx)-1, -1, rank))
+	case rank < sumBins(s.negative)+s.zero:
+		v = 0
+	default:
+		idx := sortedIndexes(s.positive)
+		v = s.value(walkBins(s.positive, idx, 0, 1, rank-sumBins(s.negative)-s.zero))
+	}
+	return math.Max(s.min, math.Min(s.max, v))
+}
+
+func (s quantiles) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+func (s quantiles) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+// collapse merges the lowest bins if there are too many of them.
+func (s quantiles) collapse(bins map[int]int64) {
+	if len(bins) <= s.maxBins {
+		return
+	}
+	idx := sortedIndexes(bins)
+	excess := len(idx) - s.maxBins
+	target := idx[excess]
+	for _, i := range idx[:excess] {
+		bins[target] += bins[i]
+		delete(bins, i)
+	}
+}
+
+func walkBins(bins map[int]int64, idx []int, start, step int, rank int64) int {
+	var cum int64
+	i := start
+	for ; i >= 0 && i < len(idx); i += step {
+		cum += bins[idx[i]]
+		if cum > rank {
+			return idx[i]
+		}
+	}
+	return idx[i-step]
+}
+
+func sortedIndexes(bins map[int]int64) []int {
+	idx := make([]int, 0, len(bins))
+	for i := range bins {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+func sumBins(bins map[int]int64) int64 {
+	var sum int64
+	for _, v := range bins {
+		sum += v
+	}
+	return sum
+}
+
+// QuantileName returns the key suffix WriteTo uses for the quantile.
+// The quantile is formatted as a percentile: 0.5 => "p50", 0.999 => "p99_9".
+func QuantileName(q float64) string {
+	// rounding, 0.95*100 is 94.99999999999999
+	s := strconv.FormatFloat(math.Round(q*1e6)/1e4, 'f', -1, 64)
+	return "p" + strings.ReplaceAll(s, ".", "_")
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuantiles(t *testing.T) {
+	s := NewQuantiles(nil).(*quantiles)
+	assert.Equal(t, DefQuantiles, s.quantiles)
+	assert.Equal(t, []string{"p50", "p90", "p95", "p99"}, s.names)
+	assert.EqualValues(t, 0, s.count)
+
+	s = NewQuantiles([]float64{0.999, 0.5}).(*quantiles)
+	assert.Equal(t, []float64{0.5, 0.999}, s.quantiles)
+	assert.Equal(t, []string{"p50", "p99_9"}, s.names)
+}
+
+func TestNewQuantilesWithAccuracy_Panics(t *testing.T) {
+	assert.Panics(t, func() { NewQuantilesWithAccuracy([]float64{1.5}, 0.01, 10) })
+	assert.Panics(t, func() { NewQuantilesWithAccuracy(nil, 0, 10) })
+	assert.Panics(t, func() { NewQuantilesWithAccuracy(nil, 1, 10) })
+	assert.Panics(t, func() { NewQuantilesWithAccuracy(nil, 0.01, 0) })
+}
+
+func TestQuantiles_WriteTo(t *testing.T) {
+	s := NewQuantiles([]float64{0.5, 0.99})
+
+	m := map[string]int64{}
+	s.WriteTo(m, "time", 1, 1)
+	assert.Len(t, m, 0)
+
+	for i := 1; i <= 100; i++ {
+		s.Observe(float64(i))
+	}
+	s.WriteTo(m, "time", 1000, 1)
+	require.Len(t, m, 2)
+	assert.InEpsilon(t, 50000, m["time_p50"], DefQuantilesRelativeAccuracy)
+	assert.InEpsilon(t, 99000, m["time_p99"], DefQuantilesRelativeAccuracy)
+
+	s.Reset()
+	s.WriteTo(m, "time", 1000, 1)
+	assert.Len(t, m, 0)
+}
+
+func TestQuantiles_Quantile(t *testing.T) {
+	s := NewQuantiles(nil)
+	assert.Equal(t, 0.0, s.Quantile(0.5))
+
+	for _, v := range []float64{-5, -1, 0, 0, 1, 2, 3} {
+		s.Observe(v)
+	}
+	s.Observe(math.NaN())
+	s.Observe(math.Inf(1))
+
+	assert.Equal(t, -5.0, s.Quantile(0))
+	assert.InEpsilon(t, -1, s.Quantile(1.0/6), DefQuantilesRelativeAccuracy)
+	assert.Equal(t, 0.0, s.Quantile(0.5))
+	assert.InEpsilon(t, 2, s.Quantile(5.0/6), DefQuantilesRelativeAccuracy)
+	assert.Equal(t, 3.0, s.Quantile(1))
+}
+
+func TestQuantiles_Accuracy(t *testing.T) {
+	const relativeAccuracy = 0.01
+	qs := []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 0.999}
+	r := rand.New(rand.NewSource(1))
+
+	tests := map[string]func() float64{
+		"uniform":     func() float64 { return r.Float64() * 1000 },
+		"exponential": func() float64 { return r.ExpFloat64() * 0.2 },
+		"lognormal":   func() float64 { return math.Exp(r.NormFloat64()*2 + 3) },
+		"normal":      func() float64 { return r.NormFloat64() * 100 },
+	}
+
+	for name, gen := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewQuantilesWithAccuracy(qs, relativeAccuracy, DefQuantilesMaxBins)
+			values := make([]float64, 100000)
+			for i := range values {
+				values[i] = gen()
+				s.Observe(values[i])
+			}
+			sort.Float64s(values)
+
+			for _, q := range qs {
+				exact := values[int(q*float64(len(values)-1))]
+				assert.InDeltaf(t, exact, s.Quantile(q), math.Abs(exact)*relativeAccuracy+1e-9, "quantile %v", q)
+			}
+		})
+	}
+}
+
+func TestQuantiles_BoundedMemory(t *testing.T) {
+	const maxBins = 100
+	s := NewQuantilesWithAccuracy([]float64{0.99}, 0.01, maxBins).(*quantiles)
+	r := rand.New(rand.NewSource(1))
+
+	values := make([]float64, 100000)
+	for i := range values {
+		// 10^-5 .. 10^5
+		values[i] = math.Pow(10, r.Float64()*10-5)
+		s.Observe(values[i])
+	}
+	sort.Float64s(values)
+
+	assert.LessOrEqual(t, len(s.positive), maxBins)
+	// the high quantiles keep the accuracy guarantee
+	exact := values[int(0.99*float64(len(values)-1))]
+	assert.InDelta(t, exact, s.Quantile(0.99), exact*0.01)
+}
+
+func TestQuantiles_Reset(t *testing.T) {
+	s := NewQuantiles(nil).(*quantiles)
+	s.Observe(1)
+	s.Observe(-1)
+	s.Observe(0)
+	s.Reset()
+	assert.EqualValues(t, 0, s.count)
+	assert.Len(t, s.positive, 0)
+	assert.Len(t, s.negative, 0)
+	assert.EqualValues(t, 0, s.zero)
+}
+
+func BenchmarkQuantiles_Observe(b *testing.B) {
+	s := NewQuantiles(nil)
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 1024)
+	for i := range values {
+		values[i] = r.ExpFloat64()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Observe(values[i%len(values)])
+	}
+}
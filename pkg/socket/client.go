@@ -4,8 +4,10 @@ package socket
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/tls"
 	"errors"
+	"io"
 	"net"
 	"time"
 )
@@ -54,21 +56,81 @@ func (s *Socket) Disconnect() (err error) {
 }
 
 // Command writes the command string to the connection and passed the
-// response bytes line by line to the process function. It uses the
-// timeout value from the Socket config and returns read, write and
-// timeout errors if any. If a timeout occurs during the processing
-// of the responses this function will stop processing and return a
-// timeout error.
+// response bytes frame by frame (line by line by default) to the process
+// function. It uses the timeout value from the Socket config and returns
+// read, write and timeout errors if any. If a timeout occurs during the
+// processing of the responses this function will stop processing and
+// return a timeout error.
+//
+// A UDP response is a single datagram, the request is resent up to the
+// config Retries times if there is no response within the read timeout.
+// If the command fails before any frame is processed, the Socket reconnects
+// and repeats it according to the config Reconnect policy.
 func (s *Socket) Command(command string, process Processor) error {
-	if s.conn == nil {
-		return errors.New("cannot send command on nil connection")
+	if process == nil {
+		return errors.New("process func is nil")
+	}
+
+	var frames int
+	counter := func(b []byte) bool { frames++; return process(b) }
+
+	err := s.ensureConnected()
+	if err == nil {
+		err = s.command(command, counter)
+	}
+	for attempt := 1; err != nil && frames == 0 && attempt <= s.Reconnect.MaxAttempts; attempt++ {
+		time.Sleep(s.Reconnect.delay(attempt))
+		_ = s.Disconnect()
+		if err = s.Connect(); err == nil {
+			err = s.command(command, counter)
+		}
+	}
+	return err
+}
+
+func (s *Socket) ensureConnected() error {
+	if s.conn != nil {
+		return nil
+	}
+	if s.Reconnect.MaxAttempts > 0 {
+		return s.Connect()
+	}
+	return errors.New("cannot send command on nil connection")
+}
+
+func (s *Socket) command(command string, process Processor) error {
+	if _, ok := s.conn.(net.PacketConn); ok {
+		return s.packetCommand(command, process)
 	}
 	if err := write(command, s.conn, s.WriteTimeout); err != nil {
 		return err
 	}
-	return read(s.conn, process, s.ReadTimeout)
+	return read(s.conn, process, s.ReadTimeout, s.Framer, s.MaxFrameSize)
 }
 
+func (s *Socket) packetCommand(command string, process Processor) error {
+	buf := make([]byte, maxDatagramSize)
+	var err error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if err = write(command, s.conn, s.WriteTimeout); err != nil {
+			return err
+		}
+		if err = s.conn.SetReadDeadline(time.Now().Add(s.ReadTimeout)); err != nil {
+			return err
+		}
+		var n int
+		if n, err = s.conn.Read(buf); err == nil {
+			return scanFrames(bytes.NewReader(buf[:n]), process, s.Framer, s.MaxFrameSize)
+		}
+		if !isTimeout(err) {
+			return err
+		}
+	}
+	return err
+}
+
+const maxDatagramSize = 64 * 1024
+
 func write(command string, writer net.Conn, timeout time.Duration) error {
 	if writer == nil {
 		return errors.New("attempt to write on nil connection")
@@ -80,7 +142,7 @@ func write(command string, writer net.Conn, timeout time.Duration) error {
 	return err
 }
 
-func read(reader net.Conn, process Processor, timeout time.Duration) error {
+func read(reader net.Conn, process Processor, timeout time.Duration, framer Framer, maxFrameSize int) error {
 	if process == nil {
 		return errors.New("process func is nil")
 	}
@@ -90,8 +152,27 @@ func read(reader net.Conn, process Processor, timeout time.Duration) error {
 	if err := reader.SetReadDeadline(time.Now().Add(timeout)); err != nil {
 		return err
 	}
+	return scanFrames(reader, process, framer, maxFrameSize)
+}
+
+func scanFrames(reader io.Reader, process Processor, framer Framer, maxFrameSize int) error {
 	scanner := bufio.NewScanner(reader)
+	if framer != nil {
+		scanner.Split(bufio.SplitFunc(framer))
+	}
+	if maxFrameSize > 0 {
+		size := 4096
+		if maxFrameSize < size {
+			size = maxFrameSize
+		}
+		scanner.Buffer(make([]byte, 0, size), maxFrameSize)
+	}
 	for scanner.Scan() && process(scanner.Bytes()) {
 	}
 	return scanner.Err()
 }
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -4,6 +4,8 @@ package socket
 
 import (
 	"crypto/tls"
+	"encoding/binary"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -161,3 +163,167 @@ func Test_clientEmptyProcessFunc(t *testing.T) {
 	err := sock.Command("ping\n", nil)
 	require.Error(t, err, "nil process func should return an error")
 }
+
+func Test_clientFramedCommand(t *testing.T) {
+	srv := &framedServer{resp: []byte("\x00\x02ab\x00\x00\x00\x03cde")}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	sock := New(Config{
+		Address:        srv.addr,
+		ConnectTimeout: defaultTimeout,
+		ReadTimeout:    defaultTimeout,
+		WriteTimeout:   defaultTimeout,
+		Framer:         LengthPrefixedFramer(2, binary.BigEndian, false),
+	})
+	require.NoError(t, sock.Connect())
+	defer func() { _ = sock.Disconnect() }()
+
+	var frames []string
+	err := sock.Command("\x00\x04ping", func(bytes []byte) bool {
+		frames = append(frames, string(bytes))
+		return len(frames) < 3
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ab", "", "cde"}, frames)
+}
+
+func Test_clientUDPRetries(t *testing.T) {
+	tests := map[string]struct {
+		drop         int64
+		retries      int
+		wantErr      bool
+		wantRequests int64
+	}{
+		"no retries":                 {drop: 0, retries: 0, wantRequests: 1},
+		"response after retries":     {drop: 2, retries: 2, wantRequests: 3},
+		"no response within retries": {drop: 2, retries: 1, wantErr: true, wantRequests: 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := &flakyUDPServer{resp: []byte("pong\npong\n"), drop: test.drop}
+			require.NoError(t, srv.Run())
+			defer func() { _ = srv.Close() }()
+
+			sock := New(Config{
+				Address:        srv.addr,
+				ConnectTimeout: defaultTimeout,
+				ReadTimeout:    defaultTimeout,
+				WriteTimeout:   defaultTimeout,
+				Retries:        test.retries,
+			})
+			require.NoError(t, sock.Connect())
+			defer func() { _ = sock.Disconnect() }()
+
+			var lines int
+			err := sock.Command("ping\n", func(bytes []byte) bool {
+				assert.Equal(t, "pong", string(bytes))
+				lines++
+				return true
+			})
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Zero(t, lines)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, lines)
+			}
+			assert.Equal(t, test.wantRequests, atomic.LoadInt64(&srv.requests))
+		})
+	}
+}
+
+func Test_clientReconnect(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n"), resetConns: 1}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	sock := New(Config{
+		Address:        srv.addr,
+		ConnectTimeout: defaultTimeout,
+		ReadTimeout:    defaultTimeout,
+		WriteTimeout:   defaultTimeout,
+		Reconnect:      ReconnectPolicy{MaxAttempts: 2, Backoff: 10 * time.Millisecond},
+	})
+	require.NoError(t, sock.Connect())
+	defer func() { _ = sock.Disconnect() }()
+
+	err := sock.Command("ping\n", func(bytes []byte) bool {
+		assert.Equal(t, "pong", string(bytes))
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&srv.conns))
+}
+
+func Test_clientReconnectNotConnected(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n")}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	cfg := Config{
+		Address:        srv.addr,
+		ConnectTimeout: defaultTimeout,
+		ReadTimeout:    defaultTimeout,
+		WriteTimeout:   defaultTimeout,
+	}
+	process := func(bytes []byte) bool { return false }
+
+	require.Error(t, New(cfg).Command("ping\n", process))
+
+	cfg.Reconnect = ReconnectPolicy{MaxAttempts: 1}
+	sock := New(cfg)
+	defer func() { _ = sock.Disconnect() }()
+	require.NoError(t, sock.Command("ping\n", process))
+}
+
+func Test_clientReconnectFails(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n"), resetConns: 10}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	sock := New(Config{
+		Address:        srv.addr,
+		ConnectTimeout: defaultTimeout,
+		ReadTimeout:    defaultTimeout,
+		WriteTimeout:   defaultTimeout,
+		Reconnect:      ReconnectPolicy{MaxAttempts: 2},
+	})
+	require.NoError(t, sock.Connect())
+	defer func() { _ = sock.Disconnect() }()
+
+	err := sock.Command("ping\n", func(bytes []byte) bool { return false })
+	require.Error(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&srv.conns))
+}
+
+func TestReconnectPolicy_delay(t *testing.T) {
+	tests := map[string]struct {
+		policy ReconnectPolicy
+		want   []time.Duration
+	}{
+		"zero": {
+			want: []time.Duration{0, 0, 0},
+		},
+		"doubles": {
+			policy: ReconnectPolicy{Backoff: time.Second},
+			want:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		"limited": {
+			policy: ReconnectPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second},
+			want:   []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []time.Duration
+			for attempt := 1; attempt <= len(test.want); attempt++ {
+				got = append(got, test.policy.delay(attempt))
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Framer splits a response into frames (messages) passed one by one to the Processor.
+// It has the bufio.SplitFunc signature, so any split function can be used as a framer.
+type Framer func(data []byte, atEOF bool) (advance int, token []byte, err error)
+
+// LineFramer splits a response into lines, the trailing end-of-line marker ("\n" or "\r\n") is dropped.
+// It is the default framer.
+func LineFramer() Framer {
+	return bufio.ScanLines
+}
+
+// DelimiterFramer splits a response on the delimiter, the delimiter is dropped.
+// The last frame is not required to end with the delimiter.
+//
+// The function panics if 'delim' is empty.
+func DelimiterFramer(delim []byte) Framer {
+	if len(delim) == 0 {
+		panic("DelimiterFramer needs a non empty delimiter")
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, delim); i >= 0 {
+			return i + len(delim), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// FixedSizeFramer splits a response into frames of the size.
+// An incomplete last frame is an io.ErrUnexpectedEOF error.
+//
+// The function panics if 'size' is zero or negative.
+func FixedSizeFramer(size int) Framer {
+	if size < 1 {
+		panic("FixedSizeFramer needs a positive size")
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) >= size {
+			return size, data[:size], nil
+		}
+		if atEOF && len(data) > 0 {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, nil
+	}
+}
+
+// LengthPrefixedFramer splits a response into frames prefixed by their length, the prefix is dropped.
+// The length is an unsigned integer of 'prefixSize' (1, 2, 4 or 8) bytes in the byte order.
+// If 'includesPrefix' is true the length includes the prefix size.
+// An incomplete last frame is an io.ErrUnexpectedEOF error.
+//
+// The function panics if 'prefixSize' is not 1, 2, 4 or 8.
+func LengthPrefixedFramer(prefixSize int, order binary.ByteOrder, includesPrefix bool) Framer {
+	var length func([]byte) uint64
+	switch prefixSize {
+	case 1:
+		length = func(b []byte) uint64 { return uint64(b[0]) }
+	case 2:
+		length = func(b []byte) uint64 { return uint64(order.Uint16(b)) }
+	case 4:
+		length = func(b []byte) uint64 { return uint64(order.Uint32(b)) }
+	case 8:
+		length = order.Uint64
+	default:
+		panic("LengthPrefixedFramer needs a prefix size of 1, 2, 4 or 8 bytes")
+	}
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) < prefixSize {
+			if atEOF && len(data) > 0 {
+				return 0, nil, io.ErrUnexpectedEOF
+			}
+			return 0, nil, nil
+		}
+
+		n := length(data[:prefixSize])
+		if includesPrefix {
+			if n < uint64(prefixSize) {
+				return 0, nil, fmt.Errorf("invalid frame length %d, less than the prefix size", n)
+			}
+			n -= uint64(prefixSize)
+		}
+		if n > uint64(maxInt-prefixSize) {
+			return 0, nil, fmt.Errorf("invalid frame length %d", n)
+		}
+
+		end := prefixSize + int(n)
+		if len(data) >= end {
+			return end, data[prefixSize:end], nil
+		}
+		if atEOF {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, nil
+	}
+}
+
+const maxInt = int(^uint(0) >> 1)
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFramers(t *testing.T) {
+	tests := map[string]struct {
+		framer       Framer
+		input        []byte
+		maxFrameSize int
+		wantFrames   []string
+		wantErr      error
+	}{
+		"line": {
+			framer:     LineFramer(),
+			input:      []byte("a\r\nbb\nccc"),
+			wantFrames: []string{"a", "bb", "ccc"},
+		},
+		"default is line": {
+			input:      []byte("a\nbb\n"),
+			wantFrames: []string{"a", "bb"},
+		},
+		"delimiter": {
+			framer:     DelimiterFramer([]byte("\x00\x00")),
+			input:      []byte("a\x00\x00b\x00c\x00\x00d"),
+			wantFrames: []string{"a", "b\x00c", "d"},
+		},
+		"fixed size": {
+			framer:     FixedSizeFramer(2),
+			input:      []byte("aabbcc"),
+			wantFrames: []string{"aa", "bb", "cc"},
+		},
+		"fixed size incomplete last frame": {
+			framer:     FixedSizeFramer(2),
+			input:      []byte("aabbc"),
+			wantFrames: []string{"aa", "bb"},
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		"length prefixed 1 byte": {
+			framer:     LengthPrefixedFramer(1, binary.BigEndian, false),
+			input:      []byte("\x01a\x00\x03bcd"),
+			wantFrames: []string{"a", "", "bcd"},
+		},
+		"length prefixed 2 bytes big endian": {
+			framer:     LengthPrefixedFramer(2, binary.BigEndian, false),
+			input:      []byte("\x00\x02ab\x00\x01c"),
+			wantFrames: []string{"ab", "c"},
+		},
+		"length prefixed 4 bytes little endian including prefix": {
+			framer:     LengthPrefixedFramer(4, binary.LittleEndian, true),
+			input:      []byte("\x06\x00\x00\x00ab\x05\x00\x00\x00c"),
+			wantFrames: []string{"ab", "c"},
+		},
+		"length prefixed 8 bytes": {
+			framer:     LengthPrefixedFramer(8, binary.BigEndian, false),
+			input:      []byte("\x00\x00\x00\x00\x00\x00\x00\x03abc"),
+			wantFrames: []string{"abc"},
+		},
+		"length prefixed incomplete frame": {
+			framer:     LengthPrefixedFramer(2, binary.BigEndian, false),
+			input:      []byte("\x00\x02ab\x00\x05c"),
+			wantFrames: []string{"ab"},
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		"length prefixed incomplete prefix": {
+			framer:     LengthPrefixedFramer(2, binary.BigEndian, false),
+			input:      []byte("\x00\x01a\x00"),
+			wantFrames: []string{"a"},
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		"frame is too long": {
+			framer:       LengthPrefixedFramer(2, binary.BigEndian, false),
+			input:        append([]byte("\x00\x10"), bytes.Repeat([]byte("a"), 16)...),
+			maxFrameSize: 8,
+			wantErr:      bufio.ErrTooLong,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var frames []string
+			err := scanFrames(bytes.NewReader(test.input), func(b []byte) bool {
+				frames = append(frames, string(b))
+				return true
+			}, test.framer, test.maxFrameSize)
+
+			assert.Equal(t, test.wantFrames, frames)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFramers_Panic(t *testing.T) {
+	assert.Panics(t, func() { DelimiterFramer(nil) })
+	assert.Panics(t, func() { FixedSizeFramer(0) })
+	assert.Panics(t, func() { LengthPrefixedFramer(3, binary.BigEndian, false) })
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package socket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var _ Client = (*Pool)(nil)
+
+// NewPool returns a new pool of up to 'size' connections to the config address.
+// Connections are created on demand and reused.
+//
+// The function panics if 'size' is zero or negative.
+func NewPool(config Config, size int) *Pool {
+	if size < 1 {
+		panic("NewPool needs a positive size")
+	}
+	return &Pool{
+		config: config,
+		sem:    make(chan struct{}, size),
+	}
+}
+
+// Pool is a pool of socket clients connected to the same address.
+// It implements Client and is safe for concurrent use.
+type Pool struct {
+	config Config
+	sem    chan struct{}
+
+	mu     sync.Mutex
+	idle   []*Socket
+	closed bool
+}
+
+// Connect is a no-op, connections are created on demand.
+func (p *Pool) Connect() error {
+	return nil
+}
+
+// Disconnect closes the idle connections, connections in use are closed when they are put back.
+func (p *Pool) Disconnect() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	var err error
+	for _, sock := range idle {
+		if e := sock.Disconnect(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Close disconnects and closes the pool, Get returns an error after it.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return p.Disconnect()
+}
+
+// Command runs the command using a pooled connection. A connection that failed is not reused.
+func (p *Pool) Command(command string, process Processor) error {
+	sock, err := p.Get()
+	if err != nil {
+		return err
+	}
+	err = sock.Command(command, process)
+	if err != nil {
+		p.Discard(sock)
+	} else {
+		p.Put(sock)
+	}
+	return err
+}
+
+// Get returns an idle connection or connects a new one.
+// It waits up to the config ConnectTimeout if all connections are in use.
+// The connection must be returned using either Put or Discard.
+func (p *Pool) Get() (*Socket, error) {
+	timer := time.NewTimer(p.config.ConnectTimeout)
+	defer timer.Stop()
+	select {
+	case p.sem <- struct{}{}:
+	case <-timer.C:
+		return nil, errors.New("socket pool: all connections are in use")
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.sem
+		return nil, errors.New("socket pool: closed")
+	}
+	if n := len(p.idle); n > 0 {
+		sock := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return sock, nil
+	}
+	p.mu.Unlock()
+
+	sock := New(p.config)
+	if err := sock.Connect(); err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return sock, nil
+}
+
+// Put returns the connection to the pool.
+func (p *Pool) Put(sock *Socket) {
+	p.mu.Lock()
+	closed := p.closed
+	if !closed {
+		p.idle = append(p.idle, sock)
+	}
+	p.mu.Unlock()
+
+	if closed {
+		_ = sock.Disconnect()
+	}
+	<-p.sem
+}
+
+// Discard closes the connection and releases its place in the pool.
+func (p *Pool) Discard(sock *Socket) {
+	_ = sock.Disconnect()
+	<-p.sem
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package socket
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPool(t *testing.T) {
+	assert.Implements(t, (*Client)(nil), NewPool(tcpConfig, 1))
+	assert.Panics(t, func() { NewPool(tcpConfig, 0) })
+}
+
+func TestPool_Command(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n")}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	pool := NewPool(poolConfig(srv.addr), 2)
+	defer func() { _ = pool.Close() }()
+
+	var wg sync.WaitGroup
+	var ok int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Command("ping\n", func(bytes []byte) bool {
+				if string(bytes) == "pong" {
+					atomic.AddInt64(&ok, 1)
+				}
+				return false
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(10), ok)
+	assert.LessOrEqual(t, atomic.LoadInt64(&srv.conns), int64(2))
+}
+
+func TestPool_Command_DiscardsFailedConnection(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n"), resetConns: 1}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	pool := NewPool(poolConfig(srv.addr), 1)
+	defer func() { _ = pool.Close() }()
+
+	process := func(bytes []byte) bool { return false }
+	assert.Error(t, pool.Command("ping\n", process))
+	assert.NoError(t, pool.Command("ping\n", process))
+	assert.NoError(t, pool.Command("ping\n", process))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&srv.conns))
+}
+
+func TestPool_Get_AllInUse(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n")}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	pool := NewPool(poolConfig(srv.addr), 1)
+	defer func() { _ = pool.Close() }()
+
+	sock, err := pool.Get()
+	require.NoError(t, err)
+
+	_, err = pool.Get()
+	assert.Error(t, err)
+
+	pool.Put(sock)
+	sock2, err := pool.Get()
+	require.NoError(t, err)
+	assert.Same(t, sock, sock2)
+	pool.Put(sock2)
+}
+
+func TestPool_Close(t *testing.T) {
+	srv := &framedServer{resp: []byte("pong\n")}
+	require.NoError(t, srv.Run())
+	defer func() { _ = srv.Close() }()
+
+	pool := NewPool(poolConfig(srv.addr), 2)
+	sock, err := pool.Get()
+	require.NoError(t, err)
+
+	require.NoError(t, pool.Close())
+	pool.Put(sock)
+	assert.Nil(t, sock.conn)
+
+	_, err = pool.Get()
+	assert.Error(t, err)
+}
+
+func TestPool_Get_ConnectError(t *testing.T) {
+	srv := &framedServer{}
+	require.NoError(t, srv.Run())
+	addr := srv.addr
+	require.NoError(t, srv.Close())
+
+	pool := NewPool(poolConfig(addr), 1)
+	_, err := pool.Get()
+	assert.Error(t, err)
+
+	// the failed connection doesn't take the place in the pool
+	_, err = pool.Get()
+	assert.Error(t, err)
+	assert.Len(t, pool.sem, 0)
+}
+
+func poolConfig(addr string) Config {
+	return Config{
+		Address:        addr,
+		ConnectTimeout: defaultTimeout,
+		ReadTimeout:    defaultTimeout,
+		WriteTimeout:   defaultTimeout,
+	}
+}
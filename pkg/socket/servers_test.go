@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -137,3 +138,93 @@ func (u *unixServer) handleConnection(conn net.Conn) {
 		_ = rw.Flush()
 	}
 }
+
+// framedServer writes the response on every read request, connections are kept open.
+// The first 'resetConns' connections are reset after reading the request.
+type framedServer struct {
+	addr       string
+	server     net.Listener
+	resp       []byte
+	resetConns int64
+	conns      int64
+}
+
+func (f *framedServer) Run() (err error) {
+	f.server, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	f.addr = f.server.Addr().String()
+	go f.handleConnections()
+	return nil
+}
+
+func (f *framedServer) Close() (err error) {
+	return f.server.Close()
+}
+
+func (f *framedServer) handleConnections() {
+	for {
+		conn, err := f.server.Accept()
+		if err != nil {
+			return
+		}
+		reset := atomic.AddInt64(&f.conns, 1) <= f.resetConns
+		go f.handleConnection(conn, reset)
+	}
+}
+
+func (f *framedServer) handleConnection(conn net.Conn, reset bool) {
+	defer func() { _ = conn.Close() }()
+	var buf [1024]byte
+	for {
+		_ = conn.SetDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(buf[:]); err != nil {
+			return
+		}
+		if reset {
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			return
+		}
+		if _, err := conn.Write(f.resp); err != nil {
+			return
+		}
+	}
+}
+
+// flakyUDPServer doesn't respond to the first 'drop' requests.
+type flakyUDPServer struct {
+	addr     string
+	conn     net.PacketConn
+	resp     []byte
+	drop     int64
+	requests int64
+}
+
+func (u *flakyUDPServer) Run() (err error) {
+	u.conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	u.addr = "udp://" + u.conn.LocalAddr().String()
+	go u.handleConnections()
+	return nil
+}
+
+func (u *flakyUDPServer) Close() (err error) {
+	return u.conn.Close()
+}
+
+func (u *flakyUDPServer) handleConnections() {
+	var buf [2048]byte
+	for {
+		_, addr, err := u.conn.ReadFrom(buf[:])
+		if err != nil {
+			return
+		}
+		if atomic.AddInt64(&u.requests, 1) <= u.drop {
+			continue
+		}
+		_, _ = u.conn.WriteTo(u.resp, addr)
+	}
+}
@@ -38,4 +38,38 @@ type Config struct {
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 	TLSConf        *tls.Config
+
+	// Framer splits responses into frames, LineFramer is used if not set.
+	Framer Framer
+	// MaxFrameSize is the max size of a frame, bufio.MaxScanTokenSize is used if not set.
+	MaxFrameSize int
+	// Retries is the number of times a UDP request is resent if there is no response within the ReadTimeout.
+	Retries int
+	// Reconnect is the policy to reconnect and repeat a failed command.
+	Reconnect ReconnectPolicy
+}
+
+// ReconnectPolicy controls reconnecting if a command fails before any frame is processed.
+// The zero value disables reconnecting.
+type ReconnectPolicy struct {
+	// MaxAttempts is the max number of reconnect attempts per command.
+	MaxAttempts int
+	// Backoff is the delay before the first attempt, it doubles after every failed attempt.
+	Backoff time.Duration
+	// MaxBackoff limits the delay, it is not limited if not set.
+	MaxBackoff time.Duration
+}
+
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d > 0; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
 }
@@ -20,18 +20,54 @@ type (
 	Value interface {
 		WriteTo(rv map[string]int64, key string, mul, div int)
 	}
+	// Writer is implemented by structs that have the WriteTo method generated by cmd/stmgen.
+	// The generated method writes the same key-value pairs as the reflection based conversion.
+	Writer interface {
+		WriteTo(rv map[string]int64)
+	}
 )
 
-// ToMap converts struct to a map[string]int64 based on 'stm' tags
+var writerType = reflect.TypeOf((*Writer)(nil)).Elem()
+
+// ToMap converts struct to a map[string]int64 based on 'stm' tags.
+// It uses the generated WriteTo method if the struct (or a pointer to it) implements Writer.
 func ToMap(s ...interface{}) map[string]int64 {
 	rv := map[string]int64{}
 	for _, v := range s {
+		if w, ok := asWriter(v); ok {
+			w.WriteTo(rv)
+			continue
+		}
 		value := reflect.Indirect(reflect.ValueOf(v))
 		toMap(value, rv, "", 1, 1)
 	}
 	return rv
 }
 
+// WriteValue converts the value the same way ToMap converts a struct field tagged with `stm:"key,mul,div"`.
+// It is used by the generated code for the fields it doesn't convert itself.
+func WriteValue(rv map[string]int64, v interface{}, key string, mul, div int) {
+	toMap(reflect.ValueOf(v), rv, key, mul, div)
+}
+
+// JoinKey joins the key prefix and the key the same way ToMap does for nested structs.
+func JoinKey(prefix, key string) string {
+	return joinPrefix(prefix, key)
+}
+
+func asWriter(v interface{}) (Writer, bool) {
+	if w, ok := v.(Writer); ok {
+		return w, true
+	}
+	value := reflect.ValueOf(v)
+	if !value.IsValid() || value.Kind() != reflect.Struct || !reflect.PtrTo(value.Type()).Implements(writerType) {
+		return nil, false
+	}
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	return ptr.Interface().(Writer), true
+}
+
 func toMap(value reflect.Value, rv map[string]int64, key string, mul, div int) {
 	if !value.IsValid() {
 		logger.Panicf("value is not valid key=%s", key)
@@ -413,3 +413,41 @@ func TestToMap_ArraySlice(t *testing.T) {
 		stm.ToMap(s[:]),
 	)
 }
+
+type generated struct {
+	A int `stm:"a"`
+}
+
+func (g *generated) WriteTo(rv map[string]int64) {
+	rv["generated_a"] = int64(g.A)
+}
+
+func TestToMap_Writer(t *testing.T) {
+	s := generated{A: 1}
+
+	expected := map[string]int64{"generated_a": 1}
+
+	assert.Equal(t, expected, stm.ToMap(s), "value test")
+	assert.Equal(t, expected, stm.ToMap(&s), "ptr test")
+	assert.Equal(t, map[string]int64{"s_a": 1}, stm.ToMap(struct {
+		S generated `stm:"s"`
+	}{s}), "nested test")
+}
+
+func TestWriteValue(t *testing.T) {
+	rv := map[string]int64{}
+	c := metrics.Counter{}
+	c.Add(5)
+
+	stm.WriteValue(rv, 3.14, "float", 100, 1)
+	stm.WriteValue(rv, map[string]int{"a": 1}, "map", 1, 1)
+	stm.WriteValue(rv, c, "counter", 1, 1)
+
+	assert.Equal(t, map[string]int64{"float": 314, "map_a": 1, "counter": 5}, rv)
+}
+
+func TestJoinKey(t *testing.T) {
+	assert.Equal(t, "a_b", stm.JoinKey("a", "b"))
+	assert.Equal(t, "a", stm.JoinKey("a", ""))
+	assert.Equal(t, "b", stm.JoinKey("", "b"))
+}
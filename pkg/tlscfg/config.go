@@ -5,8 +5,15 @@ package tlscfg
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 // TLSConfig represents the standard client TLS configuration.
@@ -14,48 +21,117 @@ type TLSConfig struct {
 	// TLSCA specifies the certificate authority to use when verifying server certificates.
 	TLSCA string `yaml:"tls_ca"`
 
+	// TLSCAAppendSystem controls whether TLSCA is added to the system certificate pool instead of replacing it.
+	TLSCAAppendSystem bool `yaml:"tls_ca_append_system"`
+
 	// TLSCert specifies tls certificate file.
 	TLSCert string `yaml:"tls_cert"`
 
 	// TLSKey specifies tls key file.
 	TLSKey string `yaml:"tls_key"`
 
+	// TLSKeyPassword specifies the password to decrypt an encrypted PEM tls key file.
+	TLSKeyPassword string `yaml:"tls_key_password"`
+
+	// TLSPKCS12 specifies PKCS#12 bundle file containing tls certificate and key, an alternative to TLSCert and TLSKey.
+	TLSPKCS12 string `yaml:"tls_pkcs12"`
+
+	// TLSPKCS12Password specifies the PKCS#12 bundle password.
+	TLSPKCS12Password string `yaml:"tls_pkcs12_password"`
+
+	// TLSCertReload controls whether tls certificate and key are reloaded when they change on disk.
+	TLSCertReload bool `yaml:"tls_cert_reload"`
+
+	// TLSServerName is used to verify the hostname on the returned certificates and is sent in the SNI extension.
+	TLSServerName string `yaml:"tls_server_name"`
+
+	// TLSMinVersion specifies the minimum TLS version: "1.0", "1.1", "1.2" or "1.3".
+	TLSMinVersion string `yaml:"tls_min_version"`
+
+	// TLSCipherSuites specifies the enabled TLS 1.0–1.2 cipher suites by their IANA names.
+	TLSCipherSuites []string `yaml:"tls_cipher_suites"`
+
 	// InsecureSkipVerify controls whether a client verifies the server's certificate chain and host name.
 	InsecureSkipVerify bool `yaml:"tls_skip_verify"`
 }
 
+func (cfg TLSConfig) isEmpty() bool {
+	return cfg.TLSCA == "" && cfg.TLSKey == "" && cfg.TLSCert == "" && cfg.TLSPKCS12 == "" &&
+		cfg.TLSServerName == "" && cfg.TLSMinVersion == "" && len(cfg.TLSCipherSuites) == 0 &&
+		!cfg.InsecureSkipVerify
+}
+
 // NewTLSConfig creates a tls.Config, may be nil without an error if TLS is not configured.
 func NewTLSConfig(cfg TLSConfig) (*tls.Config, error) {
-	if cfg.TLSCA == "" && cfg.TLSKey == "" && cfg.TLSCert == "" && !cfg.InsecureSkipVerify {
+	if cfg.isEmpty() {
 		return nil, nil
 	}
 
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: cfg.InsecureSkipVerify,
 		Renegotiation:      tls.RenegotiateNever,
+		ServerName:         cfg.TLSServerName,
 	}
 
 	if cfg.TLSCA != "" {
-		pool, err := loadCertPool([]string{cfg.TLSCA})
+		pool, err := loadCertPool([]string{cfg.TLSCA}, cfg.TLSCAAppendSystem)
 		if err != nil {
 			return nil, err
 		}
 		tlsConfig.RootCAs = pool
 	}
 
-	if cfg.TLSCert != "" && cfg.TLSKey != "" {
-		cert, err := loadCertificate(cfg.TLSCert, cfg.TLSKey)
+	if cfg.TLSMinVersion != "" {
+		v, err := parseTLSVersion(cfg.TLSMinVersion)
 		if err != nil {
 			return nil, err
 		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
+		tlsConfig.MinVersion = v
+	}
+
+	if len(cfg.TLSCipherSuites) > 0 {
+		ids, err := parseCipherSuites(cfg.TLSCipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = ids
+	}
+
+	var load func() (tls.Certificate, error)
+	var files []string
+	switch {
+	case cfg.TLSPKCS12 != "":
+		load = func() (tls.Certificate, error) { return loadPKCS12(cfg.TLSPKCS12, cfg.TLSPKCS12Password) }
+		files = []string{cfg.TLSPKCS12}
+	case cfg.TLSCert != "" && cfg.TLSKey != "":
+		load = func() (tls.Certificate, error) { return loadCertificate(cfg.TLSCert, cfg.TLSKey, cfg.TLSKeyPassword) }
+		files = []string{cfg.TLSCert, cfg.TLSKey}
+	}
+	if load != nil {
+		cert, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.TLSCertReload {
+			r := &certReloader{load: load, files: files, cert: &cert, modTime: modTimes(files)}
+			tlsConfig.GetClientCertificate = r.getClientCertificate
+		} else {
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
 	}
 
 	return tlsConfig, nil
 }
 
-func loadCertPool(certFiles []string) (*x509.CertPool, error) {
+func loadCertPool(certFiles []string, appendSystem bool) (*x509.CertPool, error) {
 	pool := x509.NewCertPool()
+	if appendSystem {
+		sys, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("could not load system certificate pool: %v", err)
+		}
+		pool = sys
+	}
 	for _, certFile := range certFiles {
 		pem, err := os.ReadFile(certFile)
 		if err != nil {
@@ -68,10 +144,152 @@ func loadCertPool(certFiles []string) (*x509.CertPool, error) {
 	return pool, nil
 }
 
-func loadCertificate(certFile, keyFile string) (tls.Certificate, error) {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+func loadCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read certificate %q: %v", certFile, err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read key %q: %v", keyFile, err)
+	}
+	if password != "" {
+		if keyPEM, err = decryptKeyPEM(keyPEM, password); err != nil {
+			return tls.Certificate{}, fmt.Errorf("could not decrypt key %q: %v", keyFile, err)
+		}
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
 		return tls.Certificate{}, fmt.Errorf("could not load keypair %s:%s: %v", certFile, keyFile, err)
 	}
 	return cert, nil
 }
+
+// decryptKeyPEM decrypts encrypted PKCS#8 keys ("ENCRYPTED PRIVATE KEY", the OpenSSL default)
+// and legacy RFC 1423 encrypted PEM blocks ("Proc-Type: 4,ENCRYPTED").
+func decryptKeyPEM(data []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		der, err := decryptPKCS8(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+	// the legacy encryption is insecure (no integrity check), it is supported for the existing keys only
+	if !x509.IsEncryptedPEMBlock(block) {
+		return data, nil
+	}
+	der, err := x509.DecryptPEMBlock(block, []byte(password))
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+}
+
+func loadPKCS12(file, password string) (tls.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read PKCS#12 bundle %q: %v", file, err)
+	}
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not decode PKCS#12 bundle %q: %v", file, err)
+	}
+
+	cert := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, ca := range caCerts {
+		cert.Certificate = append(cert.Certificate, ca.Raw)
+	}
+	return cert, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(version), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q", version)
+	}
+	return v, nil
+}
+
+func parseCipherSuites(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		suites[s.Name] = s.ID
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		suites[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// certReloader reloads the client certificate if any of its files modification time changes.
+type certReloader struct {
+	mu      sync.Mutex
+	load    func() (tls.Certificate, error)
+	files   []string
+	cert    *tls.Certificate
+	modTime []time.Time
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime := modTimes(r.files)
+	if equalTimes(modTime, r.modTime) {
+		return r.cert, nil
+	}
+	cert, err := r.load()
+	if err != nil {
+		// the files may be in the middle of rotation, keep using the old certificate
+		return r.cert, nil
+	}
+	r.cert, r.modTime = &cert, modTime
+	return r.cert, nil
+}
+
+func modTimes(files []string) []time.Time {
+	times := make([]time.Time, len(files))
+	for i, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			times[i] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,9 +2,198 @@
 
 package tlscfg
 
-import "testing"
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
 
-// TODO:
-func TestNewClientTLSConfig(t *testing.T) {
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
+func TestNewTLSConfig(t *testing.T) {
+	tests := map[string]struct {
+		config  TLSConfig
+		wantNil bool
+		wantErr bool
+		check   func(t *testing.T, cfg *tls.Config)
+	}{
+		"empty config": {
+			wantNil: true,
+		},
+		"skip verify": {
+			config: TLSConfig{InsecureSkipVerify: true},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.True(t, cfg.InsecureSkipVerify)
+				assert.Nil(t, cfg.RootCAs)
+			},
+		},
+		"custom CA": {
+			config: TLSConfig{TLSCA: "testdata/ca.pem"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.NotNil(t, cfg.RootCAs)
+			},
+		},
+		"custom CA appended to system": {
+			config: TLSConfig{TLSCA: "testdata/ca.pem", TLSCAAppendSystem: true},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.NotNil(t, cfg.RootCAs)
+			},
+		},
+		"server name, min version and cipher suites": {
+			config: TLSConfig{
+				TLSServerName:   "example.com",
+				TLSMinVersion:   "1.2",
+				TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.Equal(t, "example.com", cfg.ServerName)
+				assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+				assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+			},
+		},
+		"cert and key": {
+			config: TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client.key"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.Len(t, cfg.Certificates, 1)
+			},
+		},
+		"cert and encrypted key": {
+			config: TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client-encrypted.key", TLSKeyPassword: "secret"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.Len(t, cfg.Certificates, 1)
+			},
+		},
+		"cert and encrypted PKCS#8 key (OpenSSL 3 default)": {
+			config: TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client-pkcs8-encrypted.key", TLSKeyPassword: "secret"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.Len(t, cfg.Certificates, 1)
+			},
+		},
+		"PKCS#12 bundle (OpenSSL 3 default)": {
+			config: TLSConfig{TLSPKCS12: "testdata/client-openssl3.p12", TLSPKCS12Password: "secret"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.Len(t, cfg.Certificates, 1)
+				assert.Len(t, cfg.Certificates[0].Certificate, 2)
+				assert.NotNil(t, cfg.Certificates[0].PrivateKey)
+			},
+		},
+		"PKCS#12 bundle": {
+			config: TLSConfig{TLSPKCS12: "testdata/client.p12", TLSPKCS12Password: "secret"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.Len(t, cfg.Certificates, 1)
+				assert.Len(t, cfg.Certificates[0].Certificate, 2)
+			},
+		},
+		"cert reload": {
+			config: TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client.key", TLSCertReload: true},
+			check: func(t *testing.T, cfg *tls.Config) {
+				assert.Empty(t, cfg.Certificates)
+				require.NotNil(t, cfg.GetClientCertificate)
+				cert, err := cfg.GetClientCertificate(nil)
+				require.NoError(t, err)
+				assert.NotNil(t, cert)
+			},
+		},
+		"nonexistent CA": {
+			config:  TLSConfig{TLSCA: "testdata/nonexistent.pem"},
+			wantErr: true,
+		},
+		"encrypted key without password": {
+			config:  TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client-encrypted.key"},
+			wantErr: true,
+		},
+		"encrypted key wrong password": {
+			config:  TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client-encrypted.key", TLSKeyPassword: "wrong"},
+			wantErr: true,
+		},
+		"encrypted PKCS#8 key wrong password": {
+			config:  TLSConfig{TLSCert: "testdata/client.pem", TLSKey: "testdata/client-pkcs8-encrypted.key", TLSKeyPassword: "wrong"},
+			wantErr: true,
+		},
+		"PKCS#12 bundle (OpenSSL 3 default) wrong password": {
+			config:  TLSConfig{TLSPKCS12: "testdata/client-openssl3.p12", TLSPKCS12Password: "wrong"},
+			wantErr: true,
+		},
+		"PKCS#12 bundle wrong password": {
+			config:  TLSConfig{TLSPKCS12: "testdata/client.p12", TLSPKCS12Password: "wrong"},
+			wantErr: true,
+		},
+		"unknown min version": {
+			config:  TLSConfig{TLSMinVersion: "2.0"},
+			wantErr: true,
+		},
+		"unknown cipher suite": {
+			config:  TLSConfig{TLSCipherSuites: []string{"TLS_UNKNOWN"}},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := NewTLSConfig(test.config)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.wantNil {
+				assert.Nil(t, cfg)
+				return
+			}
+			require.NotNil(t, cfg)
+			test.check(t, cfg)
+		})
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	for version, want := range map[string]uint16{
+		"1.0":    tls.VersionTLS10,
+		"1.1":    tls.VersionTLS11,
+		"TLS1.2": tls.VersionTLS12,
+		"tls1.3": tls.VersionTLS13,
+	} {
+		v, err := parseTLSVersion(version)
+		assert.NoError(t, err, version)
+		assert.Equal(t, want, v, version)
+	}
+}
+
+func TestCertReloader_getClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	copyFile(t, "testdata/client.pem", certFile)
+	copyFile(t, "testdata/client.key", keyFile)
+
+	cfg, err := NewTLSConfig(TLSConfig{TLSCert: certFile, TLSKey: keyFile, TLSCertReload: true})
+	require.NoError(t, err)
+	cert1, err := cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	cert2, err := cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Same(t, cert1, cert2)
+
+	// rotate
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	cert3, err := cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.NotSame(t, cert1, cert3)
+
+	// broken rotation keeps the old certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0644))
+	cert4, err := cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Same(t, cert3, cert4)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0644))
 }
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package tlscfg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PKCS#5 v2.0 (RFC 8018) object identifiers, PBES2 with PBKDF2 is what OpenSSL uses for encrypted PKCS#8 keys.
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type (
+	encryptedPrivateKeyInfo struct {
+		Algorithm     pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}
+	pbes2Params struct {
+		KeyDerivationFunc pkix.AlgorithmIdentifier
+		EncryptionScheme  pkix.AlgorithmIdentifier
+	}
+	pbkdf2Params struct {
+		Salt           []byte
+		IterationCount int
+		KeyLength      int                      `asn1:"optional"`
+		PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+	}
+)
+
+// decryptPKCS8 decrypts the DER encoded EncryptedPrivateKeyInfo ("ENCRYPTED PRIVATE KEY"),
+// it returns the DER encoded PKCS#8 private key.
+func decryptPKCS8(der []byte, password string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("bad encrypted PKCS#8 key: %v", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported PKCS#8 key encryption %s, only PBES2 is supported", info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("bad PBES2 parameters: %v", err)
+	}
+
+	newCipher, keyLen, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("bad PBES2 encryption scheme IV: %v", err)
+	}
+	key, err := pbkdf2Key(params.KeyDerivationFunc, []byte(password), keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data := info.EncryptedData
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("bad encrypted PKCS#8 key data size")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// the padding or the key is broken if the password is incorrect
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > block.BlockSize() {
+		return nil, x509.IncorrectPasswordError
+	}
+	for _, b := range plain[len(plain)-pad:] {
+		if int(b) != pad {
+			return nil, x509.IncorrectPasswordError
+		}
+	}
+	plain = plain[:len(plain)-pad]
+	if _, err := x509.ParsePKCS8PrivateKey(plain); err != nil {
+		return nil, x509.IncorrectPasswordError
+	}
+	return plain, nil
+}
+
+func pbes2Cipher(oid asn1.ObjectIdentifier) (newCipher func(key []byte) (cipher.Block, error), keyLen int, err error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported PBES2 encryption scheme %s", oid)
+}
+
+func pbkdf2Key(kdf pkix.AlgorithmIdentifier, password []byte, keyLen int) ([]byte, error) {
+	if !kdf.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported PBES2 key derivation function %s, only PBKDF2 is supported", kdf.Algorithm)
+	}
+	var params pbkdf2Params
+	if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("bad PBKDF2 parameters: %v", err)
+	}
+	if params.KeyLength != 0 && params.KeyLength != keyLen {
+		return nil, fmt.Errorf("bad PBKDF2 key length %d, expected %d", params.KeyLength, keyLen)
+	}
+
+	var h func() hash.Hash
+	switch prf := params.PRF.Algorithm; {
+	case len(prf) == 0, prf.Equal(oidHMACWithSHA1):
+		h = sha1.New
+	case prf.Equal(oidHMACWithSHA224):
+		h = sha256.New224
+	case prf.Equal(oidHMACWithSHA256):
+		h = sha256.New
+	case prf.Equal(oidHMACWithSHA384):
+		h = sha512.New384
+	case prf.Equal(oidHMACWithSHA512):
+		h = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 PRF %s", prf)
+	}
+	return pbkdf2.Key(password, params.Salt, params.IterationCount, keyLen, h), nil
+}